@@ -6,16 +6,35 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/stasshander/ddd/docs"
+	"github.com/stasshander/ddd/internal/application/cart"
+	"github.com/stasshander/ddd/internal/application/currency"
+	"github.com/stasshander/ddd/internal/application/export"
+	"github.com/stasshander/ddd/internal/application/idempotency"
+	"github.com/stasshander/ddd/internal/application/importjob"
+	"github.com/stasshander/ddd/internal/application/inventory"
+	"github.com/stasshander/ddd/internal/application/order"
+	"github.com/stasshander/ddd/internal/application/pricing"
 	"github.com/stasshander/ddd/internal/application/product"
+	"github.com/stasshander/ddd/internal/application/promotion"
+	"github.com/stasshander/ddd/internal/application/purchaseorder"
+	"github.com/stasshander/ddd/internal/application/region"
+	"github.com/stasshander/ddd/internal/application/reorder"
+	"github.com/stasshander/ddd/internal/application/review"
 	"github.com/stasshander/ddd/internal/application/store"
+	"github.com/stasshander/ddd/internal/application/supplier"
+	"github.com/stasshander/ddd/internal/application/tax"
+	domaincurrency "github.com/stasshander/ddd/internal/domain/currency"
 	"github.com/stasshander/ddd/internal/infrastructure/config"
 	"github.com/stasshander/ddd/internal/infrastructure/metrics"
 	"github.com/stasshander/ddd/internal/infrastructure/mongodb"
+	"github.com/stasshander/ddd/internal/infrastructure/search"
 	"github.com/stasshander/ddd/internal/interfaces/http/handlers"
 	"github.com/stasshander/ddd/internal/interfaces/http/middleware"
 	swaggerFiles "github.com/swaggo/files"
@@ -40,29 +59,179 @@ func main() {
 		}
 	}()
 
+	txRunner := mongodb.NewTransactionRunner(client)
 	productRepo := mongodb.NewProductRepository(client, cfg.MongoDB.Database)
 	storeRepo := mongodb.NewStoreRepository(client, cfg.MongoDB.Database)
+	regionRepo := mongodb.NewRegionRepository(client, cfg.MongoDB.Database)
+	promotionRepo := mongodb.NewPromotionRepository(client, cfg.MongoDB.Database)
+	taxRepo := mongodb.NewTaxRepository(client, cfg.MongoDB.Database)
+	exchangeRateRepo := mongodb.NewExchangeRateRepository(client, cfg.MongoDB.Database)
+	stockRepo := mongodb.NewInventoryRepository(client, cfg.MongoDB.Database)
+	reservationRepo := mongodb.NewReservationRepository(client, cfg.MongoDB.Database)
+	orderRepo := mongodb.NewOrderRepository(client, cfg.MongoDB.Database)
+	cartRepo := mongodb.NewCartRepository(client, cfg.MongoDB.Database)
+	supplierRepo := mongodb.NewSupplierRepository(client, cfg.MongoDB.Database)
+	purchaseOrderRepo := mongodb.NewPurchaseOrderRepository(client, cfg.MongoDB.Database)
+	reorderRepo := mongodb.NewReorderRepository(client, cfg.MongoDB.Database)
+	reviewRepo := mongodb.NewReviewRepository(client, cfg.MongoDB.Database)
+	importJobRepo := mongodb.NewImportJobRepository(client, cfg.MongoDB.Database)
+	idempotencyRepo := mongodb.NewIdempotencyRepository(client, cfg.MongoDB.Database)
 
-	productService := product.NewService(productRepo)
-	storeService := store.NewService(storeRepo)
+	if err := productRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create product indexes: %v", err)
+	}
+	if err := storeRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create store indexes: %v", err)
+	}
+	if migrated, err := storeRepo.MigrateProductIDs(context.Background()); err != nil {
+		log.Fatalf("Failed to migrate store products: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Moved the products of %d stores out of product_ids", migrated)
+	}
+	if err := regionRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create region indexes: %v", err)
+	}
+	if err := promotionRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create promotion indexes: %v", err)
+	}
+	if err := taxRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create tax rate indexes: %v", err)
+	}
+	if err := exchangeRateRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create exchange rate indexes: %v", err)
+	}
+	if err := stockRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create stock indexes: %v", err)
+	}
+	if err := reservationRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create reservation indexes: %v", err)
+	}
+	if err := orderRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create order indexes: %v", err)
+	}
+	if err := cartRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create cart indexes: %v", err)
+	}
+	if err := supplierRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create supplier indexes: %v", err)
+	}
+	if err := purchaseOrderRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create purchase order indexes: %v", err)
+	}
+	if err := reorderRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create reorder suggestion indexes: %v", err)
+	}
+	if err := reviewRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create review indexes: %v", err)
+	}
+	if err := importJobRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create import job indexes: %v", err)
+	}
+	if err := idempotencyRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create idempotency indexes: %v", err)
+	}
+
+	productService := product.NewService(productRepo, txRunner)
+	regionService := region.NewService(regionRepo, storeRepo)
+	storeService := store.NewService(storeRepo, regionService)
+	promotionService := promotion.NewService(promotionRepo)
+	taxService := tax.NewService(taxRepo)
+	pricingService := pricing.NewService(productRepo, storeRepo, promotionRepo, taxRepo)
+	inventoryService := inventory.NewService(stockRepo, reservationRepo, storeRepo, productRepo, txRunner, cfg.Stock.ReservationTTL)
+	orderService := order.NewService(orderRepo, productRepo, storeRepo, stockRepo, txRunner)
+	cartService := cart.NewService(cartRepo, productRepo, storeRepo, orderService, txRunner, cfg.Cart.TTL)
+	supplierService := supplier.NewService(supplierRepo, productRepo)
+	purchaseOrderService := purchaseorder.NewService(purchaseOrderRepo, supplierRepo, storeRepo, stockRepo, txRunner)
+	reorderService := reorder.NewService(reorderRepo, stockRepo, supplierRepo, purchaseOrderRepo, storeRepo)
+	reviewService := review.NewService(reviewRepo, productRepo, productRepo)
+	importService := importjob.NewService(importJobRepo, productService, storeService, cfg.Import.Lease)
+	exportService := export.NewService(productRepo, storeRepo)
+	idempotencyService := idempotency.NewService(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout)
+
+	baseCurrency, err := domaincurrency.ParseCode(cfg.Currency.Base)
+	if err != nil {
+		log.Fatalf("Invalid BASE_CURRENCY: %v", err)
+	}
+	roundingRules, err := domaincurrency.ParseRoundingRules(cfg.Currency.Rounding)
+	if err != nil {
+		log.Fatalf("Invalid CURRENCY_ROUNDING: %v", err)
+	}
+	currencyService := currency.NewService(exchangeRateRepo, domaincurrency.NewConverter(baseCurrency, roundingRules))
+	if cfg.Currency.RatesFile != "" {
+		imported, err := importExchangeRates(currencyService, cfg.Currency.RatesFile)
+		if err != nil {
+			log.Fatalf("Failed to import exchange rates: %v", err)
+		}
+		log.Printf("Imported %d exchange rates from %s", imported, cfg.Currency.RatesFile)
+	}
+	availabilityService := product.NewAvailabilityService(productService, storeRepo, regionService)
+	searchService := product.NewSearchService(productRepo)
+
+	var catalogSearchService *product.FacetedSearchService
+	if cfg.Search.IndexEnabled {
+		index := search.NewIndex()
+		products, err := productService.ListProducts(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to build search index: %v", err)
+		}
+		index.Rebuild(products)
+		productService.Subscribe(index)
+		catalogSearchService = product.NewFacetedSearchService(index)
+		log.Printf("Search index built with %d products", index.Len())
+	}
 
 	router := gin.Default()
 
 	router.Use(middleware.MetricsMiddleware())
 	router.Use(middleware.AuthMiddleware(cfg.API.Token))
 
-	productHandler := handlers.NewProductHandler(productService)
+	productHandler := handlers.NewProductHandler(productService, currencyService)
 	storeHandler := handlers.NewStoreHandler(storeService)
+	regionHandler := handlers.NewRegionHandler(regionService)
+	bundleHandler := handlers.NewBundleHandler(productService, availabilityService)
+	availabilityHandler := handlers.NewAvailabilityHandler(availabilityService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	pricingHandler := handlers.NewPricingHandler(pricingService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(currencyService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService)
+	reorderHandler := handlers.NewReorderHandler(reorderService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	importHandler := handlers.NewImportHandler(importService, cfg.Import.MaxUploadBytes)
+	exportHandler := handlers.NewExportHandler(exportService, currencyService)
+	searchHandler := handlers.NewSearchHandler(searchService, catalogSearchService)
+
+	// Idempotency-Key is honoured on the creating requests clients retry
+	idempotent := middleware.IdempotencyMiddleware(idempotencyService)
 
 	api := router.Group("/api")
 	{
 		products := api.Group("/products")
 		{
-			products.POST("", productHandler.CreateProduct)
+			products.POST("", idempotent, productHandler.CreateProduct)
 			products.GET("", productHandler.ListProducts)
+			products.GET("/search", searchHandler.SearchProducts)
+			products.POST("/batch-get", productHandler.BatchGetProducts)
+			products.POST("/bulk", productHandler.BulkProducts)
+			products.POST("/bundles", bundleHandler.CreateBundle)
 			products.GET("/:id", productHandler.GetProduct)
 			products.PUT("/:id/price", productHandler.UpdateProductPrice)
 			products.PUT("/:id/description", productHandler.UpdateProductDescription)
+			products.PUT("/:id/category", productHandler.UpdateProductCategory)
+			products.PUT("/:id/tax-class", productHandler.UpdateProductTaxClass)
+			products.PUT("/:id/translations/:locale", productHandler.UpsertTranslation)
+			products.DELETE("/:id/translations/:locale", productHandler.RemoveTranslation)
+			products.PUT("/:id/default-locale", productHandler.SetDefaultLocale)
+			products.PUT("/:id/components", bundleHandler.UpdateBundleComponents)
+			products.PUT("/:id/bundle-price", bundleHandler.SetBundlePrice)
+			products.GET("/:id/availability", bundleHandler.BundleAvailability)
+			products.GET("/:id/stores", availabilityHandler.ProductStores)
+			products.POST("/:id/reviews", reviewHandler.SubmitReview)
+			products.GET("/:id/reviews", reviewHandler.ListProductReviews)
 			products.DELETE("/:id", productHandler.DeleteProduct)
 		}
 
@@ -70,12 +239,137 @@ func main() {
 		{
 			stores.POST("", storeHandler.CreateStore)
 			stores.GET("", storeHandler.ListStores)
+			stores.GET("/nearby", storeHandler.NearbyStores)
 			stores.GET("/:id", storeHandler.GetStore)
 			stores.PUT("/:id/name", storeHandler.UpdateStoreName)
 			stores.PUT("/:id/address", storeHandler.UpdateStoreAddress)
+			stores.PUT("/:id/location", storeHandler.UpdateStoreLocation)
+			stores.PUT("/:id/region", regionHandler.AssignStore)
+			stores.PUT("/:id/hours", storeHandler.UpdateOpeningHours)
+			stores.PUT("/:id/hours/exceptions/:date", storeHandler.SetOpeningException)
+			stores.DELETE("/:id/hours/exceptions/:date", storeHandler.RemoveOpeningException)
+			stores.GET("/:id/open", storeHandler.IsStoreOpen)
+			stores.POST("/:id/open", storeHandler.OpenStore)
+			stores.POST("/:id/suspend", storeHandler.SuspendStore)
+			stores.POST("/:id/close", storeHandler.CloseStore)
 			stores.DELETE("/:id", storeHandler.DeleteStore)
-			stores.POST("/:id/products", storeHandler.AddProductToStore)
+			stores.POST("/:id/products", idempotent, storeHandler.AddProductToStore)
 			stores.DELETE("/:id/products/:productId", storeHandler.RemoveProductFromStore)
+			stores.GET("/:id/products/:productId/price", pricingHandler.QuotePrice)
+			stores.GET("/:id/stock", inventoryHandler.ListStock)
+			stores.PUT("/:id/stock/:productId", inventoryHandler.SetStock)
+			stores.PUT("/:id/stock/:productId/reorder-rule", inventoryHandler.SetReorderRule)
+			stores.GET("/:id/reorder-suggestions", reorderHandler.ReorderSuggestions)
+			stores.POST("/:id/purchase-orders", purchaseOrderHandler.CreatePurchaseOrder)
+			stores.GET("/:id/purchase-orders", purchaseOrderHandler.ListPurchaseOrders)
+			stores.GET("/:id/purchase-orders/:orderId", purchaseOrderHandler.GetPurchaseOrder)
+			stores.PUT("/:id/purchase-orders/:orderId/lines/:productId", purchaseOrderHandler.SetLine)
+			stores.DELETE("/:id/purchase-orders/:orderId/lines/:productId", purchaseOrderHandler.RemoveLine)
+			stores.POST("/:id/purchase-orders/:orderId/send", purchaseOrderHandler.SendPurchaseOrder)
+			stores.POST("/:id/purchase-orders/:orderId/receive", purchaseOrderHandler.ReceivePurchaseOrder)
+		}
+
+		reviews := api.Group("/reviews")
+		{
+			reviews.GET("", reviewHandler.ListReviews)
+			reviews.GET("/:id", reviewHandler.GetReview)
+			reviews.POST("/:id/approve", reviewHandler.ApproveReview)
+			reviews.POST("/:id/reject", reviewHandler.RejectReview)
+			reviews.DELETE("/:id", reviewHandler.DeleteReview)
+		}
+
+		imports := api.Group("/imports")
+		{
+			imports.POST("", importHandler.CreateImport)
+			imports.GET("", importHandler.ListImports)
+			imports.GET("/:id", importHandler.GetImport)
+			imports.GET("/:id/errors", importHandler.ImportErrors)
+		}
+
+		exports := api.Group("/exports")
+		{
+			exports.GET("/products", exportHandler.ExportProducts)
+			exports.GET("/stores", exportHandler.ExportStores)
+		}
+
+		suppliers := api.Group("/suppliers")
+		{
+			suppliers.POST("", supplierHandler.CreateSupplier)
+			suppliers.GET("", supplierHandler.ListSuppliers)
+			suppliers.GET("/:id", supplierHandler.GetSupplier)
+			suppliers.PUT("/:id", supplierHandler.UpdateSupplier)
+			suppliers.DELETE("/:id", supplierHandler.DeleteSupplier)
+			suppliers.PUT("/:id/products/:productId", supplierHandler.SetProduct)
+			suppliers.DELETE("/:id/products/:productId", supplierHandler.RemoveProduct)
+		}
+
+		regions := api.Group("/regions")
+		{
+			regions.POST("", regionHandler.CreateRegion)
+			regions.GET("", regionHandler.ListRegions)
+			regions.GET("/tree", regionHandler.RegionTree)
+			regions.GET("/:id", regionHandler.GetRegion)
+			regions.PUT("/:id/name", regionHandler.RenameRegion)
+			regions.PUT("/:id/parent", regionHandler.MoveRegion)
+			regions.DELETE("/:id", regionHandler.DeleteRegion)
+			regions.GET("/:id/stores", regionHandler.ListRegionStores)
+			regions.GET("/:id/stats", regionHandler.RegionStats)
+		}
+
+		promotions := api.Group("/promotions")
+		{
+			promotions.POST("", promotionHandler.CreatePromotion)
+			promotions.GET("", promotionHandler.ListPromotions)
+			promotions.GET("/:id", promotionHandler.GetPromotion)
+			promotions.PUT("/:id", promotionHandler.UpdatePromotion)
+			promotions.DELETE("/:id", promotionHandler.DeletePromotion)
+		}
+
+		taxRates := api.Group("/tax-rates")
+		{
+			taxRates.POST("", taxHandler.CreateRateTable)
+			taxRates.GET("", taxHandler.ListRateTables)
+			taxRates.GET("/:id", taxHandler.GetRateTable)
+			taxRates.PUT("/:id", taxHandler.UpdateRateTable)
+			taxRates.DELETE("/:id", taxHandler.DeleteRateTable)
+		}
+
+		orders := api.Group("/orders")
+		{
+			orders.POST("", orderHandler.PlaceOrder)
+			orders.GET("", orderHandler.ListOrders)
+			orders.GET("/:id", orderHandler.GetOrder)
+			orders.POST("/:id/pay", orderHandler.PayOrder)
+			orders.POST("/:id/fulfil", orderHandler.FulfilOrder)
+			orders.POST("/:id/cancel", orderHandler.CancelOrder)
+		}
+
+		reservations := api.Group("/reservations")
+		{
+			reservations.POST("", inventoryHandler.Reserve)
+			reservations.GET("/:id", inventoryHandler.GetReservation)
+			reservations.POST("/:id/confirm", inventoryHandler.ConfirmReservation)
+			reservations.POST("/:id/cancel", inventoryHandler.CancelReservation)
+		}
+
+		carts := api.Group("/carts")
+		{
+			carts.POST("", cartHandler.CreateCart)
+			carts.GET("/:id", cartHandler.GetCart)
+			carts.PUT("/:id/lines/:productId", cartHandler.SetLine)
+			carts.DELETE("/:id/lines/:productId", cartHandler.RemoveLine)
+			carts.POST("/:id/checkout", cartHandler.Checkout)
+		}
+
+		exchangeRates := api.Group("/exchange-rates")
+		{
+			exchangeRates.GET("", exchangeRateHandler.ListRates)
+			exchangeRates.POST("", exchangeRateHandler.SaveRates)
+			exchangeRates.POST("/import", exchangeRateHandler.ImportRates)
+		}
+
+		if catalogSearchService != nil {
+			api.GET("/search", searchHandler.Search)
 		}
 	}
 
@@ -95,6 +389,23 @@ func main() {
 		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
 	}
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	var jobs sync.WaitGroup
+	jobs.Add(3)
+	go func() {
+		defer jobs.Done()
+		inventoryService.RunSweeper(jobsCtx, cfg.Stock.SweepInterval)
+	}()
+	go func() {
+		defer jobs.Done()
+		reorderService.RunScanner(jobsCtx, cfg.Stock.ReorderScanInterval)
+	}()
+	go func() {
+		defer jobs.Done()
+		importService.RunWorker(jobsCtx, cfg.Import.PollInterval)
+	}()
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
@@ -105,6 +416,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -113,5 +425,19 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Wait for the background jobs to stop, so that an import is not cut off
+	// halfway through a write
+	jobs.Wait()
+
 	log.Println("Server exiting")
 }
+
+func importExchangeRates(service *currency.Service, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return service.ImportCSV(context.Background(), f)
+}
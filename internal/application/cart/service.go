@@ -0,0 +1,203 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apporder "github.com/stasshander/ddd/internal/application/order"
+	"github.com/stasshander/ddd/internal/application/transaction"
+	"github.com/stasshander/ddd/internal/domain/cart"
+	"github.com/stasshander/ddd/internal/domain/order"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service struct {
+	repo     cart.Repository
+	products product.Repository
+	stores   store.Repository
+	orders   *apporder.Service
+	tx       transaction.Runner
+	ttl      time.Duration
+}
+
+// NewService creates a cart service. Carts expire ttl after they were last
+// used. At checkout the cart is deleted and the order placed in one unit of
+// work run by tx.
+func NewService(repo cart.Repository, products product.Repository, stores store.Repository, orders *apporder.Service, tx transaction.Runner, ttl time.Duration) *Service {
+	return &Service{
+		repo:     repo,
+		products: products,
+		stores:   stores,
+		orders:   orders,
+		tx:       tx,
+		ttl:      ttl,
+	}
+}
+
+func (s *Service) CreateCart(ctx context.Context, storeID string) (*cart.Priced, error) {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cart.NewCart(st.ID, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return c.Reprice(nil, nil), nil
+}
+
+// GetCart returns the cart priced against the current catalog and store
+// assortment
+func (s *Service) GetCart(ctx context.Context, id string) (*cart.Priced, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.save(ctx, c)
+}
+
+// SetLine adds a product to the cart or changes its quantity
+func (s *Service) SetLine(ctx context.Context, id, productID string, quantity int) (*cart.Priced, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := s.stores.GetByID(ctx, c.StoreID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !carries(st, p.ID) {
+		return nil, cart.ErrProductNotInStore
+	}
+
+	if err := c.SetLine(p, quantity); err != nil {
+		return nil, err
+	}
+
+	return s.save(ctx, c)
+}
+
+func (s *Service) RemoveLine(ctx context.Context, id, productID string) (*cart.Priced, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.RemoveLine(objectID); err != nil {
+		return nil, err
+	}
+
+	return s.save(ctx, c)
+}
+
+// Checkout turns the cart into an order. It is refused if any product has
+// become unavailable or if a price changed since the customer last saw the
+// cart; in the latter case the cart now holds the new prices and checkout
+// can be retried once the customer has reviewed them. A cart is checked out
+// at most once: deleting it claims the checkout, and a concurrent or
+// repeated checkout of the same cart fails with ErrCartNotFound.
+func (s *Service) Checkout(ctx context.Context, id string) (*order.Order, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Lines) == 0 {
+		return nil, cart.ErrEmptyCart
+	}
+
+	priced, err := s.save(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if priced.Unavailable {
+		return nil, cart.ErrUnavailableItems
+	}
+	if priced.PriceChanged {
+		return nil, cart.ErrPricesChanged
+	}
+
+	items := make([]apporder.Item, 0, len(c.Lines))
+	for _, line := range c.Lines {
+		items = append(items, apporder.Item{ProductID: line.ProductID.Hex(), Quantity: line.Quantity})
+	}
+
+	var o *order.Order
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, c.ID); err != nil {
+			return err
+		}
+		o, err = s.orders.PlaceOrder(ctx, c.StoreID.Hex(), items)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// save re-prices the cart, extends its lifetime and stores it
+func (s *Service) save(ctx context.Context, c *cart.Cart) (*cart.Priced, error) {
+	st, err := s.stores.GetByID(ctx, c.StoreID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	carried := make(map[primitive.ObjectID]bool, len(st.Products))
+	for _, id := range st.Products {
+		carried[id] = true
+	}
+
+	catalog := make(map[primitive.ObjectID]*product.Product, len(c.Lines))
+	for _, id := range c.ProductIDs() {
+		p, err := s.products.GetByID(ctx, id.Hex())
+		if errors.Is(err, product.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		catalog[id] = p
+	}
+
+	c.Touch(s.ttl)
+	priced := c.Reprice(catalog, carried)
+
+	if err := s.repo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return priced, nil
+}
+
+func carries(st *store.Store, productID primitive.ObjectID) bool {
+	for _, id := range st.Products {
+		if id == productID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package cart
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	apporder "github.com/stasshander/ddd/internal/application/order"
+	"github.com/stasshander/ddd/internal/application/transaction"
+	"github.com/stasshander/ddd/internal/domain/cart"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/order"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// directRunner runs units of work without a transaction
+type directRunner struct{}
+
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// rollbackRunner restores the carts when a unit of work fails, standing in
+// for a transaction around the cart repository
+type rollbackRunner struct {
+	carts *memoryCarts
+}
+
+func (r rollbackRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.carts.mu.Lock()
+	saved := make(map[string]cart.Cart, len(r.carts.carts))
+	for id, c := range r.carts.carts {
+		saved[id] = c
+	}
+	r.carts.mu.Unlock()
+
+	err := fn(ctx)
+	if err != nil {
+		r.carts.mu.Lock()
+		r.carts.carts = saved
+		r.carts.mu.Unlock()
+	}
+	return err
+}
+
+type memoryCarts struct {
+	mu    sync.Mutex
+	carts map[string]cart.Cart
+}
+
+func (m *memoryCarts) Create(ctx context.Context, c *cart.Cart) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.carts[c.ID] = *c
+	return nil
+}
+
+func (m *memoryCarts) GetByID(ctx context.Context, id string) (*cart.Cart, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.carts[id]
+	if !ok {
+		return nil, cart.ErrCartNotFound
+	}
+	c.Lines = append([]cart.Line(nil), c.Lines...)
+	return &c, nil
+}
+
+func (m *memoryCarts) Update(ctx context.Context, c *cart.Cart) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.carts[c.ID]; !ok {
+		return cart.ErrCartNotFound
+	}
+	m.carts[c.ID] = *c
+	return nil
+}
+
+func (m *memoryCarts) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.carts[id]; !ok {
+		return cart.ErrCartNotFound
+	}
+	delete(m.carts, id)
+	return nil
+}
+
+type memoryOrders struct {
+	mu     sync.Mutex
+	orders []*order.Order
+}
+
+func (m *memoryOrders) Create(ctx context.Context, o *order.Order) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders = append(m.orders, o)
+	return nil
+}
+
+func (m *memoryOrders) GetByID(ctx context.Context, id string) (*order.Order, error) {
+	return nil, order.ErrOrderNotFound
+}
+
+func (m *memoryOrders) UpdateStatus(ctx context.Context, o *order.Order, previous order.Status) error {
+	return nil
+}
+
+func (m *memoryOrders) List(ctx context.Context, filter order.ListFilter, page, limit int) ([]*order.Order, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.orders, len(m.orders), nil
+}
+
+// memoryStock holds the available units of every product; any other
+// inventory.Repository method panics
+type memoryStock struct {
+	inventory.Repository
+	mu        sync.Mutex
+	available int
+}
+
+func (m *memoryStock) Reserve(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.available < quantity {
+		return inventory.ErrInsufficientStock
+	}
+	m.available -= quantity
+	return nil
+}
+
+type singleStore struct {
+	store.Repository
+	store *store.Store
+}
+
+func (s *singleStore) GetByID(ctx context.Context, id string) (*store.Store, error) {
+	if id != s.store.ID.Hex() {
+		return nil, store.ErrStoreNotFound
+	}
+	st := *s.store
+	return &st, nil
+}
+
+type singleProduct struct {
+	product.Repository
+	product *product.Product
+}
+
+func (s *singleProduct) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	if id != s.product.ID.Hex() {
+		return nil, product.ErrProductNotFound
+	}
+	p := *s.product
+	return &p, nil
+}
+
+type checkoutFixture struct {
+	service *Service
+	carts   *memoryCarts
+	orders  *memoryOrders
+	stock   *memoryStock
+	cartID  string
+}
+
+func newCheckoutFixture(t *testing.T, available int, runner func(*memoryCarts) transaction.Runner) *checkoutFixture {
+	p, err := product.NewProduct("Tea", "Green tea", 4.5)
+	assert.NoError(t, err)
+	st, err := store.NewStore("Corner Shop", "Main Street 1")
+	assert.NoError(t, err)
+	assert.NoError(t, st.Open())
+	assert.NoError(t, st.AddProduct(p.ID))
+
+	carts := &memoryCarts{carts: make(map[string]cart.Cart)}
+	orders := &memoryOrders{}
+	stock := &memoryStock{available: available}
+	stores := &singleStore{store: st}
+	products := &singleProduct{product: p}
+
+	tx := runner(carts)
+	orderService := apporder.NewService(orders, products, stores, stock, tx)
+	service := NewService(carts, products, stores, orderService, tx, time.Hour)
+
+	ctx := context.Background()
+	created, err := service.CreateCart(ctx, st.ID.Hex())
+	assert.NoError(t, err)
+	_, err = service.SetLine(ctx, created.ID, p.ID.Hex(), 2)
+	assert.NoError(t, err)
+
+	return &checkoutFixture{service: service, carts: carts, orders: orders, stock: stock, cartID: created.ID}
+}
+
+func direct(*memoryCarts) transaction.Runner {
+	return directRunner{}
+}
+
+func TestCheckoutRepeated(t *testing.T) {
+	ctx := context.Background()
+	f := newCheckoutFixture(t, 10, direct)
+
+	o, err := f.service.Checkout(ctx, f.cartID)
+	assert.NoError(t, err)
+	assert.NotNil(t, o)
+
+	_, err = f.service.Checkout(ctx, f.cartID)
+	assert.ErrorIs(t, err, cart.ErrCartNotFound)
+
+	assert.Len(t, f.orders.orders, 1)
+	assert.Equal(t, 8, f.stock.available)
+}
+
+func TestCheckoutConcurrent(t *testing.T) {
+	ctx := context.Background()
+	f := newCheckoutFixture(t, 100, direct)
+
+	const attempts = 8
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = f.service.Checkout(ctx, f.cartID)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, cart.ErrCartNotFound)
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Len(t, f.orders.orders, 1)
+	assert.Equal(t, 98, f.stock.available)
+}
+
+func TestCheckoutKeepsCartWhenOrderFails(t *testing.T) {
+	ctx := context.Background()
+	f := newCheckoutFixture(t, 1, func(carts *memoryCarts) transaction.Runner {
+		return rollbackRunner{carts: carts}
+	})
+
+	_, err := f.service.Checkout(ctx, f.cartID)
+	assert.ErrorIs(t, err, inventory.ErrInsufficientStock)
+	assert.Empty(t, f.orders.orders)
+
+	_, err = f.service.GetCart(ctx, f.cartID)
+	assert.NoError(t, err)
+}
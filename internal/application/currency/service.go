@@ -0,0 +1,139 @@
+package currency
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/currency"
+	"github.com/stasshander/ddd/internal/domain/product"
+)
+
+// ErrInvalidCSV is returned when an exchange rate CSV file is malformed
+var ErrInvalidCSV = errors.New("exchange rate CSV must have the columns currency,rate,effective_from")
+
+type Service struct {
+	repo      currency.Repository
+	converter *currency.Converter
+}
+
+func NewService(repo currency.Repository, converter *currency.Converter) *Service {
+	return &Service{
+		repo:      repo,
+		converter: converter,
+	}
+}
+
+func (s *Service) SaveRates(ctx context.Context, rates []*currency.Rate) error {
+	return s.repo.Save(ctx, rates)
+}
+
+// ImportCSV loads exchange rates from CSV with a header row of
+// currency,rate,effective_from and returns the number of rates stored. The
+// whole file is rejected if any row is invalid.
+func (s *Service) ImportCSV(ctx context.Context, r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, ErrInvalidCSV
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"currency", "rate", "effective_from"} {
+		if _, ok := columns[name]; !ok {
+			return 0, ErrInvalidCSV
+		}
+	}
+
+	var rates []*currency.Rate
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[columns["rate"]]), 64)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, currency.ErrInvalidRate)
+		}
+
+		rate, err := currency.NewRate(record[columns["currency"]], value, record[columns["effective_from"]])
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := s.repo.Save(ctx, rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+func (s *Service) ListRates(ctx context.Context, code string) ([]*currency.Rate, error) {
+	if code != "" {
+		var err error
+		if code, err = currency.ParseCode(code); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.List(ctx, code)
+}
+
+// BaseCurrency returns the currency catalog prices are kept in
+func (s *Service) BaseCurrency() string {
+	return s.converter.Base()
+}
+
+// ConvertProducts returns copies of products with prices converted into
+// code at the rate in effect at time at. Prices are returned unchanged for
+// the base currency.
+func (s *Service) ConvertProducts(ctx context.Context, products []*product.Product, code string, at time.Time) ([]*product.Product, error) {
+	convert, code, err := s.PriceConverter(ctx, code, at)
+	if err != nil {
+		return nil, err
+	}
+	if code == s.converter.Base() {
+		return products, nil
+	}
+
+	converted := make([]*product.Product, 0, len(products))
+	for _, p := range products {
+		c := *p
+		c.Price = convert(p.Price)
+		converted = append(converted, &c)
+	}
+	return converted, nil
+}
+
+// PriceConverter looks up the rate of code in effect at time at once and
+// returns a function converting base currency prices at that rate, along
+// with the normalized currency code. Prices in the base currency are left
+// unchanged.
+func (s *Service) PriceConverter(ctx context.Context, code string, at time.Time) (func(float64) float64, string, error) {
+	code, err := currency.ParseCode(code)
+	if err != nil {
+		return nil, "", err
+	}
+	if code == s.converter.Base() {
+		return func(price float64) float64 { return price }, code, nil
+	}
+
+	rate, err := s.repo.FindEffective(ctx, code, at)
+	if err != nil {
+		return nil, "", err
+	}
+	return func(price float64) float64 { return s.converter.Convert(price, rate) }, code, nil
+}
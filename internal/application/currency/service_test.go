@@ -0,0 +1,69 @@
+package currency
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/currency"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRateRepository struct {
+	rates []*currency.Rate
+}
+
+func (m *memoryRateRepository) Save(ctx context.Context, rates []*currency.Rate) error {
+	m.rates = append(m.rates, rates...)
+	return nil
+}
+
+func (m *memoryRateRepository) List(ctx context.Context, code string) ([]*currency.Rate, error) {
+	return m.rates, nil
+}
+
+func (m *memoryRateRepository) FindEffective(ctx context.Context, code string, at time.Time) (*currency.Rate, error) {
+	var found *currency.Rate
+	for _, r := range m.rates {
+		if r.Currency == code && !r.EffectiveFrom.After(at) && (found == nil || r.EffectiveFrom.After(found.EffectiveFrom)) {
+			found = r
+		}
+	}
+	if found == nil {
+		return nil, currency.ErrRateNotFound
+	}
+	return found, nil
+}
+
+func TestImportCSV(t *testing.T) {
+	repo := &memoryRateRepository{}
+	service := NewService(repo, currency.NewConverter("EUR", nil))
+
+	imported, err := service.ImportCSV(context.Background(), strings.NewReader(
+		"currency,rate,effective_from\nUSD,1.05,2026-01-01\nusd, 1.10, 2026-06-01\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, imported)
+
+	p, err := product.NewProduct("Tea", "Green tea", 10)
+	assert.NoError(t, err)
+
+	converted, err := service.ConvertProducts(context.Background(), []*product.Product{p}, "usd", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 10.5, converted[0].Price)
+	assert.Equal(t, 10.0, p.Price)
+
+	converted, err = service.ConvertProducts(context.Background(), []*product.Product{p}, "USD", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 11.0, converted[0].Price)
+
+	_, err = service.ConvertProducts(context.Background(), []*product.Product{p}, "USD", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, currency.ErrRateNotFound)
+
+	_, err = service.ImportCSV(context.Background(), strings.NewReader("code,value\nUSD,1.1\n"))
+	assert.ErrorIs(t, err, ErrInvalidCSV)
+
+	_, err = service.ImportCSV(context.Background(), strings.NewReader("currency,rate,effective_from\nUSD,abc,2026-01-01\n"))
+	assert.ErrorIs(t, err, currency.ErrInvalidRate)
+}
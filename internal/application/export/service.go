@@ -0,0 +1,40 @@
+package export
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+)
+
+// Service reads the catalog for exports. Results are streamed from the
+// database one document at a time rather than loaded as a whole.
+type Service struct {
+	products product.Repository
+	stores   store.Repository
+}
+
+func NewService(products product.Repository, stores store.Repository) *Service {
+	return &Service{
+		products: products,
+		stores:   stores,
+	}
+}
+
+// EachProduct calls fn for every product, oldest first, until fn fails
+func (s *Service) EachProduct(ctx context.Context, fn func(*product.Product) error) error {
+	return s.products.Each(ctx, fn)
+}
+
+// EachStore calls fn for every store matching filter, oldest first, until fn
+// fails. As with store listings, OpenAt keeps only stores open at that time.
+func (s *Service) EachStore(ctx context.Context, filter store.ListFilter, fn func(*store.Store) error) error {
+	return s.stores.Each(ctx, filter, func(st *store.Store) error {
+		if filter.OpenAt != nil {
+			if open, err := st.IsOpenAt(*filter.OpenAt); err != nil || !open {
+				return nil
+			}
+		}
+		return fn(st)
+	})
+}
@@ -0,0 +1,91 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/idempotency"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+)
+
+type Service struct {
+	repo        idempotency.Repository
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+func NewService(repo idempotency.Repository, ttl, lockTimeout time.Duration) *Service {
+	return &Service{
+		repo:        repo,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Begin claims a key for a request. It returns a record that the caller now
+// owns and must Complete or Release, or else the completed record of an
+// identical earlier request whose response should be replayed. It fails with
+// ErrKeyMismatch when the key was used for a different request and with
+// ErrKeyInProgress while the first request is still running.
+func (s *Service) Begin(ctx context.Context, key, fingerprint string) (owned *idempotency.Record, replay *idempotency.Record, err error) {
+	record, err := idempotency.NewRecord(key, fingerprint, s.ttl, s.lockTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.repo.Create(ctx, record)
+	if err == nil {
+		return record, nil, nil
+	}
+	if !errors.Is(err, idempotency.ErrKeyExists) {
+		return nil, nil, err
+	}
+
+	existing, err := s.repo.GetByKey(ctx, key)
+	if errors.Is(err, idempotency.ErrRecordNotFound) {
+		// The earlier record expired or was released in the meantime
+		if err := s.repo.Create(ctx, record); err != nil {
+			return nil, nil, s.contended(err)
+		}
+		return record, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if existing.Fingerprint == fingerprint && existing.Abandoned(time.Now()) {
+		if err := s.repo.TakeOver(ctx, record, existing.LockedUntil); err != nil {
+			return nil, nil, s.contended(err)
+		}
+		return record, nil, nil
+	}
+
+	if err := existing.Check(fingerprint); err != nil {
+		return nil, nil, err
+	}
+
+	metrics.IdempotentReplaysTotal.Inc()
+	return nil, existing, nil
+}
+
+// Complete stores the response of an owned record's request for replay
+func (s *Service) Complete(ctx context.Context, record *idempotency.Record, status int, contentType string, body []byte) error {
+	record.Complete(status, contentType, body)
+	return s.repo.Complete(ctx, record)
+}
+
+// Release gives up an owned record without storing a response, so that the
+// request can be retried with the same key
+func (s *Service) Release(ctx context.Context, record *idempotency.Record) error {
+	return s.repo.Release(ctx, record)
+}
+
+// contended reports losing a race for a key to another request as that
+// request being in progress
+func (s *Service) contended(err error) error {
+	if errors.Is(err, idempotency.ErrKeyExists) || errors.Is(err, idempotency.ErrConcurrentUpdate) {
+		return idempotency.ErrKeyInProgress
+	}
+	return err
+}
@@ -0,0 +1,135 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryRecords keeps records in a map and applies the same conditions as
+// the MongoDB repository
+type memoryRecords struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newMemoryRecords() *memoryRecords {
+	return &memoryRecords{records: make(map[string]idempotency.Record)}
+}
+
+func (m *memoryRecords) Create(ctx context.Context, record *idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.records[record.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return idempotency.ErrKeyExists
+	}
+	m.records[record.Key] = *record
+	return nil
+}
+
+func (m *memoryRecords) GetByKey(ctx context.Context, key string) (*idempotency.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.records[key]
+	if !ok || !existing.ExpiresAt.After(time.Now()) {
+		return nil, idempotency.ErrRecordNotFound
+	}
+	return &existing, nil
+}
+
+func (m *memoryRecords) TakeOver(ctx context.Context, record *idempotency.Record, lockedUntil time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.records[record.Key]
+	if !ok || existing.Completed || !existing.LockedUntil.Equal(lockedUntil) {
+		return idempotency.ErrConcurrentUpdate
+	}
+	m.records[record.Key] = *record
+	return nil
+}
+
+func (m *memoryRecords) Complete(ctx context.Context, record *idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.owns(record) {
+		return idempotency.ErrConcurrentUpdate
+	}
+	m.records[record.Key] = *record
+	return nil
+}
+
+func (m *memoryRecords) Release(ctx context.Context, record *idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.owns(record) {
+		return idempotency.ErrConcurrentUpdate
+	}
+	delete(m.records, record.Key)
+	return nil
+}
+
+func (m *memoryRecords) owns(record *idempotency.Record) bool {
+	existing, ok := m.records[record.Key]
+	return ok && !existing.Completed && existing.LockedUntil.Equal(record.LockedUntil)
+}
+
+func TestBegin(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryRecords(), time.Hour, time.Minute)
+
+	owned, replay, err := service.Begin(ctx, "order-42", "fp")
+	assert.NoError(t, err)
+	assert.NotNil(t, owned)
+	assert.Nil(t, replay)
+
+	_, _, err = service.Begin(ctx, "order-42", "fp")
+	assert.ErrorIs(t, err, idempotency.ErrKeyInProgress)
+
+	_, _, err = service.Begin(ctx, "order-42", "other")
+	assert.ErrorIs(t, err, idempotency.ErrKeyMismatch)
+
+	assert.NoError(t, service.Complete(ctx, owned, 201, "application/json", []byte(`{"id":1}`)))
+
+	owned, replay, err = service.Begin(ctx, "order-42", "fp")
+	assert.NoError(t, err)
+	assert.Nil(t, owned)
+	assert.Equal(t, 201, replay.Status)
+	assert.Equal(t, []byte(`{"id":1}`), replay.Body)
+
+	_, _, err = service.Begin(ctx, "order-42", "other")
+	assert.ErrorIs(t, err, idempotency.ErrKeyMismatch)
+}
+
+func TestBeginAfterRelease(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryRecords(), time.Hour, time.Minute)
+
+	owned, _, err := service.Begin(ctx, "order-42", "fp")
+	assert.NoError(t, err)
+	assert.NoError(t, service.Release(ctx, owned))
+
+	owned, replay, err := service.Begin(ctx, "order-42", "other")
+	assert.NoError(t, err)
+	assert.NotNil(t, owned)
+	assert.Nil(t, replay)
+}
+
+func TestBeginTakesOverAbandonedKey(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryRecords(), time.Hour, time.Millisecond)
+
+	first, _, err := service.Begin(ctx, "order-42", "fp")
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	second, _, err := service.Begin(ctx, "order-42", "fp")
+	assert.NoError(t, err)
+	assert.NotNil(t, second)
+
+	assert.ErrorIs(t, service.Complete(ctx, first, 201, "application/json", nil), idempotency.ErrConcurrentUpdate)
+	assert.NoError(t, service.Complete(ctx, second, 201, "application/json", nil))
+}
@@ -0,0 +1,161 @@
+package importjob
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/stasshander/ddd/internal/domain/importjob"
+)
+
+// maxLineBytes bounds a single NDJSON line
+const maxLineBytes = 1 << 20
+
+// row is a line of an import file with its values by column. err is set when
+// the line itself could not be read.
+type row struct {
+	number int
+	values map[string]string
+	raw    string
+	err    error
+}
+
+func readRows(format importjob.Format, kind importjob.Kind, payload []byte) ([]row, error) {
+	switch format {
+	case importjob.FormatCSV:
+		return readCSV(kind, payload)
+	case importjob.FormatNDJSON:
+		return readNDJSON(payload)
+	}
+	return nil, importjob.ErrInvalidFormat
+}
+
+// readCSV reads a CSV file whose first line names the columns. Columns are
+// matched case-insensitively, and a byte order mark left by spreadsheet
+// exports is ignored.
+func readCSV(kind importjob.Kind, payload []byte) ([]row, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(payload, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, importjob.ErrEmptyImport
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	if missing := missingColumns(kind, header); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", importjob.ErrMissingColumns, strings.Join(missing, ", "))
+	}
+
+	var rows []row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, row{number: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				values[column] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row{number: line, values: values, raw: encodeCSV(record)})
+	}
+}
+
+func missingColumns(kind importjob.Kind, header []string) []string {
+	present := make(map[string]bool, len(header))
+	for _, column := range header {
+		present[column] = true
+	}
+
+	var missing []string
+	for _, column := range kind.Columns() {
+		if !present[column] {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
+
+func encodeCSV(record []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(record)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// readNDJSON reads one JSON object per line. Blank lines are skipped; a line
+// that is not an object is reported as a row error.
+func readNDJSON(payload []byte) ([]row, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	var rows []row
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			rows = append(rows, row{number: number, raw: line, err: errors.New("line is not a JSON object")})
+			continue
+		}
+
+		values := make(map[string]string, len(object))
+		for key, value := range object {
+			values[strings.ToLower(key)] = stringValue(value)
+		}
+		rows = append(rows, row{number: number, values: values, raw: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, importjob.ErrEmptyImport
+	}
+	return rows, nil
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
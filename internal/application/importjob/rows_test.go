@@ -0,0 +1,70 @@
+package importjob
+
+import (
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/importjob"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSV(t *testing.T) {
+	payload := []byte("\xef\xbb\xbfName,Description,Price\n" +
+		"Tea, Green tea ,4.50\n" +
+		"\"Coffee, dark\",Roasted,7\n" +
+		"Broken,\"unterminated,1\n")
+
+	rows, err := readRows(importjob.FormatCSV, importjob.KindProducts, payload)
+	assert.NoError(t, err)
+	if !assert.Len(t, rows, 3) {
+		return
+	}
+
+	assert.Equal(t, 2, rows[0].number)
+	assert.Equal(t, map[string]string{"name": "Tea", "description": "Green tea", "price": "4.50"}, rows[0].values)
+	assert.Equal(t, "Tea,Green tea ,4.50", rows[0].raw)
+
+	assert.Equal(t, 3, rows[1].number)
+	assert.Equal(t, "Coffee, dark", rows[1].values["name"])
+	assert.Equal(t, `"Coffee, dark",Roasted,7`, rows[1].raw)
+
+	assert.Equal(t, 4, rows[2].number)
+	assert.Error(t, rows[2].err)
+}
+
+func TestReadCSVMissingColumns(t *testing.T) {
+	_, err := readRows(importjob.FormatCSV, importjob.KindAssortments, []byte("store_id,sku\n1,2\n"))
+	assert.ErrorIs(t, err, importjob.ErrMissingColumns)
+
+	rows, err := readRows(importjob.FormatCSV, importjob.KindAssortments, []byte("store_id,product_id\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+}
+
+func TestReadNDJSON(t *testing.T) {
+	payload := []byte(`{"name": "Tea", "description": "Green tea", "price": 4.5}
+
+not json
+{"store_id": "s1", "product_id": null, "active": true}
+`)
+
+	rows, err := readRows(importjob.FormatNDJSON, importjob.KindProducts, payload)
+	assert.NoError(t, err)
+	if !assert.Len(t, rows, 3) {
+		return
+	}
+
+	assert.Equal(t, 1, rows[0].number)
+	assert.Equal(t, "4.5", rows[0].values["price"])
+
+	assert.Equal(t, 3, rows[1].number)
+	assert.Error(t, rows[1].err)
+	assert.Equal(t, "not json", rows[1].raw)
+
+	assert.Equal(t, 4, rows[2].number)
+	assert.Equal(t, map[string]string{"store_id": "s1", "product_id": "", "active": "true"}, rows[2].values)
+}
+
+func TestReadNDJSONEmpty(t *testing.T) {
+	_, err := readRows(importjob.FormatNDJSON, importjob.KindProducts, []byte("\n\n"))
+	assert.ErrorIs(t, err, importjob.ErrEmptyImport)
+}
@@ -0,0 +1,327 @@
+package importjob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	appproduct "github.com/stasshander/ddd/internal/application/product"
+	appstore "github.com/stasshander/ddd/internal/application/store"
+	"github.com/stasshander/ddd/internal/domain/importjob"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// batchSize is the number of rows imported between progress updates
+const batchSize = 100
+
+var errInvalidPrice = errors.New("price must be a number")
+
+type Service struct {
+	jobs     importjob.Repository
+	products *appproduct.Service
+	stores   *appstore.Service
+	lease    time.Duration
+}
+
+// NewService creates an import service. A worker holds the job it runs for
+// lease at a time, renewing it after every batch; a job whose lease runs out
+// is taken over by the next worker that looks for work.
+func NewService(jobs importjob.Repository, products *appproduct.Service, stores *appstore.Service, lease time.Duration) *Service {
+	return &Service{
+		jobs:     jobs,
+		products: products,
+		stores:   stores,
+		lease:    lease,
+	}
+}
+
+// Submit queues an import of payload. The file is read once up front so that
+// an unreadable file or missing columns are reported straight away; rows are
+// validated by the worker.
+func (s *Service) Submit(ctx context.Context, kind, format string, dryRun bool, payload []byte) (*importjob.Job, error) {
+	k, err := importjob.ParseKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	f, err := importjob.ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := readRows(f, k, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := importjob.NewJob(k, f, dryRun, len(rows), payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	job.Payload = nil
+	return job, nil
+}
+
+func (s *Service) GetJob(ctx context.Context, id string) (*importjob.Job, error) {
+	return s.jobs.GetByID(ctx, id)
+}
+
+func (s *Service) ListJobs(ctx context.Context, page, limit int) ([]*importjob.Job, int, error) {
+	return s.jobs.List(ctx, page, limit)
+}
+
+// ProcessNext runs the oldest queued job, or a job abandoned by its worker, to
+// completion. It reports whether there was a job to run.
+func (s *Service) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := s.jobs.ClaimNext(ctx, s.lease)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	return true, s.run(ctx, job)
+}
+
+// RunWorker processes queued jobs, checking for new ones every interval,
+// until ctx is done
+func (s *Service) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				processed, err := s.ProcessNext(ctx)
+				if err != nil {
+					log.Printf("Failed to run import job: %v", err)
+				}
+				if !processed || err != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// run imports the job's rows in batches, saving progress and renewing the
+// lease after each batch. A job taken over from another worker resumes after
+// the last batch that worker saved. A job that cannot continue is marked
+// failed; the rows imported before that stay imported.
+func (s *Service) run(ctx context.Context, job *importjob.Job) error {
+	rows, err := readRows(job.Format, job.Kind, job.Payload)
+	if err != nil {
+		return s.fail(ctx, job, err)
+	}
+
+	// Stores loaded during a dry run, with the products added by earlier rows
+	dryRunStores := make(map[string]*store.Store)
+
+	for start := job.ProcessedRows; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		batch := rows[start:end]
+
+		var errs []error
+		switch job.Kind {
+		case importjob.KindProducts:
+			errs, err = s.importProducts(ctx, job, batch)
+		case importjob.KindAssortments:
+			errs, err = s.importAssortments(ctx, batch, job.DryRun, dryRunStores)
+		default:
+			err = importjob.ErrInvalidKind
+		}
+		if err != nil {
+			return s.fail(ctx, job, err)
+		}
+
+		for i, r := range batch {
+			if err := job.Record(r.number, r.raw, errs[i]); err != nil {
+				return err
+			}
+			recordRow(job, errs[i])
+		}
+		job.RenewLease(s.lease)
+		if err := s.jobs.SaveProgress(ctx, job); err != nil {
+			return s.fail(ctx, job, err)
+		}
+	}
+
+	if err := job.Complete(); err != nil {
+		return err
+	}
+	return s.jobs.Finish(ctx, job)
+}
+
+// fail marks the job failed. A job interrupted because ctx was cancelled, or
+// because another worker has taken it over, is left running instead, so that
+// it is resumed once its lease runs out.
+func (s *Service) fail(ctx context.Context, job *importjob.Job, cause error) error {
+	if ctx.Err() != nil || errors.Is(cause, importjob.ErrLeaseLost) {
+		return cause
+	}
+	if err := job.Fail(cause.Error()); err != nil {
+		return err
+	}
+	if err := s.jobs.Finish(ctx, job); err != nil {
+		return err
+	}
+	return cause
+}
+
+// importProducts creates a product for each row through the product bulk
+// operation. It returns one error per row, nil for rows that were imported.
+// Each row's product gets an ID derived from the job and the row number, so
+// a batch imported again after its worker stopped before saving progress
+// finds its products already there and reports them as imported.
+func (s *Service) importProducts(ctx context.Context, job *importjob.Job, batch []row) ([]error, error) {
+	errs := make([]error, len(batch))
+	operations := make([]appproduct.BulkOperation, 0, len(batch))
+	positions := make([]int, 0, len(batch))
+	for i, r := range batch {
+		if r.err != nil {
+			errs[i] = r.err
+			continue
+		}
+
+		price, err := strconv.ParseFloat(r.values["price"], 64)
+		if err != nil {
+			errs[i] = errInvalidPrice
+			continue
+		}
+
+		operations = append(operations, appproduct.BulkOperation{
+			Action:      string(product.BulkCreate),
+			NewID:       rowProductID(job.ID, r.number),
+			Name:        r.values["name"],
+			Description: r.values["description"],
+			Price:       &price,
+			Category:    r.values["category"],
+			TaxClass:    r.values["tax_class"],
+		})
+		positions = append(positions, i)
+	}
+	if len(operations) == 0 {
+		return errs, nil
+	}
+
+	var results []*appproduct.BulkResult
+	var err error
+	if job.DryRun {
+		results, err = s.products.ValidateBulk(ctx, operations)
+	} else {
+		results, err = s.products.Bulk(ctx, operations, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range positions {
+		if !errors.Is(results[j].Err, product.ErrProductExists) {
+			errs[i] = results[j].Err
+		}
+	}
+	return errs, nil
+}
+
+// rowProductID derives the ID of the product imported from a row. It keeps
+// the job ID's timestamp, so products sort by ID as if created with the job,
+// and fills the rest from a hash of the job ID and row number.
+func rowProductID(jobID primitive.ObjectID, number int) primitive.ObjectID {
+	h := sha256.New()
+	h.Write(jobID[:])
+	binary.Write(h, binary.BigEndian, int64(number))
+	sum := h.Sum(nil)
+
+	var id primitive.ObjectID
+	copy(id[:4], jobID[:4])
+	copy(id[4:], sum)
+	return id
+}
+
+// importAssortments adds the product of each row to the row's store. A dry
+// run applies the rows to copies of the stores kept in dryRunStores, so that
+// duplicates within the file are caught too.
+func (s *Service) importAssortments(ctx context.Context, batch []row, dryRun bool, dryRunStores map[string]*store.Store) ([]error, error) {
+	errs := make([]error, len(batch))
+	productIDs := make([]primitive.ObjectID, len(batch))
+	hexIDs := make([]string, 0, len(batch))
+	for i, r := range batch {
+		if r.err != nil {
+			errs[i] = r.err
+			continue
+		}
+
+		id, err := primitive.ObjectIDFromHex(r.values["product_id"])
+		if err != nil {
+			errs[i] = fmt.Errorf("invalid product_id: %w", err)
+			continue
+		}
+		productIDs[i] = id
+		hexIDs = append(hexIDs, id.Hex())
+	}
+	if len(hexIDs) == 0 {
+		return errs, nil
+	}
+
+	_, missing, err := s.products.GetProducts(ctx, hexIDs)
+	if err != nil {
+		return nil, err
+	}
+	unknown := make(map[string]bool, len(missing))
+	for _, id := range missing {
+		unknown[id] = true
+	}
+
+	for i, r := range batch {
+		if errs[i] != nil {
+			continue
+		}
+		if unknown[productIDs[i].Hex()] {
+			errs[i] = product.ErrProductNotFound
+			continue
+		}
+
+		storeID := r.values["store_id"]
+		if !dryRun {
+			errs[i] = s.stores.AddProductToStore(ctx, storeID, productIDs[i])
+			continue
+		}
+
+		st, ok := dryRunStores[storeID]
+		if !ok {
+			st, err = s.stores.GetStore(ctx, storeID)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			dryRunStores[storeID] = st
+		}
+		errs[i] = st.AddProduct(productIDs[i])
+	}
+	return errs, nil
+}
+
+func recordRow(job *importjob.Job, err error) {
+	outcome := "imported"
+	if err != nil {
+		outcome = "rejected"
+	} else if job.DryRun {
+		outcome = "validated"
+	}
+	metrics.ImportRowsTotal.WithLabelValues(string(job.Kind), outcome).Inc()
+}
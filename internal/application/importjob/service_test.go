@@ -0,0 +1,379 @@
+package importjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appproduct "github.com/stasshander/ddd/internal/application/product"
+	"github.com/stasshander/ddd/internal/domain/importjob"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// directRunner runs units of work without a transaction
+type directRunner struct{}
+
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memoryJobs keeps jobs in a map and their files apart, and claims and saves
+// jobs the same way as the MongoDB repository
+type memoryJobs struct {
+	mu       sync.Mutex
+	jobs     map[primitive.ObjectID]importjob.Job
+	payloads map[primitive.ObjectID][]byte
+	// progress holds the processed rows of every saved batch
+	progress []int
+}
+
+func newMemoryJobs() *memoryJobs {
+	return &memoryJobs{
+		jobs:     make(map[primitive.ObjectID]importjob.Job),
+		payloads: make(map[primitive.ObjectID][]byte),
+	}
+}
+
+func (m *memoryJobs) Create(ctx context.Context, job *importjob.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads[job.ID] = job.Payload
+	stored := *job
+	stored.Payload = nil
+	m.jobs[job.ID] = stored
+	return nil
+}
+
+func (m *memoryJobs) GetByID(ctx context.Context, id string) (*importjob.Job, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[objectID]
+	if !ok {
+		return nil, importjob.ErrJobNotFound
+	}
+	return &job, nil
+}
+
+func (m *memoryJobs) List(ctx context.Context, page, limit int) ([]*importjob.Job, int, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (m *memoryJobs) ClaimNext(ctx context.Context, lease time.Duration) (*importjob.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+
+	var next *importjob.Job
+	for _, job := range m.jobs {
+		claimable := job.Status == importjob.StatusQueued ||
+			job.Status == importjob.StatusRunning && (job.LeaseUntil == nil || job.LeaseUntil.Before(now))
+		if claimable && (next == nil || job.CreatedAt.Before(next.CreatedAt)) {
+			job := job
+			next = &job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	until := now.Add(lease)
+	next.Status = importjob.StatusRunning
+	if next.StartedAt == nil {
+		next.StartedAt = &now
+	}
+	next.ClaimedAt = &now
+	next.LeaseUntil = &until
+	m.jobs[next.ID] = *next
+
+	claimed := *next
+	claimed.Payload = m.payloads[next.ID]
+	return &claimed, nil
+}
+
+func (m *memoryJobs) SaveProgress(ctx context.Context, job *importjob.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.jobs[job.ID]
+	if !ok || !stored.ClaimedAt.Equal(*job.ClaimedAt) {
+		return importjob.ErrLeaseLost
+	}
+	stored.ProcessedRows = job.ProcessedRows
+	stored.ImportedRows = job.ImportedRows
+	stored.RejectedRows = job.RejectedRows
+	stored.Rejections = append([]importjob.Rejection(nil), job.Rejections...)
+	stored.LeaseUntil = job.LeaseUntil
+	m.jobs[job.ID] = stored
+	m.progress = append(m.progress, job.ProcessedRows)
+	return nil
+}
+
+func (m *memoryJobs) Finish(ctx context.Context, job *importjob.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.jobs[job.ID]
+	if !ok || !stored.ClaimedAt.Equal(*job.ClaimedAt) {
+		return importjob.ErrLeaseLost
+	}
+	finished := *job
+	finished.Payload = nil
+	finished.LeaseUntil = nil
+	m.jobs[job.ID] = finished
+	delete(m.payloads, job.ID)
+	return nil
+}
+
+// steal lets another worker claim the job, as if this worker's lease had
+// run out
+func (m *memoryJobs) steal(id primitive.ObjectID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := m.jobs[id]
+	later := job.ClaimedAt.Add(time.Minute)
+	job.ClaimedAt = &later
+	m.jobs[id] = job
+}
+
+// memoryProducts stores the products created through bulk writes and, like
+// the unique _id index, rejects a create that reuses an ID
+type memoryProducts struct {
+	product.Repository
+	created []*product.Product
+	ids     map[primitive.ObjectID]bool
+	// err fails every bulk write
+	err error
+	// beforeWrite runs before each bulk write
+	beforeWrite func()
+}
+
+func (m *memoryProducts) BulkWrite(ctx context.Context, writes []*product.BulkWrite) ([]error, error) {
+	if m.beforeWrite != nil {
+		m.beforeWrite()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	errs := make([]error, len(writes))
+	for i, w := range writes {
+		if m.ids[w.ProductID] {
+			errs[i] = product.ErrProductExists
+			continue
+		}
+		m.ids[w.ProductID] = true
+		m.created = append(m.created, w.Product)
+	}
+	return errs, nil
+}
+
+// productCSV returns a product import of n rows. The rows numbered in bad,
+// counting from 1, have an invalid price.
+func productCSV(n int, bad ...int) []byte {
+	invalid := make(map[int]bool, len(bad))
+	for _, i := range bad {
+		invalid[i] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("name,description,price\n")
+	for i := 1; i <= n; i++ {
+		price := "4.50"
+		if invalid[i] {
+			price = "free"
+		}
+		fmt.Fprintf(&b, "Tea %d,Green tea,%s\n", i, price)
+	}
+	return []byte(b.String())
+}
+
+func newTestService() (*Service, *memoryJobs, *memoryProducts) {
+	jobs := newMemoryJobs()
+	products := &memoryProducts{ids: make(map[primitive.ObjectID]bool)}
+	return NewService(jobs, appproduct.NewService(products, directRunner{}), nil, time.Minute), jobs, products
+}
+
+func TestProcessNextImportsProducts(t *testing.T) {
+	ctx := context.Background()
+	service, jobs, products := newTestService()
+
+	submitted, err := service.Submit(ctx, "products", "csv", false, productCSV(250, 120))
+	assert.NoError(t, err)
+	assert.Equal(t, importjob.StatusQueued, submitted.Status)
+
+	processed, err := service.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	job, err := service.GetJob(ctx, submitted.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, importjob.StatusCompleted, job.Status)
+	assert.Equal(t, 250, job.ProcessedRows)
+	assert.Equal(t, 249, job.ImportedRows)
+	assert.Equal(t, 1, job.RejectedRows)
+	if assert.Len(t, job.Rejections, 1) {
+		assert.Equal(t, 121, job.Rejections[0].Row)
+	}
+	assert.NotNil(t, job.FinishedAt)
+	assert.Nil(t, job.LeaseUntil)
+	assert.Equal(t, []int{100, 200, 250}, jobs.progress)
+	assert.Len(t, products.created, 249)
+	assert.Empty(t, jobs.payloads)
+
+	processed, err = service.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.False(t, processed)
+}
+
+func TestProcessNextFailsJob(t *testing.T) {
+	ctx := context.Background()
+	service, _, products := newTestService()
+	products.err = errors.New("connection reset")
+
+	submitted, err := service.Submit(ctx, "products", "csv", false, productCSV(3))
+	assert.NoError(t, err)
+
+	processed, err := service.ProcessNext(ctx)
+	assert.ErrorIs(t, err, products.err)
+	assert.True(t, processed)
+
+	job, err := service.GetJob(ctx, submitted.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, importjob.StatusFailed, job.Status)
+	assert.Equal(t, "connection reset", job.Error)
+	assert.Equal(t, 0, job.ProcessedRows)
+}
+
+func TestProcessNextResumesAbandonedJob(t *testing.T) {
+	ctx := context.Background()
+	service, jobs, products := newTestService()
+
+	submitted, err := service.Submit(ctx, "products", "csv", false, productCSV(250))
+	assert.NoError(t, err)
+
+	// A worker imported the first batch and stopped before its lease ran out
+	abandoned := jobs.jobs[submitted.ID]
+	startedAt := time.Now().Add(-time.Hour)
+	leaseUntil := time.Now().Add(-time.Second)
+	abandoned.Status = importjob.StatusRunning
+	abandoned.StartedAt = &startedAt
+	abandoned.ClaimedAt = &startedAt
+	abandoned.LeaseUntil = &leaseUntil
+	abandoned.ProcessedRows = 100
+	abandoned.ImportedRows = 100
+	jobs.jobs[submitted.ID] = abandoned
+
+	processed, err := service.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	job, err := service.GetJob(ctx, submitted.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, importjob.StatusCompleted, job.Status)
+	assert.Equal(t, 250, job.ProcessedRows)
+	assert.Equal(t, 250, job.ImportedRows)
+	assert.True(t, startedAt.Equal(*job.StartedAt))
+	assert.Equal(t, []int{200, 250}, jobs.progress)
+	if assert.Len(t, products.created, 150) {
+		assert.Equal(t, "Tea 101", products.created[0].Name)
+	}
+}
+
+func TestProcessNextReplaysUnsavedBatch(t *testing.T) {
+	ctx := context.Background()
+	service, jobs, products := newTestService()
+
+	submitted, err := service.Submit(ctx, "products", "csv", false, productCSV(250))
+	assert.NoError(t, err)
+
+	// The first worker loses its lease while writing the second batch, so
+	// the batch is stored but its progress is not
+	batches := 0
+	products.beforeWrite = func() {
+		batches++
+		if batches == 2 {
+			jobs.steal(submitted.ID)
+		}
+	}
+	_, err = service.ProcessNext(ctx)
+	assert.ErrorIs(t, err, importjob.ErrLeaseLost)
+	assert.Len(t, products.created, 200)
+
+	expired := jobs.jobs[submitted.ID]
+	leaseUntil := time.Now().Add(-time.Second)
+	expired.LeaseUntil = &leaseUntil
+	jobs.jobs[submitted.ID] = expired
+
+	processed, err := service.ProcessNext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	job, err := service.GetJob(ctx, submitted.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, importjob.StatusCompleted, job.Status)
+	assert.Equal(t, 250, job.ImportedRows)
+	assert.Equal(t, 0, job.RejectedRows)
+	assert.Len(t, products.created, 250)
+}
+
+func TestProcessNextLeavesInterruptedJobRunning(t *testing.T) {
+	testCases := []struct {
+		name      string
+		interrupt func(cancel context.CancelFunc, jobs *memoryJobs, id primitive.ObjectID)
+		wantErr   error
+	}{
+		{
+			name: "shutdown",
+			interrupt: func(cancel context.CancelFunc, jobs *memoryJobs, id primitive.ObjectID) {
+				cancel()
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "lease taken over",
+			interrupt: func(cancel context.CancelFunc, jobs *memoryJobs, id primitive.ObjectID) {
+				jobs.steal(id)
+			},
+			wantErr: importjob.ErrLeaseLost,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			service, jobs, products := newTestService()
+
+			submitted, err := service.Submit(ctx, "products", "csv", false, productCSV(250))
+			assert.NoError(t, err)
+
+			batches := 0
+			products.beforeWrite = func() {
+				batches++
+				if batches == 2 {
+					tc.interrupt(cancel, jobs, submitted.ID)
+				}
+			}
+
+			processed, err := service.ProcessNext(ctx)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.True(t, processed)
+
+			job, err := service.GetJob(context.Background(), submitted.ID.Hex())
+			assert.NoError(t, err)
+			assert.Equal(t, importjob.StatusRunning, job.Status)
+			assert.Equal(t, 100, job.ProcessedRows)
+			assert.Empty(t, job.Error)
+		})
+	}
+}
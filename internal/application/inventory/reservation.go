@@ -0,0 +1,142 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sweepBatchSize bounds how many expired reservations one sweep releases, so
+// a backlog is worked off over several ticks rather than in one long pass.
+const sweepBatchSize = 100
+
+// Reserve holds quantity units of a product in a store. Taking the units out
+// of the available stock is atomic, so two concurrent reservations can never
+// both be granted the last unit, and it is stored together with the
+// reservation, so held stock always has a reservation the sweeper can
+// release.
+func (s *Service) Reserve(ctx context.Context, storeID, productID string, quantity int) (*inventory.Reservation, error) {
+	storeObjectID, err := primitive.ObjectIDFromHex(storeID)
+	if err != nil {
+		return nil, err
+	}
+	productObjectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := inventory.NewReservation(storeObjectID, productObjectID, quantity, s.reservationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.Reserve(ctx, storeObjectID, productObjectID, quantity); err != nil {
+			return err
+		}
+		return s.reservations.Create(ctx, reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *Service) GetReservation(ctx context.Context, id string) (*inventory.Reservation, error) {
+	return s.reservations.GetByID(ctx, id)
+}
+
+// ConfirmReservation completes the sale, removing the held units from stock
+func (s *Service) ConfirmReservation(ctx context.Context, id string) (*inventory.Reservation, error) {
+	return s.settle(ctx, id, (*inventory.Reservation).Confirm, s.repo.Commit)
+}
+
+// CancelReservation returns the held units to the available stock
+func (s *Service) CancelReservation(ctx context.Context, id string) (*inventory.Reservation, error) {
+	return s.settle(ctx, id, (*inventory.Reservation).Cancel, s.repo.Release)
+}
+
+// ReleaseExpired expires active reservations whose hold has lapsed and
+// returns their units to stock. It returns the number released.
+func (s *Service) ReleaseExpired(ctx context.Context) (int, error) {
+	expired, err := s.reservations.ListExpired(ctx, time.Now(), sweepBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, reservation := range expired {
+		_, err := s.apply(ctx, reservation, (*inventory.Reservation).Expire, s.repo.Release)
+		if errors.Is(err, inventory.ErrConcurrentUpdate) {
+			// Confirmed or cancelled while the sweep was running
+			continue
+		}
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+
+	metrics.ReservationsExpiredTotal.Add(float64(released))
+	return released, nil
+}
+
+// RunSweeper releases expired reservations every interval until ctx is done
+func (s *Service) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.ReleaseExpired(ctx)
+			if err != nil {
+				log.Printf("Failed to release expired reservations: %v", err)
+			}
+			if released > 0 {
+				log.Printf("Released %d expired reservations", released)
+			}
+		}
+	}
+}
+
+func (s *Service) settle(ctx context.Context, id string, change func(*inventory.Reservation) error, effect func(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error) (*inventory.Reservation, error) {
+	reservation, err := s.reservations.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.apply(ctx, reservation, change, effect)
+}
+
+// apply saves a status change and its effect on stock as one unit of work,
+// so a failed effect leaves the reservation active and holding its stock.
+// The save is conditional on the reservation still being active, so exactly
+// one of a confirm, cancel or expiry racing on the same reservation touches
+// stock.
+func (s *Service) apply(ctx context.Context, reservation *inventory.Reservation, change func(*inventory.Reservation) error, effect func(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error) (*inventory.Reservation, error) {
+	previous := reservation.Status
+	if err := change(reservation); err != nil {
+		return nil, err
+	}
+
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		if err := s.reservations.UpdateStatus(ctx, reservation, previous); err != nil {
+			return err
+		}
+		return effect(ctx, reservation.StoreID, reservation.ProductID, reservation.Quantity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
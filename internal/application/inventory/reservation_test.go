@@ -0,0 +1,222 @@
+package inventory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// directRunner runs units of work without a transaction
+type directRunner struct{}
+
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memoryStock keeps a single stock item and applies changes under a lock,
+// like the conditional updates of the MongoDB repository
+type memoryStock struct {
+	mu   sync.Mutex
+	item inventory.StockItem
+}
+
+func (m *memoryStock) Get(ctx context.Context, storeID, productID primitive.ObjectID) (*inventory.StockItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := m.item
+	return &item, nil
+}
+
+func (m *memoryStock) ListByStore(ctx context.Context, storeID primitive.ObjectID, page, limit int) ([]*inventory.StockItem, int, error) {
+	item, _ := m.Get(ctx, storeID, primitive.NilObjectID)
+	return []*inventory.StockItem{item}, 1, nil
+}
+
+func (m *memoryStock) SetOnHand(ctx context.Context, storeID, productID primitive.ObjectID, onHand int) (*inventory.StockItem, error) {
+	m.mu.Lock()
+	m.item.OnHand = onHand
+	m.mu.Unlock()
+	return m.Get(ctx, storeID, productID)
+}
+
+func (m *memoryStock) SetReorderRule(ctx context.Context, storeID, productID primitive.ObjectID, point, quantity int) (*inventory.StockItem, error) {
+	m.mu.Lock()
+	m.item.ReorderPoint = point
+	m.item.ReorderQuantity = quantity
+	m.mu.Unlock()
+	return m.Get(ctx, storeID, productID)
+}
+
+func (m *memoryStock) ListBelowReorderPoint(ctx context.Context) ([]*inventory.StockItem, error) {
+	item, _ := m.Get(ctx, primitive.NilObjectID, primitive.NilObjectID)
+	if !item.BelowReorderPoint(0) {
+		return nil, nil
+	}
+	return []*inventory.StockItem{item}, nil
+}
+
+func (m *memoryStock) Receive(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.item.OnHand += quantity
+	return nil
+}
+
+func (m *memoryStock) Reserve(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.item.Available() < quantity {
+		return inventory.ErrInsufficientStock
+	}
+	m.item.Reserved += quantity
+	return nil
+}
+
+func (m *memoryStock) Release(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.item.Reserved -= quantity
+	return nil
+}
+
+func (m *memoryStock) Commit(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.item.OnHand -= quantity
+	m.item.Reserved -= quantity
+	return nil
+}
+
+type memoryReservations struct {
+	mu           sync.Mutex
+	reservations map[primitive.ObjectID]inventory.Reservation
+}
+
+func newMemoryReservations() *memoryReservations {
+	return &memoryReservations{reservations: make(map[primitive.ObjectID]inventory.Reservation)}
+}
+
+func (m *memoryReservations) Create(ctx context.Context, reservation *inventory.Reservation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reservations[reservation.ID] = *reservation
+	return nil
+}
+
+func (m *memoryReservations) GetByID(ctx context.Context, id string) (*inventory.Reservation, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reservation, ok := m.reservations[objectID]
+	if !ok {
+		return nil, inventory.ErrReservationNotFound
+	}
+	return &reservation, nil
+}
+
+func (m *memoryReservations) UpdateStatus(ctx context.Context, reservation *inventory.Reservation, previous inventory.ReservationStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reservations[reservation.ID].Status != previous {
+		return inventory.ErrConcurrentUpdate
+	}
+	m.reservations[reservation.ID] = *reservation
+	return nil
+}
+
+func (m *memoryReservations) ListExpired(ctx context.Context, t time.Time, limit int) ([]*inventory.Reservation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var expired []*inventory.Reservation
+	for _, reservation := range m.reservations {
+		if reservation.Status == inventory.ReservationActive && reservation.ExpiredAt(t) {
+			reservation := reservation
+			expired = append(expired, &reservation)
+		}
+	}
+	return expired, nil
+}
+
+func TestConcurrentReservationsDoNotOversell(t *testing.T) {
+	stock := &memoryStock{item: inventory.StockItem{OnHand: 5}}
+	service := NewService(stock, newMemoryReservations(), nil, nil, directRunner{}, time.Minute)
+	storeID, productID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	granted := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.Reserve(context.Background(), storeID, productID, 1); err == nil {
+				mu.Lock()
+				granted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, granted)
+	assert.Equal(t, 5, stock.item.Reserved)
+}
+
+func TestReservationLifecycle(t *testing.T) {
+	stock := &memoryStock{item: inventory.StockItem{OnHand: 5}}
+	service := NewService(stock, newMemoryReservations(), nil, nil, directRunner{}, time.Minute)
+	storeID, productID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	ctx := context.Background()
+
+	confirmed, err := service.Reserve(ctx, storeID, productID, 2)
+	assert.NoError(t, err)
+	cancelled, err := service.Reserve(ctx, storeID, productID, 3)
+	assert.NoError(t, err)
+
+	_, err = service.Reserve(ctx, storeID, productID, 1)
+	assert.ErrorIs(t, err, inventory.ErrInsufficientStock)
+
+	_, err = service.ConfirmReservation(ctx, confirmed.ID.Hex())
+	assert.NoError(t, err)
+	_, err = service.CancelReservation(ctx, cancelled.ID.Hex())
+	assert.NoError(t, err)
+
+	_, err = service.CancelReservation(ctx, confirmed.ID.Hex())
+	assert.ErrorIs(t, err, inventory.ErrReservationNotActive)
+
+	assert.Equal(t, 3, stock.item.OnHand)
+	assert.Equal(t, 0, stock.item.Reserved)
+}
+
+func TestReleaseExpired(t *testing.T) {
+	stock := &memoryStock{item: inventory.StockItem{OnHand: 5}}
+	reservations := newMemoryReservations()
+	service := NewService(stock, reservations, nil, nil, directRunner{}, time.Minute)
+	storeID, productID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	ctx := context.Background()
+
+	lapsed, err := service.Reserve(ctx, storeID, productID, 4)
+	assert.NoError(t, err)
+	_, err = service.Reserve(ctx, storeID, productID, 1)
+	assert.NoError(t, err)
+
+	stored := reservations.reservations[lapsed.ID]
+	stored.ExpiresAt = time.Now().Add(-time.Second)
+	reservations.reservations[lapsed.ID] = stored
+
+	released, err := service.ReleaseExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Equal(t, 1, stock.item.Reserved)
+
+	_, err = service.ConfirmReservation(ctx, lapsed.ID.Hex())
+	assert.ErrorIs(t, err, inventory.ErrReservationNotActive)
+}
@@ -0,0 +1,82 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"github.com/stasshander/ddd/internal/application/transaction"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+)
+
+type Service struct {
+	repo           inventory.Repository
+	reservations   inventory.ReservationRepository
+	stores         store.Repository
+	products       product.Repository
+	tx             transaction.Runner
+	reservationTTL time.Duration
+}
+
+// NewService creates an inventory service. Reservations hold stock for
+// reservationTTL unless they are confirmed or cancelled first. A reservation
+// and the stock it holds are changed together in units of work run by tx.
+func NewService(repo inventory.Repository, reservations inventory.ReservationRepository, stores store.Repository, products product.Repository, tx transaction.Runner, reservationTTL time.Duration) *Service {
+	return &Service{
+		repo:           repo,
+		reservations:   reservations,
+		stores:         stores,
+		products:       products,
+		tx:             tx,
+		reservationTTL: reservationTTL,
+	}
+}
+
+// SetStock records the units of a product on hand in a store
+func (s *Service) SetStock(ctx context.Context, storeID, productID string, onHand int) (*inventory.StockItem, error) {
+	if err := inventory.ValidateOnHand(onHand); err != nil {
+		return nil, err
+	}
+
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.SetOnHand(ctx, st.ID, p.ID, onHand)
+}
+
+// SetReorderRule sets the stock level at which a product should be
+// reordered in a store and how many units to order
+func (s *Service) SetReorderRule(ctx context.Context, storeID, productID string, point, quantity int) (*inventory.StockItem, error) {
+	if err := inventory.ValidateReorderRule(point, quantity); err != nil {
+		return nil, err
+	}
+
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.SetReorderRule(ctx, st.ID, p.ID, point, quantity)
+}
+
+func (s *Service) ListStock(ctx context.Context, storeID string, page, limit int) ([]*inventory.StockItem, int, error) {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.ListByStore(ctx, st.ID, page, limit)
+}
@@ -0,0 +1,158 @@
+package order
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/application/transaction"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/order"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service struct {
+	repo     order.Repository
+	products product.Repository
+	stores   store.Repository
+	stock    inventory.Repository
+	tx       transaction.Runner
+}
+
+// NewService creates an order service. Orders and the stock they reserve
+// are changed together in units of work run by tx.
+func NewService(repo order.Repository, products product.Repository, stores store.Repository, stock inventory.Repository, tx transaction.Runner) *Service {
+	return &Service{
+		repo:     repo,
+		products: products,
+		stores:   stores,
+		stock:    stock,
+		tx:       tx,
+	}
+}
+
+// Item is a product and quantity requested for an order
+type Item struct {
+	ProductID string
+	Quantity  int
+}
+
+// PlaceOrder creates an order in an open store, snapshotting current product
+// prices and reserving stock for every line. The order and its reservations
+// are stored together: if any line cannot be reserved, nothing is.
+func (s *Service) PlaceOrder(ctx context.Context, storeID string, items []Item) (*order.Order, error) {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if st.CurrentStatus() != store.StatusOpen {
+		return nil, order.ErrStoreNotOpen
+	}
+
+	carried := make(map[primitive.ObjectID]bool, len(st.Products))
+	for _, id := range st.Products {
+		carried[id] = true
+	}
+
+	lines := make([]order.LineItem, 0, len(items))
+	for _, item := range items {
+		p, err := s.products.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if !carried[p.ID] {
+			return nil, order.ErrProductNotInStore
+		}
+
+		line, err := order.NewLineItem(p, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	o, err := order.NewOrder(st.ID, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		for _, line := range o.Lines {
+			if err := s.stock.Reserve(ctx, o.StoreID, line.ProductID, line.Quantity); err != nil {
+				return err
+			}
+		}
+		return s.repo.Create(ctx, o)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (s *Service) GetOrder(ctx context.Context, id string) (*order.Order, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) ListOrders(ctx context.Context, filter order.ListFilter, page, limit int) ([]*order.Order, int, error) {
+	return s.repo.List(ctx, filter, page, limit)
+}
+
+func (s *Service) PayOrder(ctx context.Context, id string) (*order.Order, error) {
+	return s.transition(ctx, id, (*order.Order).Pay, nil)
+}
+
+// FulfilOrder completes a paid order, taking its reserved units out of stock
+func (s *Service) FulfilOrder(ctx context.Context, id string) (*order.Order, error) {
+	return s.transition(ctx, id, (*order.Order).Fulfil, func(ctx context.Context, o *order.Order) error {
+		for _, line := range o.Lines {
+			if err := s.stock.Commit(ctx, o.StoreID, line.ProductID, line.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CancelOrder cancels an order and returns its reserved units to stock
+func (s *Service) CancelOrder(ctx context.Context, id string) (*order.Order, error) {
+	return s.transition(ctx, id, (*order.Order).Cancel, func(ctx context.Context, o *order.Order) error {
+		for _, line := range o.Lines {
+			if err := s.stock.Release(ctx, o.StoreID, line.ProductID, line.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// transition applies a status change and its effect on stock as one unit of
+// work, so a failed effect leaves the order in its previous status and the
+// change can be retried. The conditional save guarantees the effect runs
+// once even if the same change is requested concurrently.
+func (s *Service) transition(ctx context.Context, id string, change func(*order.Order) error, effect func(context.Context, *order.Order) error) (*order.Order, error) {
+	o, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := o.Status
+	if err := change(o); err != nil {
+		return nil, err
+	}
+
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateStatus(ctx, o, previous); err != nil {
+			return err
+		}
+		if effect != nil {
+			return effect(ctx, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
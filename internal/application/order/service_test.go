@@ -0,0 +1,270 @@
+package order
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/stasshander/ddd/internal/application/transaction"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/order"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/product/producttest"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// directRunner runs units of work without a transaction
+type directRunner struct{}
+
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// rollbackRunner restores the stock and orders when a unit of work fails,
+// standing in for a transaction around both repositories
+type rollbackRunner struct {
+	orders *memoryOrders
+	stock  *memoryStock
+}
+
+func (r rollbackRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	orders := maps.Clone(r.orders.orders)
+	levels := maps.Clone(r.stock.levels)
+
+	err := fn(ctx)
+	if err != nil {
+		r.orders.orders = orders
+		r.stock.levels = levels
+	}
+	return err
+}
+
+// memoryOrders keeps copies of orders and saves status changes on the same
+// condition as the MongoDB repository
+type memoryOrders struct {
+	orders map[primitive.ObjectID]order.Order
+	// afterGet runs once after the next GetByID, when set
+	afterGet func()
+}
+
+func (m *memoryOrders) Create(ctx context.Context, o *order.Order) error {
+	m.orders[o.ID] = *o
+	return nil
+}
+
+func (m *memoryOrders) GetByID(ctx context.Context, id string) (*order.Order, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := m.orders[objectID]
+	if !ok {
+		return nil, order.ErrOrderNotFound
+	}
+	if after := m.afterGet; after != nil {
+		m.afterGet = nil
+		after()
+	}
+	return &o, nil
+}
+
+func (m *memoryOrders) UpdateStatus(ctx context.Context, o *order.Order, previous order.Status) error {
+	stored, ok := m.orders[o.ID]
+	if !ok {
+		return order.ErrOrderNotFound
+	}
+	if stored.Status != previous {
+		return order.ErrConcurrentUpdate
+	}
+	m.orders[o.ID] = *o
+	return nil
+}
+
+func (m *memoryOrders) List(ctx context.Context, filter order.ListFilter, page, limit int) ([]*order.Order, int, error) {
+	return nil, 0, nil
+}
+
+// stockLevel is the stock of one product
+type stockLevel struct {
+	onHand   int
+	reserved int
+}
+
+// memoryStock holds the stock of every product in a single store; any other
+// inventory.Repository method panics
+type memoryStock struct {
+	inventory.Repository
+	levels map[primitive.ObjectID]stockLevel
+}
+
+func (m *memoryStock) Reserve(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	level := m.levels[productID]
+	if level.onHand-level.reserved < quantity {
+		return inventory.ErrInsufficientStock
+	}
+	level.reserved += quantity
+	m.levels[productID] = level
+	return nil
+}
+
+func (m *memoryStock) Release(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	level := m.levels[productID]
+	level.reserved -= quantity
+	m.levels[productID] = level
+	return nil
+}
+
+func (m *memoryStock) Commit(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	level := m.levels[productID]
+	level.onHand -= quantity
+	level.reserved -= quantity
+	m.levels[productID] = level
+	return nil
+}
+
+type singleStore struct {
+	store.Repository
+	store *store.Store
+}
+
+func (s *singleStore) GetByID(ctx context.Context, id string) (*store.Store, error) {
+	if id != s.store.ID.Hex() {
+		return nil, store.ErrStoreNotFound
+	}
+	return s.store, nil
+}
+
+type orderFixture struct {
+	orders   *memoryOrders
+	products *producttest.Repository
+	stock    *memoryStock
+	store    *store.Store
+	tea      *product.Product
+	coffee   *product.Product
+}
+
+// newOrderFixture sets up an open store carrying tea and coffee, with 5 units
+// of tea and 1 of coffee in stock
+func newOrderFixture(t *testing.T) *orderFixture {
+	ctx := context.Background()
+	products := producttest.NewRepository()
+	tea, err := product.NewProduct("Tea", "Green tea", 4.5)
+	assert.NoError(t, err)
+	assert.NoError(t, products.Create(ctx, tea))
+	coffee, err := product.NewProduct("Coffee", "Ground coffee", 7)
+	assert.NoError(t, err)
+	assert.NoError(t, products.Create(ctx, coffee))
+
+	st, err := store.NewStore("Main Street", "1 Main Street")
+	assert.NoError(t, err)
+	assert.NoError(t, st.Open())
+	assert.NoError(t, st.AddProduct(tea.ID))
+	assert.NoError(t, st.AddProduct(coffee.ID))
+
+	return &orderFixture{
+		orders:   &memoryOrders{orders: make(map[primitive.ObjectID]order.Order)},
+		products: products,
+		stock: &memoryStock{levels: map[primitive.ObjectID]stockLevel{
+			tea.ID:    {onHand: 5},
+			coffee.ID: {onHand: 1},
+		}},
+		store:  st,
+		tea:    tea,
+		coffee: coffee,
+	}
+}
+
+func (f *orderFixture) service(tx transaction.Runner) *Service {
+	return NewService(f.orders, f.products, &singleStore{store: f.store}, f.stock, tx)
+}
+
+func TestPlaceOrderReservesNothingWhenALineIsShort(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+	service := f.service(rollbackRunner{orders: f.orders, stock: f.stock})
+
+	_, err := service.PlaceOrder(ctx, f.store.ID.Hex(), []Item{
+		{ProductID: f.tea.ID.Hex(), Quantity: 2},
+		{ProductID: f.coffee.ID.Hex(), Quantity: 2},
+	})
+	assert.ErrorIs(t, err, inventory.ErrInsufficientStock)
+	assert.Equal(t, stockLevel{onHand: 5}, f.stock.levels[f.tea.ID])
+	assert.Equal(t, stockLevel{onHand: 1}, f.stock.levels[f.coffee.ID])
+	assert.Empty(t, f.orders.orders)
+
+	placed, err := service.PlaceOrder(ctx, f.store.ID.Hex(), []Item{
+		{ProductID: f.tea.ID.Hex(), Quantity: 2},
+		{ProductID: f.coffee.ID.Hex(), Quantity: 1},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, order.StatusPlaced, placed.Status)
+	assert.Equal(t, stockLevel{onHand: 5, reserved: 2}, f.stock.levels[f.tea.ID])
+	assert.Equal(t, stockLevel{onHand: 1, reserved: 1}, f.stock.levels[f.coffee.ID])
+}
+
+func TestOrderTransitionsMoveStock(t *testing.T) {
+	testCases := []struct {
+		name       string
+		transition func(s *Service, ctx context.Context, id string) (*order.Order, error)
+		wantStatus order.Status
+		wantTea    stockLevel
+	}{
+		{
+			name:       "cancel releases units",
+			transition: (*Service).CancelOrder,
+			wantStatus: order.StatusCancelled,
+			wantTea:    stockLevel{onHand: 5},
+		},
+		{
+			name:       "fulfil commits units",
+			transition: (*Service).FulfilOrder,
+			wantStatus: order.StatusFulfilled,
+			wantTea:    stockLevel{onHand: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newOrderFixture(t)
+			service := f.service(directRunner{})
+
+			placed, err := service.PlaceOrder(ctx, f.store.ID.Hex(), []Item{{ProductID: f.tea.ID.Hex(), Quantity: 2}})
+			assert.NoError(t, err)
+			_, err = service.PayOrder(ctx, placed.ID.Hex())
+			assert.NoError(t, err)
+
+			changed, err := tc.transition(service, ctx, placed.ID.Hex())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, changed.Status)
+			assert.Equal(t, tc.wantStatus, f.orders.orders[placed.ID].Status)
+			assert.Equal(t, tc.wantTea, f.stock.levels[f.tea.ID])
+		})
+	}
+}
+
+func TestFulfilOrderOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newOrderFixture(t)
+	service := f.service(directRunner{})
+
+	placed, err := service.PlaceOrder(ctx, f.store.ID.Hex(), []Item{{ProductID: f.tea.ID.Hex(), Quantity: 2}})
+	assert.NoError(t, err)
+	_, err = service.PayOrder(ctx, placed.ID.Hex())
+	assert.NoError(t, err)
+
+	// Another request fulfils the order after this one has read it as paid
+	f.orders.afterGet = func() {
+		_, err := service.FulfilOrder(ctx, placed.ID.Hex())
+		assert.NoError(t, err)
+	}
+	_, err = service.FulfilOrder(ctx, placed.ID.Hex())
+	assert.ErrorIs(t, err, order.ErrConcurrentUpdate)
+	assert.Equal(t, stockLevel{onHand: 3}, f.stock.levels[f.tea.ID])
+
+	_, err = service.FulfilOrder(ctx, placed.ID.Hex())
+	assert.ErrorIs(t, err, order.ErrInvalidStatusTransition)
+	assert.Equal(t, stockLevel{onHand: 3}, f.stock.levels[f.tea.ID])
+}
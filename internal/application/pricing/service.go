@@ -0,0 +1,107 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/promotion"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/domain/tax"
+)
+
+// Service prices products as sold in a particular store, combining the
+// catalog price with the promotions and taxes that apply there.
+type Service struct {
+	products   product.Repository
+	stores     store.Repository
+	promotions promotion.Repository
+	taxes      tax.Repository
+	calculator *tax.PricingService
+}
+
+func NewService(products product.Repository, stores store.Repository, promotions promotion.Repository, taxes tax.Repository) *Service {
+	return &Service{
+		products:   products,
+		stores:     stores,
+		promotions: promotions,
+		taxes:      taxes,
+		calculator: tax.NewPricingService(),
+	}
+}
+
+// Quote is the price of a product in a store. Tax is omitted when the store
+// has no structured address or no tax rates are set up for its jurisdiction.
+type Quote struct {
+	ProductID string    `json:"product_id"`
+	StoreID   string    `json:"store_id"`
+	At        time.Time `json:"at"`
+	*promotion.Quote
+	Tax *tax.Breakdown `json:"tax,omitempty"`
+}
+
+// QuotePrice prices quantity units of a product in a store at time at, using
+// the product's catalog price as the base and applying every promotion that
+// is active and in scope, then the tax of the store's jurisdiction. Only
+// products in the store's assortment can be priced.
+func (s *Service) QuotePrice(ctx context.Context, storeID, productID string, quantity int, at time.Time) (*Quote, error) {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !st.HasProduct(p.ID) {
+		return nil, store.ErrProductNotFound
+	}
+
+	active, err := s.promotions.ListActive(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	applicable := make([]*promotion.Promotion, 0, len(active))
+	for _, promo := range active {
+		if promo.ActiveAt(at) && promo.AppliesTo(p.ID, p.Category, st.ID) {
+			applicable = append(applicable, promo)
+		}
+	}
+
+	discounted, err := promotion.Price(p.Price, quantity, applicable)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown, err := s.taxBreakdown(ctx, st, p, discounted.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Quote{
+		ProductID: productID,
+		StoreID:   storeID,
+		At:        at,
+		Quote:     discounted,
+		Tax:       breakdown,
+	}, nil
+}
+
+func (s *Service) taxBreakdown(ctx context.Context, st *store.Store, p *product.Product, amount float64) (*tax.Breakdown, error) {
+	if st.PostalAddress == nil {
+		return nil, nil
+	}
+
+	table, err := s.taxes.FindForJurisdiction(ctx, st.PostalAddress.Country, st.PostalAddress.Region)
+	if errors.Is(err, tax.ErrRateTableNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.calculator.Breakdown(amount, p.EffectiveTaxClass(), table)
+}
@@ -0,0 +1,289 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/product/producttest"
+	"github.com/stasshander/ddd/internal/domain/promotion"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/domain/tax"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fixedStores serves a fixed set of stores
+type fixedStores struct {
+	store.Repository
+	stores []*store.Store
+}
+
+func (f *fixedStores) GetByID(ctx context.Context, id string) (*store.Store, error) {
+	for _, st := range f.stores {
+		if st.ID.Hex() == id {
+			return st, nil
+		}
+	}
+	return nil, store.ErrStoreNotFound
+}
+
+// listedPromotions returns every promotion as active, leaving the checks of
+// the validity window and scope to the service
+type listedPromotions struct {
+	promotion.Repository
+	promotions []*promotion.Promotion
+}
+
+func (l *listedPromotions) ListActive(ctx context.Context, at time.Time) ([]*promotion.Promotion, error) {
+	return l.promotions, nil
+}
+
+// jurisdictionTaxes serves rate tables by country
+type jurisdictionTaxes struct {
+	tax.Repository
+	tables []*tax.RateTable
+}
+
+func (j *jurisdictionTaxes) FindForJurisdiction(ctx context.Context, country, region string) (*tax.RateTable, error) {
+	for _, table := range j.tables {
+		if table.Country == country {
+			return table, nil
+		}
+	}
+	return nil, tax.ErrRateTableNotFound
+}
+
+type pricingFixture struct {
+	service    *Service
+	products   *producttest.Repository
+	promotions *listedPromotions
+	taxes      *jurisdictionTaxes
+	tea        *product.Product
+	main       *store.Store
+	other      *store.Store
+}
+
+func newPricingFixture(t *testing.T) *pricingFixture {
+	f := &pricingFixture{
+		products:   producttest.NewRepository(),
+		promotions: &listedPromotions{},
+		taxes:      &jurisdictionTaxes{},
+	}
+
+	var err error
+	f.tea, err = product.NewProduct("Tea", "Green tea", 10)
+	assert.NoError(t, err)
+	assert.NoError(t, f.tea.UpdateCategory("drinks"))
+	assert.NoError(t, f.products.Create(context.Background(), f.tea))
+
+	f.main, err = store.NewStore("Main Street", "1 Main Street")
+	assert.NoError(t, err)
+	assert.NoError(t, f.main.AddProduct(f.tea.ID))
+	f.other, err = store.NewStore("High Street", "2 High Street")
+	assert.NoError(t, err)
+
+	f.service = NewService(f.products, &fixedStores{stores: []*store.Store{f.main, f.other}}, f.promotions, f.taxes)
+	return f
+}
+
+// promote adds a promotion to the fixture, created after every earlier one
+func (f *pricingFixture) promote(t *testing.T, name string, rule promotion.Rule, scope promotion.Scope, priority int, stackable bool) *promotion.Promotion {
+	p, err := promotion.NewPromotion(name, rule, scope, time.Now().Add(-time.Hour), nil, priority, stackable)
+	assert.NoError(t, err)
+	p.CreatedAt = time.Now().Add(time.Duration(len(f.promotions.promotions)) * time.Second)
+	f.promotions.promotions = append(f.promotions.promotions, p)
+	return p
+}
+
+func TestQuotePricePromotions(t *testing.T) {
+	percent := func(p float64) promotion.Rule {
+		return promotion.Rule{Type: promotion.TypePercentage, Percent: p}
+	}
+	amount := func(a float64) promotion.Rule {
+		return promotion.Rule{Type: promotion.TypeFixedAmount, Amount: a}
+	}
+
+	testCases := []struct {
+		name        string
+		setup       func(t *testing.T, f *pricingFixture)
+		quantity    int
+		wantTotal   float64
+		wantApplied []string
+	}{
+		{
+			name:        "no promotions",
+			setup:       func(t *testing.T, f *pricingFixture) {},
+			quantity:    2,
+			wantTotal:   20,
+			wantApplied: []string{},
+		},
+		{
+			name: "highest priority wins when not stackable",
+			setup: func(t *testing.T, f *pricingFixture) {
+				f.promote(t, "Ten off", percent(10), promotion.Scope{}, 1, true)
+				f.promote(t, "Half off", percent(50), promotion.Scope{}, 5, false)
+			},
+			quantity:    2,
+			wantTotal:   10,
+			wantApplied: []string{"Half off"},
+		},
+		{
+			name: "stackable promotions apply in priority order",
+			setup: func(t *testing.T, f *pricingFixture) {
+				f.promote(t, "One off", amount(1), promotion.Scope{}, 1, true)
+				f.promote(t, "Ten percent", percent(10), promotion.Scope{}, 5, true)
+				f.promote(t, "Exclusive", percent(50), promotion.Scope{}, 3, false)
+			},
+			quantity:    2,
+			wantTotal:   16,
+			wantApplied: []string{"Ten percent", "One off"},
+		},
+		{
+			name: "equal priority breaks ties by age",
+			setup: func(t *testing.T, f *pricingFixture) {
+				f.promote(t, "Older", percent(20), promotion.Scope{}, 1, false)
+				f.promote(t, "Newer", percent(50), promotion.Scope{}, 1, false)
+			},
+			quantity:    1,
+			wantTotal:   8,
+			wantApplied: []string{"Older"},
+		},
+		{
+			name: "other store and category are out of scope",
+			setup: func(t *testing.T, f *pricingFixture) {
+				f.promote(t, "Other store", percent(50), promotion.Scope{StoreIDs: []primitive.ObjectID{f.other.ID}}, 9, false)
+				f.promote(t, "Snacks", percent(50), promotion.Scope{Categories: []string{"snacks"}}, 9, false)
+				f.promote(t, "Drinks here", percent(10), promotion.Scope{
+					StoreIDs:   []primitive.ObjectID{f.main.ID},
+					Categories: []string{"drinks"},
+				}, 1, false)
+			},
+			quantity:    1,
+			wantTotal:   9,
+			wantApplied: []string{"Drinks here"},
+		},
+		{
+			name: "expired promotion is ignored",
+			setup: func(t *testing.T, f *pricingFixture) {
+				expired := f.promote(t, "Expired", percent(50), promotion.Scope{}, 9, false)
+				ended := time.Now().Add(-time.Minute)
+				expired.EndsAt = &ended
+				f.promote(t, "Current", percent(10), promotion.Scope{}, 1, false)
+			},
+			quantity:    1,
+			wantTotal:   9,
+			wantApplied: []string{"Current"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPricingFixture(t)
+			tc.setup(t, f)
+
+			quote, err := f.service.QuotePrice(context.Background(), f.main.ID.Hex(), f.tea.ID.Hex(), tc.quantity, time.Now())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTotal, quote.Total)
+			applied := make([]string, 0, len(quote.Applied))
+			for _, a := range quote.Applied {
+				applied = append(applied, a.Name)
+			}
+			assert.Equal(t, tc.wantApplied, applied)
+			assert.Nil(t, quote.Tax)
+		})
+	}
+}
+
+func TestQuotePriceRequiresAssortment(t *testing.T) {
+	f := newPricingFixture(t)
+	ctx := context.Background()
+
+	_, err := f.service.QuotePrice(ctx, f.other.ID.Hex(), f.tea.ID.Hex(), 1, time.Now())
+	assert.ErrorIs(t, err, store.ErrProductNotFound)
+
+	_, err = f.service.QuotePrice(ctx, f.main.ID.Hex(), primitive.NewObjectID().Hex(), 1, time.Now())
+	assert.ErrorIs(t, err, product.ErrProductNotFound)
+
+	_, err = f.service.QuotePrice(ctx, primitive.NewObjectID().Hex(), f.tea.ID.Hex(), 1, time.Now())
+	assert.ErrorIs(t, err, store.ErrStoreNotFound)
+}
+
+func TestQuotePriceTax(t *testing.T) {
+	testCases := []struct {
+		name             string
+		rates            map[string]float64
+		pricesIncludeTax bool
+		taxClass         string
+		wantTax          *tax.Breakdown
+		wantErr          error
+	}{
+		{
+			name:             "gross prices",
+			rates:            map[string]float64{"standard": 19, "reduced": 7},
+			pricesIncludeTax: true,
+			taxClass:         "reduced",
+			wantTax:          &tax.Breakdown{Net: 9.35, Tax: 0.65, Gross: 10, Rate: 7, TaxClass: product.TaxClassReduced, Country: "DE"},
+		},
+		{
+			name:     "net prices",
+			rates:    map[string]float64{"standard": 19},
+			taxClass: "standard",
+			wantTax:  &tax.Breakdown{Net: 10, Tax: 1.9, Gross: 11.9, Rate: 19, TaxClass: product.TaxClassStandard, Country: "DE"},
+		},
+		{
+			name:     "zero rated without a rate",
+			rates:    map[string]float64{"standard": 19},
+			taxClass: "zero",
+			wantTax:  &tax.Breakdown{Net: 10, Tax: 0, Gross: 10, Rate: 0, TaxClass: product.TaxClassZero, Country: "DE"},
+		},
+		{
+			name:     "undefined tax class",
+			rates:    map[string]float64{"standard": 19},
+			taxClass: "reduced",
+			wantErr:  tax.ErrRateNotDefined,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newPricingFixture(t)
+			assert.NoError(t, f.tea.UpdateTaxClass(tc.taxClass))
+
+			address, err := store.NewAddress([]string{"1 Main Street"}, "Berlin", "", "10115", "DE")
+			assert.NoError(t, err)
+			assert.NoError(t, f.main.UpdatePostalAddress(address))
+			table, err := tax.NewRateTable("DE", "", tc.rates, tc.pricesIncludeTax)
+			assert.NoError(t, err)
+			f.taxes.tables = []*tax.RateTable{table}
+
+			// Half off two units of 10 leaves 10 to be taxed
+			f.promote(t, "Half off", promotion.Rule{Type: promotion.TypePercentage, Percent: 50}, promotion.Scope{}, 1, false)
+
+			quote, err := f.service.QuotePrice(context.Background(), f.main.ID.Hex(), f.tea.ID.Hex(), 2, time.Now())
+			assert.ErrorIs(t, err, tc.wantErr)
+			if tc.wantErr != nil {
+				return
+			}
+			assert.Equal(t, 20.0, quote.Subtotal)
+			assert.Equal(t, 10.0, quote.Total)
+			assert.Equal(t, tc.wantTax, quote.Tax)
+		})
+	}
+}
+
+func TestQuotePriceWithoutRateTable(t *testing.T) {
+	f := newPricingFixture(t)
+	address, err := store.NewAddress([]string{"1 Main Street"}, "Vienna", "", "1010", "AT")
+	assert.NoError(t, err)
+	assert.NoError(t, f.main.UpdatePostalAddress(address))
+	table, err := tax.NewRateTable("DE", "", map[string]float64{"standard": 19}, true)
+	assert.NoError(t, err)
+	f.taxes.tables = []*tax.RateTable{table}
+
+	quote, err := f.service.QuotePrice(context.Background(), f.main.ID.Hex(), f.tea.ID.Hex(), 1, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, quote.Total)
+	assert.Nil(t, quote.Tax)
+}
@@ -0,0 +1,376 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BulkOperation is one write of a bulk request. A create needs a name,
+// description and price, and may set a category and tax class; an update
+// needs the ID and at least one of a new price, description, category or tax
+// class; a delete needs the ID only. A create may also fix the ID of the new
+// product in NewID; repeating such a create fails with ErrProductExists.
+type BulkOperation struct {
+	Action      string
+	ID          string
+	NewID       primitive.ObjectID
+	Name        string
+	Description string
+	Price       *float64
+	Category    string
+	TaxClass    string
+}
+
+// BulkResult is the outcome of one bulk operation. Product is the created or
+// updated product and is nil for deletes and failed operations.
+type BulkResult struct {
+	Action  product.BulkAction
+	ID      string
+	Product *product.Product
+	Err     error
+}
+
+// Bulk validates and applies many product writes at once. Each operation
+// gets a result at its index. Normally valid operations are applied even if
+// others fail; when atomic is set, any failure leaves every operation
+// unapplied, reporting ErrBulkAborted for the operations that were fine.
+func (s *Service) Bulk(ctx context.Context, operations []BulkOperation, atomic bool) ([]*BulkResult, error) {
+	var (
+		results   []*BulkResult
+		writes    []*product.BulkWrite
+		positions []int
+		errs      []error
+	)
+
+	// The bundles containing deleted products are looked up in the unit of
+	// work that deletes them, like DeleteProduct does. A failed atomic
+	// request rolls the unit of work back.
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		var err error
+		results, writes, positions, err = s.prepareBulk(ctx, operations)
+		if err != nil || (atomic && len(writes) < len(operations)) {
+			return err
+		}
+
+		if atomic {
+			errs, err = s.repo.BulkWriteAtomic(ctx, writes)
+			if err == nil && slices.ContainsFunc(errs, isFailed) {
+				return errBulkFailed
+			}
+			return err
+		}
+
+		errs = make([]error, len(writes))
+		return s.writeBulkWhere(ctx, writes, errs, isDelete)
+	})
+	if errors.Is(err, product.ErrEmptyBulk) || errors.Is(err, product.ErrBulkTooLarge) {
+		return nil, err
+	}
+	if err != nil && !errors.Is(err, errBulkFailed) {
+		metrics.ProductOperationsTotal.WithLabelValues("bulk", "repository_error").Inc()
+		return nil, err
+	}
+
+	if atomic && len(writes) < len(operations) {
+		for _, i := range positions {
+			results[i].Err = product.ErrBulkAborted
+			results[i].Product = nil
+		}
+		recordBulkResults(results)
+		return results, nil
+	}
+
+	// Creates and updates are sent in one unordered batch outside the unit of
+	// work, so that one failing write does not undo the others
+	if !atomic {
+		err := s.writeBulkWhere(ctx, writes, errs, func(w *product.BulkWrite) bool { return !isDelete(w) })
+		if err != nil {
+			metrics.ProductOperationsTotal.WithLabelValues("bulk", "repository_error").Inc()
+			return nil, err
+		}
+	}
+
+	for j, i := range positions {
+		if errs[j] != nil {
+			results[i].Err = errs[j]
+			results[i].Product = nil
+		}
+	}
+	recordBulkResults(results)
+
+	s.notifyBulk(ctx, writes, errs)
+
+	return results, nil
+}
+
+// errBulkFailed rolls back the unit of work of an atomic bulk request in which
+// a write failed
+var errBulkFailed = errors.New("bulk write failed")
+
+func isFailed(err error) bool {
+	return err != nil
+}
+
+func isDelete(w *product.BulkWrite) bool {
+	return w.Action == product.BulkDelete
+}
+
+// writeBulkWhere sends the writes that match in one unordered batch and
+// records their errors at their positions in errs
+func (s *Service) writeBulkWhere(ctx context.Context, writes []*product.BulkWrite, errs []error, match func(*product.BulkWrite) bool) error {
+	matched := make([]*product.BulkWrite, 0, len(writes))
+	at := make([]int, 0, len(writes))
+	for i, w := range writes {
+		if match(w) {
+			matched = append(matched, w)
+			at = append(at, i)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	matchedErrs, err := s.repo.BulkWrite(ctx, matched)
+	if err != nil {
+		return err
+	}
+	for j, i := range at {
+		errs[i] = matchedErrs[j]
+	}
+	return nil
+}
+
+// ValidateBulk checks operations exactly as Bulk would, without writing
+// anything. Operations that would be applied get a nil Err.
+func (s *Service) ValidateBulk(ctx context.Context, operations []BulkOperation) ([]*BulkResult, error) {
+	results, _, _, err := s.prepareBulk(ctx, operations)
+	return results, err
+}
+
+// prepareBulk validates operations into writes. positions holds the index of
+// the operation each write came from.
+func (s *Service) prepareBulk(ctx context.Context, operations []BulkOperation) ([]*BulkResult, []*product.BulkWrite, []int, error) {
+	if len(operations) == 0 {
+		return nil, nil, nil, product.ErrEmptyBulk
+	}
+	if len(operations) > product.MaxBulkSize {
+		return nil, nil, nil, product.ErrBulkTooLarge
+	}
+
+	existing, err := s.loadBulkTargets(ctx, operations)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bundled, err := s.loadBundledTargets(ctx, operations)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	results := make([]*BulkResult, len(operations))
+	writes := make([]*product.BulkWrite, 0, len(operations))
+	positions := make([]int, 0, len(operations))
+	targeted := make(map[primitive.ObjectID]bool)
+	for i, op := range operations {
+		write, err := prepareBulkWrite(op, existing, bundled, targeted)
+		results[i] = &BulkResult{Action: product.BulkAction(op.Action), ID: op.ID, Err: err}
+		if err != nil {
+			continue
+		}
+		results[i].ID = write.ProductID.Hex()
+		results[i].Product = write.Product
+		writes = append(writes, write)
+		positions = append(positions, i)
+	}
+
+	return results, writes, positions, nil
+}
+
+// loadBulkTargets fetches the products targeted by updates and deletes,
+// keyed by ID. Malformed IDs are skipped and reported per operation later.
+func (s *Service) loadBulkTargets(ctx context.Context, operations []BulkOperation) (map[primitive.ObjectID]*product.Product, error) {
+	ids := make([]string, 0, len(operations))
+	for _, op := range operations {
+		if op.Action == string(product.BulkCreate) {
+			continue
+		}
+		if _, err := primitive.ObjectIDFromHex(op.ID); err == nil {
+			ids = append(ids, op.ID)
+		}
+	}
+
+	existing := make(map[primitive.ObjectID]*product.Product, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	found, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range found {
+		existing[p.ID] = p
+	}
+	return existing, nil
+}
+
+// loadBundledTargets returns the products targeted by deletes that are a
+// component of some bundle. Like DeleteProduct, a bulk request cannot delete
+// them while a bundle still contains them; Bulk runs this check in the unit
+// of work that deletes them.
+func (s *Service) loadBundledTargets(ctx context.Context, operations []BulkOperation) (map[primitive.ObjectID]bool, error) {
+	ids := make([]primitive.ObjectID, 0, len(operations))
+	for _, op := range operations {
+		if op.Action != string(product.BulkDelete) {
+			continue
+		}
+		if id, err := primitive.ObjectIDFromHex(op.ID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	bundled := make(map[primitive.ObjectID]bool)
+	if len(ids) == 0 {
+		return bundled, nil
+	}
+
+	bundles, err := s.repo.ListBundlesContaining(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bundles {
+		for _, id := range ids {
+			if b.Contains(id) {
+				bundled[id] = true
+			}
+		}
+	}
+	return bundled, nil
+}
+
+// prepareBulkWrite validates an operation through the product aggregate and
+// turns it into a write. bundled holds the products that are bundle
+// components and cannot be deleted; targeted records the products already
+// written by earlier operations of the request.
+func prepareBulkWrite(op BulkOperation, existing map[primitive.ObjectID]*product.Product, bundled, targeted map[primitive.ObjectID]bool) (*product.BulkWrite, error) {
+	action, err := product.ParseBulkAction(op.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	if action == product.BulkCreate {
+		price := 0.0
+		if op.Price != nil {
+			price = *op.Price
+		}
+		p, err := product.NewProduct(op.Name, op.Description, price)
+		if err != nil {
+			return nil, err
+		}
+		if err := classify(p, op); err != nil {
+			return nil, err
+		}
+		if !op.NewID.IsZero() {
+			p.ID = op.NewID
+		}
+		return &product.BulkWrite{Action: action, ProductID: p.ID, Product: p}, nil
+	}
+
+	id, err := primitive.ObjectIDFromHex(op.ID)
+	if err != nil {
+		return nil, err
+	}
+	current, ok := existing[id]
+	if !ok {
+		return nil, product.ErrProductNotFound
+	}
+	if targeted[id] {
+		return nil, product.ErrDuplicateTarget
+	}
+	targeted[id] = true
+
+	if action == product.BulkDelete {
+		if bundled[id] {
+			return nil, product.ErrProductInBundle
+		}
+		return &product.BulkWrite{Action: action, ProductID: id}, nil
+	}
+
+	if op.Price == nil && op.Description == "" && op.Category == "" && op.TaxClass == "" {
+		return nil, product.ErrEmptyUpdate
+	}
+	updated := *current
+	if op.Price != nil {
+		if err := updated.UpdatePrice(*op.Price); err != nil {
+			return nil, err
+		}
+	}
+	if op.Description != "" {
+		if err := updated.UpdateDescription(op.Description); err != nil {
+			return nil, err
+		}
+	}
+	if err := classify(&updated, op); err != nil {
+		return nil, err
+	}
+	return &product.BulkWrite{Action: action, ProductID: id, Product: &updated}, nil
+}
+
+// classify applies the category and tax class an operation sets, if any
+func classify(p *product.Product, op BulkOperation) error {
+	if op.Category != "" {
+		if err := p.UpdateCategory(op.Category); err != nil {
+			return err
+		}
+	}
+	if op.TaxClass != "" {
+		if err := p.UpdateTaxClass(op.TaxClass); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyBulk tells listeners about the stored writes and reprices the bundles
+// containing updated products in one pass. Repricing failures are logged, as
+// the writes themselves have been stored.
+func (s *Service) notifyBulk(ctx context.Context, writes []*product.BulkWrite, errs []error) {
+	updated := make([]primitive.ObjectID, 0, len(writes))
+	for j, w := range writes {
+		if errs[j] != nil {
+			continue
+		}
+
+		switch w.Action {
+		case product.BulkCreate:
+			s.notify(ctx, product.ChangeCreated, w.ProductID.Hex(), w.Product)
+		case product.BulkUpdate:
+			s.notify(ctx, product.ChangeUpdated, w.ProductID.Hex(), w.Product)
+			updated = append(updated, w.ProductID)
+		case product.BulkDelete:
+			s.notify(ctx, product.ChangeDeleted, w.ProductID.Hex(), nil)
+		}
+	}
+
+	if len(updated) > 0 {
+		s.refreshBundles(ctx, updated...)
+	}
+}
+
+func recordBulkResults(results []*BulkResult) {
+	for _, r := range results {
+		action := string(r.Action)
+		if _, err := product.ParseBulkAction(action); err != nil {
+			action = "invalid"
+		}
+		status := "success"
+		if r.Err != nil {
+			status = "error"
+		}
+		metrics.ProductOperationsTotal.WithLabelValues("bulk_"+action, status).Inc()
+	}
+}
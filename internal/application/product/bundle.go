@@ -0,0 +1,302 @@
+package product
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	appregion "github.com/stasshander/ddd/internal/application/region"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateBundle creates a bundle of existing products. A nil price derives the
+// bundle price from its components.
+func (s *Service) CreateBundle(ctx context.Context, name, description string, components []product.BundleComponent, price *float64) (*product.Product, error) {
+	start := time.Now()
+
+	catalog, err := s.loadCatalog(ctx, components)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("create_bundle", "validation_error").Inc()
+		return nil, err
+	}
+
+	p, err := product.NewBundle(name, description, components, price, catalog)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("create_bundle", "validation_error").Inc()
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, p); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("create_bundle", "repository_error").Inc()
+		return nil, err
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues("create_bundle", "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues("create_bundle").Observe(duration)
+
+	s.notify(ctx, product.ChangeCreated, p.ID.Hex(), p)
+
+	return p, nil
+}
+
+func (s *Service) UpdateBundleComponents(ctx context.Context, id string, components []product.BundleComponent) error {
+	start := time.Now()
+
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "not_found").Inc()
+		return err
+	}
+
+	catalog, err := s.loadCatalog(ctx, components)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "validation_error").Inc()
+		return err
+	}
+
+	if err := p.UpdateComponents(components, catalog); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "validation_error").Inc()
+		return err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "repository_error").Inc()
+		return err
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues("update_bundle").Observe(duration)
+
+	s.notify(ctx, product.ChangeUpdated, id, p)
+	s.refreshBundles(ctx, p.ID)
+
+	return nil
+}
+
+// SetBundlePrice overrides the price of a bundle, or reverts to the price
+// derived from its components when price is nil.
+func (s *Service) SetBundlePrice(ctx context.Context, id string, price *float64) error {
+	if price != nil {
+		p, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !p.IsBundle() {
+			return product.ErrNotABundle
+		}
+		return s.UpdateProductPrice(ctx, id, *price)
+	}
+
+	start := time.Now()
+
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "not_found").Inc()
+		return err
+	}
+
+	if !p.IsBundle() {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "validation_error").Inc()
+		return product.ErrNotABundle
+	}
+
+	catalog, err := s.loadCatalog(ctx, p.Bundle.Components)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "validation_error").Inc()
+		return err
+	}
+
+	if err := p.UseDerivedPrice(catalog); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "validation_error").Inc()
+		return err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "repository_error").Inc()
+		return err
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues("update_bundle", "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues("update_bundle").Observe(duration)
+
+	s.notify(ctx, product.ChangeUpdated, id, p)
+	s.refreshBundles(ctx, p.ID)
+
+	return nil
+}
+
+// refreshBundles recalculates the derived price of every bundle containing
+// one of the changed products, and of the bundles containing those in turn,
+// one level of nesting at a time. It runs after the change itself has been
+// stored, so failures are logged rather than returned.
+func (s *Service) refreshBundles(ctx context.Context, changed ...primitive.ObjectID) {
+	for len(changed) > 0 {
+		bundles, err := s.repo.ListBundlesContaining(ctx, changed)
+		if err != nil {
+			log.Printf("Failed to find bundles to reprice: %v", err)
+			return
+		}
+
+		catalog, err := s.loadComponents(ctx, bundles)
+		if err != nil {
+			log.Printf("Failed to load bundle components for repricing: %v", err)
+			return
+		}
+
+		repriced := make([]primitive.ObjectID, 0, len(bundles))
+		for _, p := range bundles {
+			if p.Bundle.PriceOverridden {
+				continue
+			}
+			if err := p.RecalculatePrice(catalog); err != nil {
+				log.Printf("Failed to reprice bundle %s: %v", p.ID.Hex(), err)
+				continue
+			}
+			if err := s.repo.Update(ctx, p); err != nil {
+				log.Printf("Failed to reprice bundle %s: %v", p.ID.Hex(), err)
+				continue
+			}
+
+			s.notify(ctx, product.ChangeUpdated, p.ID.Hex(), p)
+			repriced = append(repriced, p.ID)
+		}
+		changed = repriced
+	}
+}
+
+// loadComponents fetches the direct components of the given bundles with a
+// single lookup
+func (s *Service) loadComponents(ctx context.Context, bundles []*product.Product) (map[primitive.ObjectID]*product.Product, error) {
+	ids := make([]string, 0)
+	seen := make(map[primitive.ObjectID]bool)
+	for _, b := range bundles {
+		for _, c := range b.Bundle.Components {
+			if !seen[c.ProductID] {
+				seen[c.ProductID] = true
+				ids = append(ids, c.ProductID.Hex())
+			}
+		}
+	}
+
+	components, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := make(map[primitive.ObjectID]*product.Product, len(components))
+	for _, p := range components {
+		catalog[p.ID] = p
+	}
+	return catalog, nil
+}
+
+// loadCatalog fetches every product reachable from components, following
+// nested bundles.
+func (s *Service) loadCatalog(ctx context.Context, components []product.BundleComponent) (map[primitive.ObjectID]*product.Product, error) {
+	catalog := make(map[primitive.ObjectID]*product.Product)
+	pending := append([]product.BundleComponent(nil), components...)
+	for len(pending) > 0 {
+		c := pending[0]
+		pending = pending[1:]
+		if _, ok := catalog[c.ProductID]; ok {
+			continue
+		}
+
+		p, err := s.repo.GetByID(ctx, c.ProductID.Hex())
+		if err != nil {
+			if err == product.ErrProductNotFound {
+				return nil, product.ErrComponentNotFound
+			}
+			return nil, err
+		}
+
+		catalog[c.ProductID] = p
+		if p.IsBundle() {
+			pending = append(pending, p.Bundle.Components...)
+		}
+	}
+	return catalog, nil
+}
+
+// Availability reports whether a store carries everything a bundle is made of
+type Availability struct {
+	ProductID string   `json:"product_id"`
+	StoreID   string   `json:"store_id"`
+	Available bool     `json:"available"`
+	Missing   []string `json:"missing"`
+}
+
+// AvailabilityService answers which bundles a store can sell, based on the
+// component products the store carries, and which stores carry a product.
+type AvailabilityService struct {
+	products *Service
+	stores   store.Repository
+	regions  *appregion.Service
+}
+
+func NewAvailabilityService(products *Service, stores store.Repository, regions *appregion.Service) *AvailabilityService {
+	return &AvailabilityService{
+		products: products,
+		stores:   stores,
+		regions:  regions,
+	}
+}
+
+// BundleAvailability checks a bundle against a store's products. Nested
+// bundles are expanded, so the store needs to carry the plain products only.
+// A bundle with a component that no longer exists is never available.
+func (s *AvailabilityService) BundleAvailability(ctx context.Context, bundleID, storeID string) (*Availability, error) {
+	p, err := s.products.GetProduct(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsBundle() {
+		return nil, product.ErrNotABundle
+	}
+
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	carried := make(map[primitive.ObjectID]bool, len(st.Products))
+	for _, id := range st.Products {
+		carried[id] = true
+	}
+
+	result := &Availability{
+		ProductID: bundleID,
+		StoreID:   storeID,
+		Missing:   make([]string, 0),
+	}
+
+	catalog, err := s.products.loadCatalog(ctx, p.Bundle.Components)
+	if err == product.ErrComponentNotFound {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := p.ExpandComponents(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	for id := range leaves {
+		if !carried[id] {
+			result.Missing = append(result.Missing, id.Hex())
+		}
+	}
+	sort.Strings(result.Missing)
+	result.Available = len(result.Missing) == 0
+
+	return result, nil
+}
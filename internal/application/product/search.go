@@ -0,0 +1,92 @@
+package product
+
+import (
+	"context"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+)
+
+type SearchService struct {
+	searcher product.SearchRepository
+}
+
+func NewSearchService(searcher product.SearchRepository) *SearchService {
+	return &SearchService{
+		searcher: searcher,
+	}
+}
+
+// SearchProducts runs a full-text query and returns one page of hits ordered by
+// relevance, with matched terms highlighted in the name and description.
+func (s *SearchService) SearchProducts(ctx context.Context, query string, page, limit int) ([]*product.SearchHit, int, error) {
+	start := time.Now()
+
+	terms := product.SearchTerms(query)
+	if len(terms) == 0 {
+		metrics.ProductOperationsTotal.WithLabelValues("search", "validation_error").Inc()
+		return nil, 0, product.ErrEmptySearchQuery
+	}
+
+	hits, total, err := s.searcher.Search(ctx, query, page, limit)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("search", "repository_error").Inc()
+		return nil, 0, err
+	}
+
+	for _, hit := range hits {
+		if hit.Highlights == nil {
+			hit.Highlights = highlights(hit, terms)
+		}
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues("search", "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues("search").Observe(duration)
+
+	return hits, total, nil
+}
+
+type FacetedSearchService struct {
+	searcher product.FacetedSearchRepository
+}
+
+func NewFacetedSearchService(searcher product.FacetedSearchRepository) *FacetedSearchService {
+	return &FacetedSearchService{
+		searcher: searcher,
+	}
+}
+
+// Search runs a catalog query with prefix and typo tolerant matching and
+// returns one page of hits together with price band and category facets.
+func (s *FacetedSearchService) Search(ctx context.Context, opts product.SearchOptions) ([]*product.SearchHit, int, *product.SearchFacets, error) {
+	start := time.Now()
+
+	hits, total, facets, err := s.searcher.FacetedSearch(ctx, opts)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("faceted_search", "repository_error").Inc()
+		return nil, 0, nil, err
+	}
+
+	terms := product.SearchTerms(opts.Query)
+	for _, hit := range hits {
+		hit.Highlights = highlights(hit, terms)
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues("faceted_search", "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues("faceted_search").Observe(duration)
+
+	return hits, total, facets, nil
+}
+
+// highlights marks the words of a hit's name and description matched by the
+// query terms, or by the typo corrections the search made for them
+func highlights(hit *product.SearchHit, terms []string) map[string]string {
+	terms = append(terms[:len(terms):len(terms)], hit.MatchedTerms...)
+	return map[string]string{
+		"name":        product.Highlight(hit.Product.Name, terms),
+		"description": product.Highlight(hit.Product.Description, terms),
+	}
+}
@@ -0,0 +1,152 @@
+package product
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/product/producttest"
+	"github.com/stretchr/testify/assert"
+)
+
+// MemorySearchRepository is an in-process stand-in for the text index that
+// scores products by the number of query terms they contain.
+type MemorySearchRepository struct {
+	repo *producttest.Repository
+}
+
+func (m *MemorySearchRepository) Search(ctx context.Context, query string, page, limit int) ([]*product.SearchHit, int, error) {
+	terms := product.SearchTerms(query)
+
+	var hits []*product.SearchHit
+	for _, p := range m.repo.Products {
+		text := strings.ToLower(p.Name + " " + p.Description)
+		score := 0.0
+		for _, term := range terms {
+			score += float64(strings.Count(text, term))
+		}
+		if score > 0 {
+			hits = append(hits, &product.SearchHit{Product: p, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+
+	total := len(hits)
+	skip := (page - 1) * limit
+	if skip >= total {
+		return []*product.SearchHit{}, total, nil
+	}
+	end := skip + limit
+	if end > total {
+		end = total
+	}
+	return hits[skip:end], total, nil
+}
+
+func TestSearchProducts(t *testing.T) {
+	repo := producttest.NewRepository()
+	service := NewService(repo, directRunner{})
+	search := NewSearchService(&MemorySearchRepository{repo: repo})
+
+	_, err := service.CreateProduct(context.Background(), "Coffee Beans", "Dark roast coffee", 12.0)
+	assert.NoError(t, err)
+	_, err = service.CreateProduct(context.Background(), "Coffee Mug", "Ceramic mug", 8.0)
+	assert.NoError(t, err)
+	_, err = service.CreateProduct(context.Background(), "Green Tea", "Loose leaf tea", 6.0)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		query     string
+		page      int
+		limit     int
+		wantNames []string
+		wantTotal int
+		wantErr   error
+	}{
+		{
+			name:      "ranked by relevance",
+			query:     "coffee",
+			page:      1,
+			limit:     10,
+			wantNames: []string{"Coffee Beans", "Coffee Mug"},
+			wantTotal: 2,
+		},
+		{
+			name:      "second page",
+			query:     "coffee",
+			page:      2,
+			limit:     1,
+			wantNames: []string{"Coffee Mug"},
+			wantTotal: 2,
+		},
+		{
+			name:      "no matches",
+			query:     "chocolate",
+			page:      1,
+			limit:     10,
+			wantNames: []string{},
+			wantTotal: 0,
+		},
+		{
+			name:    "empty query",
+			query:   "  ",
+			page:    1,
+			limit:   10,
+			wantErr: product.ErrEmptySearchQuery,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits, total, err := search.SearchProducts(context.Background(), tc.query, tc.page, tc.limit)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTotal, total)
+			names := make([]string, 0, len(hits))
+			for _, hit := range hits {
+				names = append(names, hit.Product.Name)
+				assert.Contains(t, hit.Highlights["name"], "<em>")
+			}
+			assert.Equal(t, tc.wantNames, names)
+		})
+	}
+}
+
+// fuzzySearchRepository returns one hit matched through a typo correction
+type fuzzySearchRepository struct {
+	hit *product.SearchHit
+}
+
+func (f *fuzzySearchRepository) Search(ctx context.Context, query string, page, limit int) ([]*product.SearchHit, int, error) {
+	return []*product.SearchHit{f.hit}, 1, nil
+}
+
+func (f *fuzzySearchRepository) FacetedSearch(ctx context.Context, opts product.SearchOptions) ([]*product.SearchHit, int, *product.SearchFacets, error) {
+	return []*product.SearchHit{f.hit}, 1, &product.SearchFacets{}, nil
+}
+
+func TestSearchHighlightsTypoCorrections(t *testing.T) {
+	p, err := product.NewProduct("Coffee Grinder", "Burr grinder for espresso", 89)
+	assert.NoError(t, err)
+	want := map[string]string{
+		"name":        "<em>Coffee</em> <em>Grinder</em>",
+		"description": "Burr <em>grinder</em> for espresso",
+	}
+
+	repo := &fuzzySearchRepository{hit: &product.SearchHit{Product: p, MatchedTerms: []string{"grinder"}}}
+	hits, _, err := NewSearchService(repo).SearchProducts(context.Background(), "cof grindr", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, want, hits[0].Highlights)
+
+	repo.hit.Highlights = nil
+	hits, _, _, err = NewFacetedSearchService(repo).Search(context.Background(), product.SearchOptions{Query: "cof grindr", Fuzzy: true})
+	assert.NoError(t, err)
+	assert.Equal(t, want, hits[0].Highlights)
+}
@@ -2,19 +2,45 @@ package product
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/stasshander/ddd/internal/application/transaction"
 	"github.com/stasshander/ddd/internal/domain/product"
 	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Service struct {
-	repo product.Repository
+	repo      product.Repository
+	tx        transaction.Runner
+	listeners []product.ChangeListener
 }
 
-func NewService(repo product.Repository) *Service {
+// NewService creates a product service. Deletes are checked against the
+// bundles containing the product in the same unit of work, run by tx, that
+// deletes it.
+func NewService(repo product.Repository, tx transaction.Runner) *Service {
 	return &Service{
 		repo: repo,
+		tx:   tx,
+	}
+}
+
+// Subscribe registers a listener that is notified after every successful
+// product write. It must be called before the service starts handling requests.
+func (s *Service) Subscribe(listener product.ChangeListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *Service) notify(ctx context.Context, changeType product.ChangeType, id string, p *product.Product) {
+	change := product.Change{
+		Type:      changeType,
+		ProductID: id,
+		Product:   p,
+	}
+	for _, listener := range s.listeners {
+		listener.ProductChanged(ctx, change)
 	}
 }
 
@@ -36,6 +62,8 @@ func (s *Service) CreateProduct(ctx context.Context, name, description string, p
 	metrics.ProductOperationsTotal.WithLabelValues("create", "success").Inc()
 	metrics.ProductOperationDuration.WithLabelValues("create").Observe(duration)
 
+	s.notify(ctx, product.ChangeCreated, p.ID.Hex(), p)
+
 	return p, nil
 }
 
@@ -63,6 +91,62 @@ func (s *Service) GetProduct(ctx context.Context, id string) (*product.Product,
 	return p, nil
 }
 
+// MaxBatchSize is the most products GetProducts looks up at once
+const MaxBatchSize = 100
+
+// GetProducts looks up several products at once. Found products are returned
+// in the order their IDs were requested, and the IDs of products that do not
+// exist, malformed ones included, are returned as missing. Repeated IDs are
+// looked up once.
+func (s *Service) GetProducts(ctx context.Context, ids []string) ([]*product.Product, []string, error) {
+	start := time.Now()
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBatchSize {
+		metrics.ProductOperationsTotal.WithLabelValues("batch_get", "validation_error").Inc()
+		return nil, nil, product.ErrTooManyIDs
+	}
+
+	found, err := s.repo.GetByIDs(ctx, unique)
+
+	duration := time.Since(start).Seconds()
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	metrics.ProductOperationsTotal.WithLabelValues("batch_get", status).Inc()
+	metrics.ProductOperationDuration.WithLabelValues("batch_get").Observe(duration)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[string]*product.Product, len(found))
+	for _, p := range found {
+		byID[p.ID.Hex()] = p
+	}
+
+	products := make([]*product.Product, 0, len(found))
+	missing := make([]string, 0)
+	for _, id := range unique {
+		if p, ok := byID[id]; ok {
+			products = append(products, p)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	return products, missing, nil
+}
+
 func (s *Service) UpdateProductPrice(ctx context.Context, id string, newPrice float64) error {
 	start := time.Now()
 
@@ -86,6 +170,10 @@ func (s *Service) UpdateProductPrice(ctx context.Context, id string, newPrice fl
 	metrics.ProductOperationsTotal.WithLabelValues("update_price", "success").Inc()
 	metrics.ProductOperationDuration.WithLabelValues("update_price").Observe(duration)
 
+	s.notify(ctx, product.ChangeUpdated, id, p)
+
+	s.refreshBundles(ctx, p.ID)
+
 	return nil
 }
 
@@ -112,13 +200,89 @@ func (s *Service) UpdateProductDescription(ctx context.Context, id string, newDe
 	metrics.ProductOperationsTotal.WithLabelValues("update_description", "success").Inc()
 	metrics.ProductOperationDuration.WithLabelValues("update_description").Observe(duration)
 
+	s.notify(ctx, product.ChangeUpdated, id, p)
+
+	return nil
+}
+
+func (s *Service) UpdateProductCategory(ctx context.Context, id string, category string) error {
+	start := time.Now()
+
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_category", "not_found").Inc()
+		return err
+	}
+
+	if err := p.UpdateCategory(category); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_category", "validation_error").Inc()
+		return err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_category", "repository_error").Inc()
+		return err
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues("update_category", "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues("update_category").Observe(duration)
+
+	s.notify(ctx, product.ChangeUpdated, id, p)
+
 	return nil
 }
 
+func (s *Service) UpdateProductTaxClass(ctx context.Context, id string, taxClass string) error {
+	start := time.Now()
+
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_tax_class", "not_found").Inc()
+		return err
+	}
+
+	if err := p.UpdateTaxClass(taxClass); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_tax_class", "validation_error").Inc()
+		return err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues("update_tax_class", "repository_error").Inc()
+		return err
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues("update_tax_class", "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues("update_tax_class").Observe(duration)
+
+	s.notify(ctx, product.ChangeUpdated, id, p)
+
+	return nil
+}
+
+// DeleteProduct removes a product. Products that are a component of a bundle
+// cannot be deleted until they are taken out of the bundle.
 func (s *Service) DeleteProduct(ctx context.Context, id string) error {
 	start := time.Now()
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	err := s.tx.Run(ctx, func(ctx context.Context) error {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			bundles, err := s.repo.ListBundlesContaining(ctx, []primitive.ObjectID{objectID})
+			if err != nil {
+				return err
+			}
+			if len(bundles) > 0 {
+				return product.ErrProductInBundle
+			}
+		}
+		return s.repo.Delete(ctx, id)
+	})
+	if errors.Is(err, product.ErrProductInBundle) {
+		metrics.ProductOperationsTotal.WithLabelValues("delete", "validation_error").Inc()
+		return err
+	}
+	if err != nil {
 		metrics.ProductOperationsTotal.WithLabelValues("delete", "error").Inc()
 		return err
 	}
@@ -127,6 +291,8 @@ func (s *Service) DeleteProduct(ctx context.Context, id string) error {
 	metrics.ProductOperationsTotal.WithLabelValues("delete", "success").Inc()
 	metrics.ProductOperationDuration.WithLabelValues("delete").Observe(duration)
 
+	s.notify(ctx, product.ChangeDeleted, id, nil)
+
 	return nil
 }
 
@@ -150,3 +316,50 @@ func (s *Service) ListProducts(ctx context.Context) ([]*product.Product, error)
 
 	return products, nil
 }
+
+// UpsertTranslation adds or replaces a product's name and description in a locale
+func (s *Service) UpsertTranslation(ctx context.Context, id, locale, name, description string) error {
+	return s.updateLocalization(ctx, id, "upsert_translation", func(p *product.Product) error {
+		return p.SetTranslation(locale, name, description)
+	})
+}
+
+func (s *Service) RemoveTranslation(ctx context.Context, id, locale string) error {
+	return s.updateLocalization(ctx, id, "remove_translation", func(p *product.Product) error {
+		return p.RemoveTranslation(locale)
+	})
+}
+
+func (s *Service) SetDefaultLocale(ctx context.Context, id, locale string) error {
+	return s.updateLocalization(ctx, id, "set_default_locale", func(p *product.Product) error {
+		return p.SetDefaultLocale(locale)
+	})
+}
+
+func (s *Service) updateLocalization(ctx context.Context, id, operation string, change func(*product.Product) error) error {
+	start := time.Now()
+
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues(operation, "not_found").Inc()
+		return err
+	}
+
+	if err := change(p); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues(operation, "validation_error").Inc()
+		return err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		metrics.ProductOperationsTotal.WithLabelValues(operation, "repository_error").Inc()
+		return err
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.ProductOperationsTotal.WithLabelValues(operation, "success").Inc()
+	metrics.ProductOperationDuration.WithLabelValues(operation).Observe(duration)
+
+	s.notify(ctx, product.ChangeUpdated, id, p)
+
+	return nil
+}
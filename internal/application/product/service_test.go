@@ -5,53 +5,16 @@ import (
 	"testing"
 
 	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/product/producttest"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-type MockRepository struct {
-	products map[string]*product.Product
-}
-
-func NewMockRepository() *MockRepository {
-	return &MockRepository{
-		products: make(map[string]*product.Product),
-	}
-}
-
-func (m *MockRepository) Create(ctx context.Context, p *product.Product) error {
-	m.products[p.ID.Hex()] = p
-	return nil
-}
-
-func (m *MockRepository) GetByID(ctx context.Context, id string) (*product.Product, error) {
-	if p, ok := m.products[id]; ok {
-		return p, nil
-	}
-	return nil, product.ErrProductNotFound
-}
-
-func (m *MockRepository) Update(ctx context.Context, p *product.Product) error {
-	if _, ok := m.products[p.ID.Hex()]; !ok {
-		return product.ErrProductNotFound
-	}
-	m.products[p.ID.Hex()] = p
-	return nil
-}
-
-func (m *MockRepository) Delete(ctx context.Context, id string) error {
-	if _, ok := m.products[id]; !ok {
-		return product.ErrProductNotFound
-	}
-	delete(m.products, id)
-	return nil
-}
+// directRunner runs units of work without a transaction
+type directRunner struct{}
 
-func (m *MockRepository) List(ctx context.Context) ([]*product.Product, error) {
-	products := make([]*product.Product, 0, len(m.products))
-	for _, p := range m.products {
-		products = append(products, p)
-	}
-	return products, nil
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
 
 func TestCreateProduct(t *testing.T) {
@@ -94,8 +57,8 @@ func TestCreateProduct(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			repo := NewMockRepository()
-			service := NewService(repo)
+			repo := producttest.NewRepository()
+			service := NewService(repo, directRunner{})
 
 			p, err := service.CreateProduct(context.Background(), tc.productName, tc.desc, tc.price)
 			if tc.wantErr != nil {
@@ -138,8 +101,8 @@ func TestGetProduct(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			repo := NewMockRepository()
-			service := NewService(repo)
+			repo := producttest.NewRepository()
+			service := NewService(repo, directRunner{})
 
 			id := tc.setup(service)
 			p, err := service.GetProduct(context.Background(), id)
@@ -157,6 +120,30 @@ func TestGetProduct(t *testing.T) {
 	}
 }
 
+func TestGetProducts(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(producttest.NewRepository(), directRunner{})
+
+	first, _ := service.CreateProduct(ctx, "First", "First product", 10.0)
+	second, _ := service.CreateProduct(ctx, "Second", "Second product", 20.0)
+	missingID := primitive.NewObjectID().Hex()
+
+	products, missing, err := service.GetProducts(ctx, []string{first.ID.Hex(), missingID, second.ID.Hex(), "not-an-id", first.ID.Hex()})
+	assert.NoError(t, err)
+	if assert.Len(t, products, 2) {
+		assert.Equal(t, first.ID, products[0].ID)
+		assert.Equal(t, second.ID, products[1].ID)
+	}
+	assert.Equal(t, []string{missingID, "not-an-id"}, missing)
+
+	tooMany := make([]string, MaxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = primitive.NewObjectID().Hex()
+	}
+	_, _, err = service.GetProducts(ctx, tooMany)
+	assert.ErrorIs(t, err, product.ErrTooManyIDs)
+}
+
 func TestUpdateProductPrice(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -194,8 +181,8 @@ func TestUpdateProductPrice(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			repo := NewMockRepository()
-			service := NewService(repo)
+			repo := producttest.NewRepository()
+			service := NewService(repo, directRunner{})
 
 			id := tc.setup(service)
 			err := service.UpdateProductPrice(context.Background(), id, tc.price)
@@ -250,8 +237,8 @@ func TestUpdateProductDescription(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			repo := NewMockRepository()
-			service := NewService(repo)
+			repo := producttest.NewRepository()
+			service := NewService(repo, directRunner{})
 
 			id := tc.setup(service)
 			err := service.UpdateProductDescription(context.Background(), id, tc.description)
@@ -294,8 +281,8 @@ func TestDeleteProduct(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			repo := NewMockRepository()
-			service := NewService(repo)
+			repo := producttest.NewRepository()
+			service := NewService(repo, directRunner{})
 
 			id := tc.setup(service)
 			err := service.DeleteProduct(context.Background(), id)
@@ -347,8 +334,8 @@ func TestListProducts(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			repo := NewMockRepository()
-			service := NewService(repo)
+			repo := producttest.NewRepository()
+			service := NewService(repo, directRunner{})
 
 			expectedCount := tc.setup(service)
 			products, err := service.ListProducts(context.Background())
@@ -364,3 +351,352 @@ func TestListProducts(t *testing.T) {
 		})
 	}
 }
+
+type recordingListener struct {
+	changes []product.Change
+}
+
+func (l *recordingListener) ProductChanged(ctx context.Context, change product.Change) {
+	l.changes = append(l.changes, change)
+}
+
+func TestSubscribe(t *testing.T) {
+	repo := producttest.NewRepository()
+	service := NewService(repo, directRunner{})
+	listener := &recordingListener{}
+	service.Subscribe(listener)
+
+	p, err := service.CreateProduct(context.Background(), "Test Product", "Test Description", 10.0)
+	assert.NoError(t, err)
+	assert.NoError(t, service.UpdateProductCategory(context.Background(), p.ID.Hex(), "Beverages"))
+	assert.ErrorIs(t, service.UpdateProductPrice(context.Background(), p.ID.Hex(), -1), product.ErrInvalidPrice)
+	assert.NoError(t, service.DeleteProduct(context.Background(), p.ID.Hex()))
+
+	types := make([]product.ChangeType, 0, len(listener.changes))
+	for _, change := range listener.changes {
+		assert.Equal(t, p.ID.Hex(), change.ProductID)
+		types = append(types, change.Type)
+	}
+	assert.Equal(t, []product.ChangeType{product.ChangeCreated, product.ChangeUpdated, product.ChangeDeleted}, types)
+}
+
+func TestBundleRepricedWhenComponentChanges(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(producttest.NewRepository(), directRunner{})
+
+	soap, err := service.CreateProduct(ctx, "Soap", "Lavender soap", 4.5)
+	assert.NoError(t, err)
+
+	pair, err := service.CreateBundle(ctx, "Soap Pair", "Two soaps", []product.BundleComponent{
+		{ProductID: soap.ID, Quantity: 2},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 9.0, pair.Price)
+
+	box, err := service.CreateBundle(ctx, "Gift Box", "Soap pair in a box", []product.BundleComponent{
+		{ProductID: pair.ID, Quantity: 1},
+	}, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.UpdateProductPrice(ctx, soap.ID.Hex(), 5))
+
+	pair, _ = service.GetProduct(ctx, pair.ID.Hex())
+	box, _ = service.GetProduct(ctx, box.ID.Hex())
+	assert.Equal(t, 10.0, pair.Price)
+	assert.Equal(t, 10.0, box.Price)
+
+	err = service.UpdateBundleComponents(ctx, pair.ID.Hex(), []product.BundleComponent{
+		{ProductID: box.ID, Quantity: 1},
+	})
+	assert.ErrorIs(t, err, product.ErrCyclicBundle)
+
+	_, err = service.CreateBundle(ctx, "Broken", "Missing component", []product.BundleComponent{
+		{ProductID: primitive.NewObjectID(), Quantity: 1},
+	}, nil)
+	assert.ErrorIs(t, err, product.ErrComponentNotFound)
+}
+
+func TestDeleteBundleComponent(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(producttest.NewRepository(), directRunner{})
+
+	soap, err := service.CreateProduct(ctx, "Soap", "Lavender soap", 4.5)
+	assert.NoError(t, err)
+	pair, err := service.CreateBundle(ctx, "Soap Pair", "Two soaps", []product.BundleComponent{
+		{ProductID: soap.ID, Quantity: 2},
+	}, nil)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, service.DeleteProduct(ctx, soap.ID.Hex()), product.ErrProductInBundle)
+	_, err = service.GetProduct(ctx, soap.ID.Hex())
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.DeleteProduct(ctx, pair.ID.Hex()))
+	assert.NoError(t, service.DeleteProduct(ctx, soap.ID.Hex()))
+}
+
+// unitOfWork marks the contexts of the units of work run by markingRunner
+type unitOfWork struct{}
+
+type markingRunner struct{}
+
+func (markingRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, unitOfWork{}, true))
+}
+
+// unitRepository records which repository calls ran in a unit of work
+type unitRepository struct {
+	*producttest.Repository
+	calls map[string]bool
+}
+
+func (u *unitRepository) record(ctx context.Context, call string) {
+	u.calls[call] = ctx.Value(unitOfWork{}) != nil
+}
+
+func (u *unitRepository) ListBundlesContaining(ctx context.Context, ids []primitive.ObjectID) ([]*product.Product, error) {
+	u.record(ctx, "bundles")
+	return u.Repository.ListBundlesContaining(ctx, ids)
+}
+
+func (u *unitRepository) Delete(ctx context.Context, id string) error {
+	u.record(ctx, "delete")
+	return u.Repository.Delete(ctx, id)
+}
+
+func (u *unitRepository) BulkWrite(ctx context.Context, writes []*product.BulkWrite) ([]error, error) {
+	u.record(ctx, string(writes[0].Action))
+	return u.Repository.BulkWrite(ctx, writes)
+}
+
+func (u *unitRepository) BulkWriteAtomic(ctx context.Context, writes []*product.BulkWrite) ([]error, error) {
+	u.record(ctx, "atomic")
+	return u.Repository.BulkWriteAtomic(ctx, writes)
+}
+
+func TestDeleteChecksBundlesInUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	operations := func(id string) []BulkOperation {
+		price := 2.5
+		return []BulkOperation{
+			{Action: "delete", ID: id},
+			{Action: "create", Name: "Chai", Description: "Spiced tea", Price: &price},
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		delete    func(s *Service, id string) error
+		wantCalls map[string]bool
+	}{
+		{
+			name: "single delete",
+			delete: func(s *Service, id string) error {
+				return s.DeleteProduct(ctx, id)
+			},
+			wantCalls: map[string]bool{"bundles": true, "delete": true},
+		},
+		{
+			name: "bulk",
+			delete: func(s *Service, id string) error {
+				_, err := s.Bulk(ctx, operations(id), false)
+				return err
+			},
+			wantCalls: map[string]bool{"bundles": true, "delete": true, "create": false},
+		},
+		{
+			name: "atomic bulk",
+			delete: func(s *Service, id string) error {
+				_, err := s.Bulk(ctx, operations(id), true)
+				return err
+			},
+			wantCalls: map[string]bool{"bundles": true, "atomic": true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &unitRepository{Repository: producttest.NewRepository(), calls: make(map[string]bool)}
+			service := NewService(repo, markingRunner{})
+			soap, err := service.CreateProduct(ctx, "Soap", "Lavender soap", 4.5)
+			assert.NoError(t, err)
+
+			assert.NoError(t, tc.delete(service, soap.ID.Hex()))
+			assert.Equal(t, tc.wantCalls, repo.calls)
+			_, err = service.GetProduct(ctx, soap.ID.Hex())
+			assert.ErrorIs(t, err, product.ErrProductNotFound)
+		})
+	}
+}
+
+// vanishingRepository deletes the products it hands out, as if another
+// request removed them between validation and the write
+type vanishingRepository struct {
+	*producttest.Repository
+}
+
+func (v *vanishingRepository) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	found, err := v.Repository.GetByIDs(ctx, ids)
+	for _, p := range found {
+		delete(v.Products, p.ID.Hex())
+	}
+	return found, err
+}
+
+func TestBulk(t *testing.T) {
+	ctx := context.Background()
+	price := func(v float64) *float64 { return &v }
+
+	setup := func() (*Service, *product.Product, *product.Product) {
+		service := NewService(producttest.NewRepository(), directRunner{})
+		kept, _ := service.CreateProduct(ctx, "Kept", "Kept product", 10.0)
+		removed, _ := service.CreateProduct(ctx, "Removed", "Removed product", 20.0)
+		return service, kept, removed
+	}
+
+	t.Run("applies valid operations and reports the others", func(t *testing.T) {
+		service, kept, removed := setup()
+
+		results, err := service.Bulk(ctx, []BulkOperation{
+			{Action: "create", Name: "New", Description: "New product", Price: price(5.0)},
+			{Action: "update", ID: kept.ID.Hex(), Price: price(12.5)},
+			{Action: "delete", ID: removed.ID.Hex()},
+			{Action: "create", Name: "Free", Description: "Free product", Price: price(0)},
+			{Action: "update", ID: kept.ID.Hex(), Description: "Twice"},
+			{Action: "delete", ID: primitive.NewObjectID().Hex()},
+			{Action: "rename", ID: kept.ID.Hex()},
+		}, false)
+		assert.NoError(t, err)
+		if !assert.Len(t, results, 7) {
+			return
+		}
+
+		assert.NoError(t, results[0].Err)
+		assert.NoError(t, results[1].Err)
+		assert.NoError(t, results[2].Err)
+		assert.ErrorIs(t, results[3].Err, product.ErrInvalidPrice)
+		assert.ErrorIs(t, results[4].Err, product.ErrDuplicateTarget)
+		assert.ErrorIs(t, results[5].Err, product.ErrProductNotFound)
+		assert.ErrorIs(t, results[6].Err, product.ErrInvalidBulkAction)
+
+		created, err := service.GetProduct(ctx, results[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "New", created.Name)
+
+		updated, _ := service.GetProduct(ctx, kept.ID.Hex())
+		assert.Equal(t, 12.5, updated.Price)
+
+		_, err = service.GetProduct(ctx, removed.ID.Hex())
+		assert.ErrorIs(t, err, product.ErrProductNotFound)
+	})
+
+	t.Run("atomic request applies nothing when an operation fails", func(t *testing.T) {
+		service, kept, removed := setup()
+
+		results, err := service.Bulk(ctx, []BulkOperation{
+			{Action: "update", ID: kept.ID.Hex(), Price: price(12.5)},
+			{Action: "delete", ID: removed.ID.Hex()},
+			{Action: "update", ID: kept.ID.Hex(), Price: price(-1)},
+		}, true)
+		assert.NoError(t, err)
+		if !assert.Len(t, results, 3) {
+			return
+		}
+
+		assert.ErrorIs(t, results[0].Err, product.ErrBulkAborted)
+		assert.ErrorIs(t, results[1].Err, product.ErrBulkAborted)
+		assert.Error(t, results[2].Err)
+
+		unchanged, _ := service.GetProduct(ctx, kept.ID.Hex())
+		assert.Equal(t, 10.0, unchanged.Price)
+		_, err = service.GetProduct(ctx, removed.ID.Hex())
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports targets deleted during the request", func(t *testing.T) {
+		for _, atomic := range []bool{false, true} {
+			repo := producttest.NewRepository()
+			service := NewService(repo, directRunner{})
+			kept, _ := service.CreateProduct(ctx, "Kept", "Kept product", 10.0)
+			service.repo = &vanishingRepository{Repository: repo}
+
+			results, err := service.Bulk(ctx, []BulkOperation{
+				{Action: "create", Name: "New", Description: "New product", Price: price(5.0)},
+				{Action: "update", ID: kept.ID.Hex(), Price: price(12.5)},
+			}, atomic)
+			assert.NoError(t, err)
+			if !assert.Len(t, results, 2) {
+				return
+			}
+
+			assert.ErrorIs(t, results[1].Err, product.ErrProductNotFound)
+			assert.Nil(t, results[1].Product)
+			_, err = service.GetProduct(ctx, kept.ID.Hex())
+			assert.ErrorIs(t, err, product.ErrProductNotFound)
+			if atomic {
+				assert.ErrorIs(t, results[0].Err, product.ErrBulkAborted)
+				assert.Empty(t, repo.Products)
+			} else {
+				assert.NoError(t, results[0].Err)
+			}
+		}
+	})
+
+	t.Run("refuses to delete bundle components", func(t *testing.T) {
+		service, kept, removed := setup()
+		pair, err := service.CreateBundle(ctx, "Pair", "Kept twice", []product.BundleComponent{
+			{ProductID: kept.ID, Quantity: 2},
+		}, nil)
+		assert.NoError(t, err)
+
+		results, err := service.Bulk(ctx, []BulkOperation{
+			{Action: "delete", ID: kept.ID.Hex()},
+			{Action: "delete", ID: removed.ID.Hex()},
+		}, false)
+		assert.NoError(t, err)
+		if !assert.Len(t, results, 2) {
+			return
+		}
+
+		assert.ErrorIs(t, results[0].Err, product.ErrProductInBundle)
+		assert.NoError(t, results[1].Err)
+		_, err = service.GetProduct(ctx, kept.ID.Hex())
+		assert.NoError(t, err)
+		_, err = service.GetProduct(ctx, pair.ID.Hex())
+		assert.NoError(t, err)
+	})
+
+	t.Run("reprices each bundle once", func(t *testing.T) {
+		service, kept, removed := setup()
+		pair, err := service.CreateBundle(ctx, "Pair", "One of each", []product.BundleComponent{
+			{ProductID: kept.ID, Quantity: 1},
+			{ProductID: removed.ID, Quantity: 1},
+		}, nil)
+		assert.NoError(t, err)
+		listener := &recordingListener{}
+		service.Subscribe(listener)
+
+		_, err = service.Bulk(ctx, []BulkOperation{
+			{Action: "update", ID: kept.ID.Hex(), Price: price(11)},
+			{Action: "update", ID: removed.ID.Hex(), Price: price(21)},
+		}, false)
+		assert.NoError(t, err)
+
+		repriced, _ := service.GetProduct(ctx, pair.ID.Hex())
+		assert.Equal(t, 32.0, repriced.Price)
+		updates := 0
+		for _, change := range listener.changes {
+			if change.ProductID == pair.ID.Hex() {
+				updates++
+			}
+		}
+		assert.Equal(t, 1, updates)
+	})
+
+	t.Run("rejects empty requests", func(t *testing.T) {
+		service, _, _ := setup()
+
+		_, err := service.Bulk(ctx, nil, false)
+		assert.ErrorIs(t, err, product.ErrEmptyBulk)
+	})
+}
@@ -0,0 +1,64 @@
+package product
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/domain/store"
+)
+
+// CarryingStore is a store that carries a product. DistanceKm is set only
+// when the stores were looked up around a location.
+type CarryingStore struct {
+	Store      *store.Store `json:"store"`
+	DistanceKm *float64     `json:"distance_km,omitempty"`
+}
+
+// StoresCarrying lists the stores that carry a product. A non-empty regionID
+// keeps only stores in that region or any region below it. A non-nil near
+// keeps only stores within its radius, nearest first; otherwise stores are
+// listed newest first. The filter's ProductID and RegionIDs are overwritten.
+func (s *AvailabilityService) StoresCarrying(ctx context.Context, productID string, filter store.ListFilter, regionID string, near *store.NearbyQuery, page, limit int) ([]*CarryingStore, int, error) {
+	p, err := s.products.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter.ProductID = &p.ID
+	filter.RegionIDs = nil
+	if regionID != "" {
+		ids, err := s.regions.SubtreeIDs(ctx, regionID)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.RegionIDs = ids
+	}
+
+	if near != nil {
+		query := *near
+		query.ProductID = &p.ID
+		query.Filter = filter
+
+		nearby, total, err := s.stores.FindNearby(ctx, &query, page, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		result := make([]*CarryingStore, len(nearby))
+		for i, n := range nearby {
+			distance := n.DistanceKm
+			result[i] = &CarryingStore{Store: n.Store, DistanceKm: &distance}
+		}
+		return result, total, nil
+	}
+
+	stores, total, err := s.stores.List(ctx, filter, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*CarryingStore, len(stores))
+	for i, st := range stores {
+		result[i] = &CarryingStore{Store: st}
+	}
+	return result, total, nil
+}
@@ -0,0 +1,69 @@
+package promotion
+
+import (
+	"context"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/promotion"
+)
+
+type Service struct {
+	repo promotion.Repository
+}
+
+func NewService(repo promotion.Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// Definition holds the fields of a promotion that can be set by clients
+type Definition struct {
+	Name      string
+	Rule      promotion.Rule
+	Scope     promotion.Scope
+	StartsAt  time.Time
+	EndsAt    *time.Time
+	Priority  int
+	Stackable bool
+}
+
+func (s *Service) CreatePromotion(ctx context.Context, def Definition) (*promotion.Promotion, error) {
+	p, err := promotion.NewPromotion(def.Name, def.Rule, def.Scope, def.StartsAt, def.EndsAt, def.Priority, def.Stackable)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Service) GetPromotion(ctx context.Context, id string) (*promotion.Promotion, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) UpdatePromotion(ctx context.Context, id string, def Definition) (*promotion.Promotion, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Revise(def.Name, def.Rule, def.Scope, def.StartsAt, def.EndsAt, def.Priority, def.Stackable); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Service) DeletePromotion(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) ListPromotions(ctx context.Context, page, limit int) ([]*promotion.Promotion, int, error) {
+	return s.repo.List(ctx, page, limit)
+}
@@ -0,0 +1,203 @@
+package purchaseorder
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/application/transaction"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/purchaseorder"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service struct {
+	repo      purchaseorder.Repository
+	suppliers supplier.Repository
+	stores    store.Repository
+	stock     inventory.Repository
+	tx        transaction.Runner
+}
+
+// NewService creates a purchase order service. Deliveries are booked on the
+// order and added to stock together in units of work run by tx.
+func NewService(repo purchaseorder.Repository, suppliers supplier.Repository, stores store.Repository, stock inventory.Repository, tx transaction.Runner) *Service {
+	return &Service{
+		repo:      repo,
+		suppliers: suppliers,
+		stores:    stores,
+		stock:     stock,
+		tx:        tx,
+	}
+}
+
+// Item is a product and quantity to order or receive
+type Item struct {
+	ProductID string
+	Quantity  int
+}
+
+// CreatePurchaseOrder starts a draft order from a supplier for a store. Each
+// line is priced at the supplier's cost price.
+func (s *Service) CreatePurchaseOrder(ctx context.Context, storeID, supplierID string, items []Item) (*purchaseorder.PurchaseOrder, error) {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	sup, err := s.suppliers.GetByID(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	po := purchaseorder.NewPurchaseOrder(st.ID, sup.ID)
+	for _, item := range items {
+		productID, err := primitive.ObjectIDFromHex(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if err := po.SetLine(sup, productID, item.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Create(ctx, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// GetPurchaseOrder returns a purchase order of a store. Orders of other
+// stores are reported as not found.
+func (s *Service) GetPurchaseOrder(ctx context.Context, storeID, id string) (*purchaseorder.PurchaseOrder, error) {
+	po, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if po.StoreID.Hex() != storeID {
+		return nil, purchaseorder.ErrPurchaseOrderNotFound
+	}
+	return po, nil
+}
+
+func (s *Service) ListPurchaseOrders(ctx context.Context, storeID string, status purchaseorder.Status, page, limit int) ([]*purchaseorder.PurchaseOrder, int, error) {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter := purchaseorder.ListFilter{StoreID: &st.ID, Status: status}
+	return s.repo.List(ctx, filter, page, limit)
+}
+
+// SetLine adds a product to a draft order or changes its quantity
+func (s *Service) SetLine(ctx context.Context, storeID, id, productID string, quantity int) (*purchaseorder.PurchaseOrder, error) {
+	po, err := s.GetPurchaseOrder(ctx, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sup, err := s.suppliers.GetByID(ctx, po.SupplierID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := po.SetLine(sup, objectID, quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+func (s *Service) RemoveLine(ctx context.Context, storeID, id, productID string) (*purchaseorder.PurchaseOrder, error) {
+	po, err := s.GetPurchaseOrder(ctx, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := po.RemoveLine(objectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// Send marks the order as sent, expecting delivery after the supplier's
+// lead time
+func (s *Service) Send(ctx context.Context, storeID, id string) (*purchaseorder.PurchaseOrder, error) {
+	po, err := s.GetPurchaseOrder(ctx, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sup, err := s.suppliers.GetByID(ctx, po.SupplierID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := po.Send(sup.LeadTime()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, po); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// Receive books a delivery against the order and adds the delivered units to
+// the store's stock, both or neither, so a failed delivery can be submitted
+// again. The order is saved under its version, so a delivery that is
+// submitted twice concurrently is only counted once.
+func (s *Service) Receive(ctx context.Context, storeID, id string, items []Item) (*purchaseorder.PurchaseOrder, error) {
+	po, err := s.GetPurchaseOrder(ctx, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]purchaseorder.Receipt, 0, len(items))
+	for _, item := range items {
+		productID, err := primitive.ObjectIDFromHex(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, purchaseorder.Receipt{ProductID: productID, Quantity: item.Quantity})
+	}
+
+	if err := po.Receive(receipts); err != nil {
+		return nil, err
+	}
+
+	err = s.tx.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, po); err != nil {
+			return err
+		}
+		for _, r := range receipts {
+			if err := s.stock.Receive(ctx, po.StoreID, r.ProductID, r.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return po, nil
+}
@@ -0,0 +1,229 @@
+package purchaseorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/purchaseorder"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// directRunner runs units of work without a transaction
+type directRunner struct{}
+
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memoryPurchaseOrders keeps copies of orders and saves them under their
+// version like the MongoDB repository
+type memoryPurchaseOrders struct {
+	purchaseorder.Repository
+	orders map[primitive.ObjectID]purchaseorder.PurchaseOrder
+	// afterGet runs once after the next GetByID, when set
+	afterGet func()
+}
+
+func (m *memoryPurchaseOrders) Create(ctx context.Context, po *purchaseorder.PurchaseOrder) error {
+	m.orders[po.ID] = *copyOrder(po)
+	return nil
+}
+
+func (m *memoryPurchaseOrders) GetByID(ctx context.Context, id string) (*purchaseorder.PurchaseOrder, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	po, ok := m.orders[objectID]
+	if !ok {
+		return nil, purchaseorder.ErrPurchaseOrderNotFound
+	}
+	if after := m.afterGet; after != nil {
+		m.afterGet = nil
+		after()
+	}
+	return copyOrder(&po), nil
+}
+
+func (m *memoryPurchaseOrders) Update(ctx context.Context, po *purchaseorder.PurchaseOrder) error {
+	stored, ok := m.orders[po.ID]
+	if !ok {
+		return purchaseorder.ErrPurchaseOrderNotFound
+	}
+	if stored.Version != po.Version {
+		return purchaseorder.ErrConcurrentUpdate
+	}
+	po.Version++
+	m.orders[po.ID] = *copyOrder(po)
+	return nil
+}
+
+// copyOrder copies an order along with its lines, which Receive changes in
+// place
+func copyOrder(po *purchaseorder.PurchaseOrder) *purchaseorder.PurchaseOrder {
+	c := *po
+	c.Lines = append([]purchaseorder.Line(nil), po.Lines...)
+	return &c
+}
+
+// receivedStock counts the units received per product; any other
+// inventory.Repository method panics
+type receivedStock struct {
+	inventory.Repository
+	received map[primitive.ObjectID]int
+}
+
+func (r *receivedStock) Receive(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	r.received[productID] += quantity
+	return nil
+}
+
+type singleStore struct {
+	store.Repository
+	store *store.Store
+}
+
+func (s *singleStore) GetByID(ctx context.Context, id string) (*store.Store, error) {
+	if id != s.store.ID.Hex() {
+		return nil, store.ErrStoreNotFound
+	}
+	return s.store, nil
+}
+
+type singleSupplier struct {
+	supplier.Repository
+	supplier *supplier.Supplier
+}
+
+func (s *singleSupplier) GetByID(ctx context.Context, id string) (*supplier.Supplier, error) {
+	if id != s.supplier.ID.Hex() {
+		return nil, supplier.ErrSupplierNotFound
+	}
+	return s.supplier, nil
+}
+
+type receiptFixture struct {
+	service *Service
+	orders  *memoryPurchaseOrders
+	stock   *receivedStock
+	store   *store.Store
+	po      *purchaseorder.PurchaseOrder
+	tea     primitive.ObjectID
+	coffee  primitive.ObjectID
+}
+
+// newReceiptFixture sends an order for 10 units of tea and 4 of coffee
+func newReceiptFixture(t *testing.T) *receiptFixture {
+	ctx := context.Background()
+	f := &receiptFixture{
+		orders: &memoryPurchaseOrders{orders: make(map[primitive.ObjectID]purchaseorder.PurchaseOrder)},
+		stock:  &receivedStock{received: make(map[primitive.ObjectID]int)},
+		tea:    primitive.NewObjectID(),
+		coffee: primitive.NewObjectID(),
+	}
+
+	var err error
+	f.store, err = store.NewStore("Main Street", "1 Main Street")
+	assert.NoError(t, err)
+	sup, err := supplier.NewSupplier("Leaf & Bean", supplier.Contact{}, 3)
+	assert.NoError(t, err)
+	assert.NoError(t, sup.SetProduct(f.tea, 2))
+	assert.NoError(t, sup.SetProduct(f.coffee, 5))
+
+	f.service = NewService(f.orders, &singleSupplier{supplier: sup}, &singleStore{store: f.store}, f.stock, directRunner{})
+	f.po, err = f.service.CreatePurchaseOrder(ctx, f.store.ID.Hex(), sup.ID.Hex(), []Item{
+		{ProductID: f.tea.Hex(), Quantity: 10},
+		{ProductID: f.coffee.Hex(), Quantity: 4},
+	})
+	assert.NoError(t, err)
+	_, err = f.service.Send(ctx, f.store.ID.Hex(), f.po.ID.Hex())
+	assert.NoError(t, err)
+	return f
+}
+
+func TestReceive(t *testing.T) {
+	testCases := []struct {
+		name         string
+		deliveries   [][]Item
+		wantErr      error
+		wantStatus   purchaseorder.Status
+		wantReceived map[string]int
+	}{
+		{
+			name:         "partial receipt",
+			deliveries:   [][]Item{{{ProductID: "tea", Quantity: 6}}},
+			wantStatus:   purchaseorder.StatusPartiallyReceived,
+			wantReceived: map[string]int{"tea": 6},
+		},
+		{
+			name: "received in full over two deliveries",
+			deliveries: [][]Item{
+				{{ProductID: "tea", Quantity: 6}},
+				{{ProductID: "tea", Quantity: 4}, {ProductID: "coffee", Quantity: 4}},
+			},
+			wantStatus:   purchaseorder.StatusReceived,
+			wantReceived: map[string]int{"tea": 10, "coffee": 4},
+		},
+		{
+			name: "over receipt",
+			deliveries: [][]Item{
+				{{ProductID: "tea", Quantity: 6}},
+				{{ProductID: "coffee", Quantity: 1}, {ProductID: "tea", Quantity: 5}},
+			},
+			wantErr:      purchaseorder.ErrOverReceipt,
+			wantStatus:   purchaseorder.StatusPartiallyReceived,
+			wantReceived: map[string]int{"tea": 6},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newReceiptFixture(t)
+			ids := map[string]primitive.ObjectID{"tea": f.tea, "coffee": f.coffee}
+
+			var err error
+			for _, delivery := range tc.deliveries {
+				items := make([]Item, 0, len(delivery))
+				for _, item := range delivery {
+					items = append(items, Item{ProductID: ids[item.ProductID].Hex(), Quantity: item.Quantity})
+				}
+				_, err = f.service.Receive(ctx, f.store.ID.Hex(), f.po.ID.Hex(), items)
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+
+			received := make(map[string]int)
+			for name, id := range ids {
+				if units, ok := f.stock.received[id]; ok {
+					received[name] = units
+				}
+			}
+			assert.Equal(t, tc.wantReceived, received)
+			assert.Equal(t, tc.wantStatus, f.orders.orders[f.po.ID].Status)
+		})
+	}
+}
+
+func TestReceiveStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	f := newReceiptFixture(t)
+	delivery := []Item{{ProductID: f.tea.Hex(), Quantity: 6}}
+
+	// The same delivery is booked by another request after this one has read
+	// the order
+	f.orders.afterGet = func() {
+		_, err := f.service.Receive(ctx, f.store.ID.Hex(), f.po.ID.Hex(), delivery)
+		assert.NoError(t, err)
+	}
+	_, err := f.service.Receive(ctx, f.store.ID.Hex(), f.po.ID.Hex(), delivery)
+	assert.ErrorIs(t, err, purchaseorder.ErrConcurrentUpdate)
+
+	assert.Equal(t, map[primitive.ObjectID]int{f.tea: 6}, f.stock.received)
+	stored := f.orders.orders[f.po.ID]
+	assert.Equal(t, 6, stored.Lines[0].Received)
+	assert.Equal(t, 0, stored.Lines[1].Received)
+}
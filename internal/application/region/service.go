@@ -0,0 +1,194 @@
+package region
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/domain/region"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service struct {
+	repo   region.Repository
+	stores store.Repository
+}
+
+func NewService(repo region.Repository, stores store.Repository) *Service {
+	return &Service{
+		repo:   repo,
+		stores: stores,
+	}
+}
+
+// CreateRegion creates a region under parentID, or a root region when
+// parentID is empty.
+func (s *Service) CreateRegion(ctx context.Context, name, parentID string) (*region.Region, error) {
+	var parent *region.Region
+	if parentID != "" {
+		var err error
+		parent, err = s.repo.GetByID(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := region.NewRegion(name, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Service) GetRegion(ctx context.Context, id string) (*region.Region, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) ListRegions(ctx context.Context) ([]*region.Region, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *Service) RegionTree(ctx context.Context) ([]*region.Node, error) {
+	regions, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return region.BuildTree(regions), nil
+}
+
+func (s *Service) RenameRegion(ctx context.Context, id, name string) error {
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Rename(name); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, r)
+}
+
+// MoveRegion re-parents a region together with its whole subtree. An empty
+// parentID makes the region a root.
+func (s *Service) MoveRegion(ctx context.Context, id, parentID string) error {
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var parent *region.Region
+	if parentID != "" {
+		parent, err = s.repo.GetByID(ctx, parentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	previous := r.Ancestors
+	if err := r.MoveTo(parent); err != nil {
+		return err
+	}
+
+	return s.repo.Move(ctx, r, previous, parent)
+}
+
+// DeleteRegion removes a region that has neither child regions nor stores
+func (s *Service) DeleteRegion(ctx context.Context, id string) error {
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	children, err := s.repo.CountChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return region.ErrRegionHasChildren
+	}
+
+	_, stores, err := s.stores.List(ctx, store.ListFilter{RegionIDs: []primitive.ObjectID{r.ID}}, 1, 1)
+	if err != nil {
+		return err
+	}
+	if stores > 0 {
+		return region.ErrRegionHasStores
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// AssignStore places a store in a region. An empty regionID removes the
+// store from its region.
+func (s *Service) AssignStore(ctx context.Context, storeID, regionID string) error {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return err
+	}
+
+	if regionID == "" {
+		st.AssignRegion(nil)
+		return s.stores.AssignRegion(ctx, storeID, nil)
+	}
+
+	r, err := s.repo.GetByID(ctx, regionID)
+	if err != nil {
+		return err
+	}
+
+	st.AssignRegion(&r.ID)
+	return s.stores.AssignRegion(ctx, storeID, st.RegionID)
+}
+
+// ListRegionStores lists stores assigned to the region or any region below it
+func (s *Service) ListRegionStores(ctx context.Context, id string, page, limit int) ([]*store.Store, int, error) {
+	ids, err := s.SubtreeIDs(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.stores.List(ctx, store.ListFilter{RegionIDs: ids}, page, limit)
+}
+
+// RegionStats aggregates store and product counts over the region's subtree
+func (s *Service) RegionStats(ctx context.Context, id string) (*region.Stats, error) {
+	ids, err := s.SubtreeIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.stores.Summarize(ctx, store.ListFilter{RegionIDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	return &region.Stats{
+		RegionID:             id,
+		StoreCount:           summary.StoreCount,
+		DistinctProductCount: summary.DistinctProductCount,
+	}, nil
+}
+
+// SubtreeIDs returns the IDs of the region and every region below it
+func (s *Service) SubtreeIDs(ctx context.Context, id string) ([]primitive.ObjectID, error) {
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.repo.ListDescendants(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(descendants)+1)
+	ids = append(ids, r.ID)
+	for _, d := range descendants {
+		ids = append(ids, d.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,283 @@
+package region
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/region"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryRegions keeps regions in a map and checks moves the same way as the
+// MongoDB repository
+type memoryRegions struct {
+	mu      sync.Mutex
+	regions map[primitive.ObjectID]region.Region
+}
+
+func newMemoryRegions() *memoryRegions {
+	return &memoryRegions{regions: make(map[primitive.ObjectID]region.Region)}
+}
+
+func (m *memoryRegions) Create(ctx context.Context, r *region.Region) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.regions[r.ID] = *r
+	return nil
+}
+
+func (m *memoryRegions) GetByID(ctx context.Context, id string) (*region.Region, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.regions[objectID]
+	if !ok {
+		return nil, region.ErrRegionNotFound
+	}
+	return &r, nil
+}
+
+func (m *memoryRegions) Update(ctx context.Context, r *region.Region) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.regions[r.ID]; !ok {
+		return region.ErrRegionNotFound
+	}
+	m.regions[r.ID] = *r
+	return nil
+}
+
+func (m *memoryRegions) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.regions[objectID]; !ok {
+		return region.ErrRegionNotFound
+	}
+	delete(m.regions, objectID)
+	return nil
+}
+
+func (m *memoryRegions) List(ctx context.Context) ([]*region.Region, error) {
+	return m.find(func(*region.Region) bool { return true }), nil
+}
+
+func (m *memoryRegions) ListDescendants(ctx context.Context, id string) ([]*region.Region, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return m.find(func(r *region.Region) bool {
+		for _, ancestor := range r.Ancestors {
+			if ancestor == objectID {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (m *memoryRegions) CountChildren(ctx context.Context, id string) (int, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+	children := m.find(func(r *region.Region) bool {
+		return r.ParentID != nil && *r.ParentID == objectID
+	})
+	return len(children), nil
+}
+
+func (m *memoryRegions) Move(ctx context.Context, moved *region.Region, previousAncestors []primitive.ObjectID, parent *region.Region) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.regions[moved.ID]
+	if !ok || !samePath(current.Ancestors, previousAncestors) {
+		return region.ErrRegionChanged
+	}
+	if parent != nil {
+		if current, ok := m.regions[parent.ID]; !ok || !samePath(current.Ancestors, parent.Ancestors) {
+			return region.ErrRegionChanged
+		}
+	}
+
+	m.regions[moved.ID] = *moved
+	for id, r := range m.regions {
+		for i, ancestor := range r.Ancestors {
+			if ancestor == moved.ID {
+				below := append([]primitive.ObjectID{}, r.Ancestors[i:]...)
+				r.Ancestors = append(append([]primitive.ObjectID{}, moved.Ancestors...), below...)
+				m.regions[id] = r
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memoryRegions) find(keep func(*region.Region) bool) []*region.Region {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	regions := make([]*region.Region, 0)
+	for _, r := range m.regions {
+		r := r
+		if keep(&r) {
+			regions = append(regions, &r)
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Name < regions[j].Name })
+	return regions
+}
+
+func samePath(a, b []primitive.ObjectID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryStores implements the store lookups the region service uses; any
+// other store.Repository method panics
+type memoryStores struct {
+	store.Repository
+	stores []*store.Store
+}
+
+func (m *memoryStores) List(ctx context.Context, filter store.ListFilter, page, limit int) ([]*store.Store, int, error) {
+	matching := make([]*store.Store, 0)
+	for _, st := range m.stores {
+		for _, id := range filter.RegionIDs {
+			if st.RegionID != nil && *st.RegionID == id {
+				matching = append(matching, st)
+				break
+			}
+		}
+	}
+	return matching, len(matching), nil
+}
+
+func newTestHierarchy(t *testing.T, service *Service) (europe, germany, berlin, dach *region.Region) {
+	ctx := context.Background()
+	europe, err := service.CreateRegion(ctx, "Europe", "")
+	assert.NoError(t, err)
+	germany, err = service.CreateRegion(ctx, "Germany", europe.ID.Hex())
+	assert.NoError(t, err)
+	berlin, err = service.CreateRegion(ctx, "Berlin", germany.ID.Hex())
+	assert.NoError(t, err)
+	dach, err = service.CreateRegion(ctx, "DACH", europe.ID.Hex())
+	assert.NoError(t, err)
+	return europe, germany, berlin, dach
+}
+
+func TestMoveRegion(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryRegions(), &memoryStores{})
+	europe, germany, berlin, dach := newTestHierarchy(t, service)
+
+	assert.NoError(t, service.MoveRegion(ctx, germany.ID.Hex(), dach.ID.Hex()))
+
+	moved, err := service.GetRegion(ctx, germany.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, dach.ID, *moved.ParentID)
+	assert.Equal(t, []primitive.ObjectID{europe.ID, dach.ID}, moved.Ancestors)
+
+	rebased, err := service.GetRegion(ctx, berlin.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []primitive.ObjectID{europe.ID, dach.ID, germany.ID}, rebased.Ancestors)
+
+	ids, err := service.SubtreeIDs(ctx, dach.ID.Hex())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []primitive.ObjectID{dach.ID, germany.ID, berlin.ID}, ids)
+
+	assert.NoError(t, service.MoveRegion(ctx, germany.ID.Hex(), ""))
+	moved, err = service.GetRegion(ctx, germany.ID.Hex())
+	assert.NoError(t, err)
+	assert.Nil(t, moved.ParentID)
+	rebased, err = service.GetRegion(ctx, berlin.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []primitive.ObjectID{germany.ID}, rebased.Ancestors)
+}
+
+func TestMoveRegionRejectsCycles(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMemoryRegions(), &memoryStores{})
+	europe, germany, berlin, _ := newTestHierarchy(t, service)
+
+	testCases := []struct {
+		name     string
+		id       string
+		parentID string
+		wantErr  error
+	}{
+		{name: "under itself", id: germany.ID.Hex(), parentID: germany.ID.Hex(), wantErr: region.ErrInvalidParent},
+		{name: "under its child", id: germany.ID.Hex(), parentID: berlin.ID.Hex(), wantErr: region.ErrInvalidParent},
+		{name: "under a descendant further down", id: europe.ID.Hex(), parentID: berlin.ID.Hex(), wantErr: region.ErrInvalidParent},
+		{name: "unknown parent", id: germany.ID.Hex(), parentID: primitive.NewObjectID().Hex(), wantErr: region.ErrRegionNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, service.MoveRegion(ctx, tc.id, tc.parentID), tc.wantErr)
+		})
+	}
+
+	unchanged, err := service.GetRegion(ctx, berlin.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []primitive.ObjectID{europe.ID, germany.ID}, unchanged.Ancestors)
+}
+
+func TestMoveRegionConflictsWithConcurrentMove(t *testing.T) {
+	ctx := context.Background()
+	regions := newMemoryRegions()
+	service := NewService(regions, &memoryStores{})
+	_, germany, _, dach := newTestHierarchy(t, service)
+
+	// DACH moves under Germany while a move of Germany under DACH, based on
+	// the hierarchy read before, is being applied
+	stale, err := service.GetRegion(ctx, dach.ID.Hex())
+	assert.NoError(t, err)
+	assert.NoError(t, service.MoveRegion(ctx, dach.ID.Hex(), germany.ID.Hex()))
+
+	moving, err := service.GetRegion(ctx, germany.ID.Hex())
+	assert.NoError(t, err)
+	previous := moving.Ancestors
+	assert.NoError(t, moving.MoveTo(stale))
+	assert.ErrorIs(t, regions.Move(ctx, moving, previous, stale), region.ErrRegionChanged)
+}
+
+func TestDeleteRegion(t *testing.T) {
+	ctx := context.Background()
+	stores := &memoryStores{}
+	service := NewService(newMemoryRegions(), stores)
+	europe, germany, berlin, dach := newTestHierarchy(t, service)
+
+	st, err := store.NewStore("Alexanderplatz", "Alexanderplatz 1, Berlin")
+	assert.NoError(t, err)
+	st.AssignRegion(&berlin.ID)
+	stores.stores = append(stores.stores, st)
+
+	assert.ErrorIs(t, service.DeleteRegion(ctx, europe.ID.Hex()), region.ErrRegionHasChildren)
+	assert.ErrorIs(t, service.DeleteRegion(ctx, germany.ID.Hex()), region.ErrRegionHasChildren)
+	assert.ErrorIs(t, service.DeleteRegion(ctx, berlin.ID.Hex()), region.ErrRegionHasStores)
+	assert.ErrorIs(t, service.DeleteRegion(ctx, primitive.NewObjectID().Hex()), region.ErrRegionNotFound)
+
+	assert.NoError(t, service.DeleteRegion(ctx, dach.ID.Hex()))
+	_, err = service.GetRegion(ctx, dach.ID.Hex())
+	assert.ErrorIs(t, err, region.ErrRegionNotFound)
+}
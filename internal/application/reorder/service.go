@@ -0,0 +1,150 @@
+package reorder
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/purchaseorder"
+	"github.com/stasshander/ddd/internal/domain/reorder"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service struct {
+	repo           reorder.Repository
+	stock          inventory.Repository
+	suppliers      supplier.Repository
+	purchaseOrders purchaseorder.Repository
+	stores         store.Repository
+
+	// gauged holds the stores the below-threshold gauge was last set for
+	mu     sync.Mutex
+	gauged map[primitive.ObjectID]bool
+}
+
+func NewService(repo reorder.Repository, stock inventory.Repository, suppliers supplier.Repository, purchaseOrders purchaseorder.Repository, stores store.Repository) *Service {
+	return &Service{
+		repo:           repo,
+		stock:          stock,
+		suppliers:      suppliers,
+		purchaseOrders: purchaseOrders,
+		stores:         stores,
+		gauged:         make(map[primitive.ObjectID]bool),
+	}
+}
+
+// Scan checks every store's stock against its reorder points, stores the
+// resulting suggestions and updates the below-threshold gauge, which reads 0
+// for stores with nothing to reorder. It returns the number of products that
+// need reordering.
+func (s *Service) Scan(ctx context.Context) (int, error) {
+	items, err := s.stock.ListBelowReorderPoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	open, err := s.purchaseOrders.ListOpen(ctx)
+	if err != nil {
+		return 0, err
+	}
+	onOrder := make(reorder.OnOrder)
+	for _, po := range open {
+		for _, line := range po.Lines {
+			onOrder[reorder.StockKey{StoreID: po.StoreID, ProductID: line.ProductID}] += line.Outstanding()
+		}
+	}
+
+	suppliers := make(map[primitive.ObjectID][]*supplier.Supplier)
+	for _, item := range items {
+		if _, ok := suppliers[item.ProductID]; ok {
+			continue
+		}
+		found, err := s.suppliers.ListByProduct(ctx, item.ProductID)
+		if err != nil {
+			return 0, err
+		}
+		suppliers[item.ProductID] = found
+	}
+
+	now := time.Now()
+	suggestions := reorder.Build(items, onOrder, suppliers, now)
+	if err := s.repo.Replace(ctx, suggestions, now); err != nil {
+		return 0, err
+	}
+
+	counts := make(map[primitive.ObjectID]int)
+	err = s.stores.Each(ctx, store.ListFilter{}, func(st *store.Store) error {
+		counts[st.ID] = 0
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for storeID, count := range reorder.CountByStore(suggestions) {
+		counts[storeID] = count
+		total += count
+	}
+
+	s.updateGauge(counts)
+	return total, nil
+}
+
+// updateGauge sets the below-threshold gauge of every store in counts and
+// then drops the stores that no longer exist, so the gauge never reads
+// empty between scans
+func (s *Service) updateGauge(counts map[primitive.ObjectID]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for storeID, count := range counts {
+		metrics.StockBelowReorderPoint.WithLabelValues(storeID.Hex()).Set(float64(count))
+	}
+	for storeID := range s.gauged {
+		if _, ok := counts[storeID]; !ok {
+			metrics.StockBelowReorderPoint.DeleteLabelValues(storeID.Hex())
+		}
+	}
+
+	s.gauged = make(map[primitive.ObjectID]bool, len(counts))
+	for storeID := range counts {
+		s.gauged[storeID] = true
+	}
+}
+
+// RunScanner scans immediately and then every interval until ctx is done
+func (s *Service) RunScanner(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := s.Scan(ctx)
+		if err != nil {
+			log.Printf("Failed to scan stock for reordering: %v", err)
+		} else if count > 0 {
+			log.Printf("%d products are at or below their reorder point", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StoreSuggestions returns the reorder suggestions from the latest scan for
+// a store
+func (s *Service) StoreSuggestions(ctx context.Context, storeID string) ([]*reorder.Suggestion, error) {
+	st, err := s.stores.GetByID(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListByStore(ctx, st.ID)
+}
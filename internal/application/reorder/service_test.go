@@ -0,0 +1,134 @@
+package reorder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/purchaseorder"
+	"github.com/stasshander/ddd/internal/domain/reorder"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"github.com/stasshander/ddd/internal/infrastructure/metrics"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type belowReorderPoint struct {
+	inventory.Repository
+	items []*inventory.StockItem
+}
+
+func (b *belowReorderPoint) ListBelowReorderPoint(ctx context.Context) ([]*inventory.StockItem, error) {
+	return b.items, nil
+}
+
+type openPurchaseOrders struct {
+	purchaseorder.Repository
+	orders []*purchaseorder.PurchaseOrder
+}
+
+func (o *openPurchaseOrders) ListOpen(ctx context.Context) ([]*purchaseorder.PurchaseOrder, error) {
+	return o.orders, nil
+}
+
+// noSuppliers supplies no product
+type noSuppliers struct {
+	supplier.Repository
+}
+
+func (noSuppliers) ListByProduct(ctx context.Context, productID primitive.ObjectID) ([]*supplier.Supplier, error) {
+	return nil, nil
+}
+
+type latestSuggestions struct {
+	suggestions []*reorder.Suggestion
+}
+
+func (l *latestSuggestions) Replace(ctx context.Context, suggestions []*reorder.Suggestion, generatedAt time.Time) error {
+	l.suggestions = suggestions
+	return nil
+}
+
+func (l *latestSuggestions) ListByStore(ctx context.Context, storeID primitive.ObjectID) ([]*reorder.Suggestion, error) {
+	var found []*reorder.Suggestion
+	for _, s := range l.suggestions {
+		if s.StoreID == storeID {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+type listedStores struct {
+	store.Repository
+	stores []*store.Store
+}
+
+func (l *listedStores) Each(ctx context.Context, filter store.ListFilter, fn func(*store.Store) error) error {
+	for _, st := range l.stores {
+		if err := fn(st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	tea, coffee := primitive.NewObjectID(), primitive.NewObjectID()
+
+	var stores []*store.Store
+	for _, name := range []string{"Main Street", "High Street", "Market Square"} {
+		st, err := store.NewStore(name, "1 "+name)
+		assert.NoError(t, err)
+		stores = append(stores, st)
+	}
+	main, high, market := stores[0], stores[1], stores[2]
+
+	// Main Street has tea on order; High Street has nothing on order
+	stock := &belowReorderPoint{items: []*inventory.StockItem{
+		{StoreID: main.ID, ProductID: tea, OnHand: 1, ReorderPoint: 4, ReorderQuantity: 10},
+		{StoreID: high.ID, ProductID: tea, OnHand: 1, ReorderPoint: 4, ReorderQuantity: 10},
+		{StoreID: high.ID, ProductID: coffee, OnHand: 0, ReorderPoint: 2, ReorderQuantity: 6},
+	}}
+	po := purchaseorder.NewPurchaseOrder(main.ID, primitive.NewObjectID())
+	po.Lines = []purchaseorder.Line{{ProductID: tea, Quantity: 10, Received: 4}}
+	purchaseOrders := &openPurchaseOrders{orders: []*purchaseorder.PurchaseOrder{po}}
+
+	repo := &latestSuggestions{}
+	listed := &listedStores{stores: stores}
+	service := NewService(repo, stock, noSuppliers{}, purchaseOrders, listed)
+
+	total, err := service.Scan(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	suggestions, err := repo.ListByStore(ctx, main.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+	suggestions, err = repo.ListByStore(ctx, high.ID)
+	assert.NoError(t, err)
+	assert.Len(t, suggestions, 1)
+	assert.Len(t, suggestions[0].Lines, 2)
+
+	gauge := func(st *store.Store) float64 {
+		return testutil.ToFloat64(metrics.StockBelowReorderPoint.WithLabelValues(st.ID.Hex()))
+	}
+	assert.Equal(t, 0.0, gauge(main))
+	assert.Equal(t, 2.0, gauge(high))
+	assert.Equal(t, 0.0, gauge(market))
+
+	// Once High Street's stock is topped up and Market Square is gone, the
+	// first reads 0 and the second is no longer reported
+	stock.items = stock.items[:1]
+	listed.stores = stores[:2]
+	total, err = service.Scan(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, 0.0, gauge(main))
+	assert.Equal(t, 0.0, gauge(high))
+	assert.False(t, metrics.StockBelowReorderPoint.DeleteLabelValues(market.ID.Hex()))
+}
@@ -0,0 +1,121 @@
+package review
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/review"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service struct {
+	repo     review.Repository
+	products product.Repository
+	ratings  review.RatingWriter
+}
+
+func NewService(repo review.Repository, products product.Repository, ratings review.RatingWriter) *Service {
+	return &Service{
+		repo:     repo,
+		products: products,
+		ratings:  ratings,
+	}
+}
+
+// SubmitReview records a customer review. It stays pending, and out of the
+// product's rating, until a moderator approves it.
+func (s *Service) SubmitReview(ctx context.Context, productID, author string, rating int, text string) (*review.Review, error) {
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	rv, err := review.NewReview(p.ID, author, rating, text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, rv); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+func (s *Service) GetReview(ctx context.Context, id string) (*review.Review, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListProductReviews lists a product's reviews in the given moderation state
+func (s *Service) ListProductReviews(ctx context.Context, productID string, status review.Status, page, limit int) ([]*review.Review, int, error) {
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filter := review.ListFilter{ProductID: &p.ID, Status: status}
+	return s.repo.List(ctx, filter, page, limit)
+}
+
+// ListReviews lists reviews across all products, for moderation
+func (s *Service) ListReviews(ctx context.Context, status review.Status, page, limit int) ([]*review.Review, int, error) {
+	return s.repo.List(ctx, review.ListFilter{Status: status}, page, limit)
+}
+
+func (s *Service) ApproveReview(ctx context.Context, id string) (*review.Review, error) {
+	return s.moderate(ctx, id, (*review.Review).Approve)
+}
+
+func (s *Service) RejectReview(ctx context.Context, id, reason string) (*review.Review, error) {
+	return s.moderate(ctx, id, func(rv *review.Review) error {
+		return rv.Reject(reason)
+	})
+}
+
+func (s *Service) DeleteReview(ctx context.Context, id string) error {
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if rv.Status == review.StatusApproved {
+		return s.refreshRating(ctx, rv.ProductID)
+	}
+	return nil
+}
+
+// moderate applies a moderation decision to the review as loaded. A review
+// moderated by someone else in the meantime fails with ErrConcurrentUpdate
+// instead of being overwritten.
+func (s *Service) moderate(ctx context.Context, id string, change func(*review.Review) error) (*review.Review, error) {
+	rv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := rv.Status
+	if err := change(rv); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateStatus(ctx, rv, previous); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshRating(ctx, rv.ProductID); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+// refreshRating recomputes the product's rating from its approved reviews.
+// Recomputing rather than adjusting the stored figures keeps the summary
+// correct even if an earlier refresh failed, and recomputing it in the write
+// that stores it leaves no round trip in which a concurrent moderation can
+// be overwritten by a summary read before it.
+func (s *Service) refreshRating(ctx context.Context, productID primitive.ObjectID) error {
+	return s.ratings.RefreshRating(ctx, productID)
+}
@@ -0,0 +1,273 @@
+package review
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/review"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryReviews keeps reviews in a map and checks status updates the same
+// way as the MongoDB repository
+type memoryReviews struct {
+	mu      sync.Mutex
+	reviews map[primitive.ObjectID]review.Review
+}
+
+func newMemoryReviews() *memoryReviews {
+	return &memoryReviews{reviews: make(map[primitive.ObjectID]review.Review)}
+}
+
+func (m *memoryReviews) Create(ctx context.Context, rv *review.Review) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reviews[rv.ID] = *rv
+	return nil
+}
+
+func (m *memoryReviews) GetByID(ctx context.Context, id string) (*review.Review, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rv, ok := m.reviews[objectID]
+	if !ok {
+		return nil, review.ErrReviewNotFound
+	}
+	return &rv, nil
+}
+
+func (m *memoryReviews) UpdateStatus(ctx context.Context, rv *review.Review, previous review.Status) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.reviews[rv.ID]
+	if !ok || current.Status != previous {
+		return review.ErrConcurrentUpdate
+	}
+	m.reviews[rv.ID] = *rv
+	return nil
+}
+
+func (m *memoryReviews) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.reviews[objectID]; !ok {
+		return review.ErrReviewNotFound
+	}
+	delete(m.reviews, objectID)
+	return nil
+}
+
+func (m *memoryReviews) List(ctx context.Context, filter review.ListFilter, page, limit int) ([]*review.Review, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var found []*review.Review
+	for _, rv := range m.reviews {
+		if filter.ProductID != nil && rv.ProductID != *filter.ProductID {
+			continue
+		}
+		if filter.Status != "" && rv.Status != filter.Status {
+			continue
+		}
+		rv := rv
+		found = append(found, &rv)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].CreatedAt.After(found[j].CreatedAt) })
+
+	start := min((page-1)*limit, len(found))
+	end := min(start+limit, len(found))
+	return found[start:end], len(found), nil
+}
+
+// summarize aggregates the approved reviews of a product
+func (m *memoryReviews) summarize(productID primitive.ObjectID) product.RatingSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total, count := 0, 0
+	for _, rv := range m.reviews {
+		if rv.ProductID == productID && rv.Status == review.StatusApproved {
+			total += rv.Rating
+			count++
+		}
+	}
+	return product.NewRatingSummary(total, count)
+}
+
+// staleReviews hands out the review as it was before another moderator
+// changed it
+type staleReviews struct {
+	*memoryReviews
+	stale review.Review
+}
+
+func (s *staleReviews) GetByID(ctx context.Context, id string) (*review.Review, error) {
+	rv := s.stale
+	return &rv, nil
+}
+
+// memoryProducts serves a single product and rates it from reviews
+type memoryProducts struct {
+	product.Repository
+	product *product.Product
+	reviews *memoryReviews
+}
+
+func (m *memoryProducts) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	if id != m.product.ID.Hex() {
+		return nil, product.ErrProductNotFound
+	}
+	return m.product, nil
+}
+
+func (m *memoryProducts) RefreshRating(ctx context.Context, productID primitive.ObjectID) error {
+	if productID == m.product.ID {
+		m.product.Rating = m.reviews.summarize(productID)
+	}
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *memoryReviews, *memoryProducts) {
+	p, err := product.NewProduct("Coffee", "Dark roast", 12)
+	assert.NoError(t, err)
+	p.ID = primitive.NewObjectID()
+
+	reviews := newMemoryReviews()
+	products := &memoryProducts{product: p, reviews: reviews}
+	return NewService(reviews, products, products), reviews, products
+}
+
+// moderation is one moderator decision on the review with the given ID
+type moderation func(s *Service, id string) (*review.Review, error)
+
+func TestModerationTransitions(t *testing.T) {
+	var approve moderation = func(s *Service, id string) (*review.Review, error) {
+		return s.ApproveReview(context.Background(), id)
+	}
+	var reject moderation = func(s *Service, id string) (*review.Review, error) {
+		return s.RejectReview(context.Background(), id, "spam")
+	}
+
+	testCases := []struct {
+		name       string
+		steps      []moderation
+		wantStatus review.Status
+		wantErr    error
+	}{
+		{name: "approve pending", steps: []moderation{approve}, wantStatus: review.StatusApproved},
+		{name: "reject pending", steps: []moderation{reject}, wantStatus: review.StatusRejected},
+		{name: "reject approved", steps: []moderation{approve, reject}, wantStatus: review.StatusRejected},
+		{name: "approve rejected", steps: []moderation{reject, approve}, wantStatus: review.StatusApproved},
+		{name: "approve twice", steps: []moderation{approve, approve}, wantStatus: review.StatusApproved, wantErr: review.ErrInvalidStatusTransition},
+		{name: "reject twice", steps: []moderation{reject, reject}, wantStatus: review.StatusRejected, wantErr: review.ErrInvalidStatusTransition},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, reviews, products := newTestService(t)
+			rv, err := s.SubmitReview(context.Background(), products.product.ID.Hex(), "Sam", 4, "Good")
+			assert.NoError(t, err)
+
+			for _, step := range tc.steps {
+				_, err = step(s, rv.ID.Hex())
+			}
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			stored, err := reviews.GetByID(context.Background(), rv.ID.Hex())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, stored.Status)
+		})
+	}
+}
+
+func TestModerationConflict(t *testing.T) {
+	s, reviews, products := newTestService(t)
+	rv, err := s.SubmitReview(context.Background(), products.product.ID.Hex(), "Sam", 1, "Bad")
+	assert.NoError(t, err)
+
+	// Another moderator rejects the review after it was loaded for approval
+	stale := &staleReviews{memoryReviews: reviews, stale: *rv}
+	_, err = s.RejectReview(context.Background(), rv.ID.Hex(), "spam")
+	assert.NoError(t, err)
+
+	late := NewService(stale, products, products)
+	_, err = late.ApproveReview(context.Background(), rv.ID.Hex())
+	assert.ErrorIs(t, err, review.ErrConcurrentUpdate)
+
+	stored, err := reviews.GetByID(context.Background(), rv.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, review.StatusRejected, stored.Status)
+	assert.Equal(t, product.RatingSummary{}, products.product.Rating)
+}
+
+func TestRatingAggregation(t *testing.T) {
+	ctx := context.Background()
+	s, _, products := newTestService(t)
+	productID := products.product.ID.Hex()
+
+	five, err := s.SubmitReview(ctx, productID, "Sam", 5, "")
+	assert.NoError(t, err)
+	four, err := s.SubmitReview(ctx, productID, "Alex", 4, "")
+	assert.NoError(t, err)
+	_, err = s.SubmitReview(ctx, productID, "Kim", 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, product.RatingSummary{}, products.product.Rating)
+
+	_, err = s.ApproveReview(ctx, five.ID.Hex())
+	assert.NoError(t, err)
+	_, err = s.ApproveReview(ctx, four.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, product.RatingSummary{Average: 4.5, Count: 2}, products.product.Rating)
+
+	_, err = s.RejectReview(ctx, four.ID.Hex(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, product.RatingSummary{Average: 5, Count: 1}, products.product.Rating)
+
+	assert.NoError(t, s.DeleteReview(ctx, five.ID.Hex()))
+	assert.Equal(t, product.RatingSummary{}, products.product.Rating)
+}
+
+func TestListProductReviewsByStatus(t *testing.T) {
+	ctx := context.Background()
+	s, _, products := newTestService(t)
+	productID := products.product.ID.Hex()
+
+	approved, err := s.SubmitReview(ctx, productID, "Sam", 5, "")
+	assert.NoError(t, err)
+	_, err = s.ApproveReview(ctx, approved.ID.Hex())
+	assert.NoError(t, err)
+	rejected, err := s.SubmitReview(ctx, productID, "Alex", 1, "")
+	assert.NoError(t, err)
+	_, err = s.RejectReview(ctx, rejected.ID.Hex(), "spam")
+	assert.NoError(t, err)
+	_, err = s.SubmitReview(ctx, productID, "Kim", 3, "")
+	assert.NoError(t, err)
+
+	listed, total, err := s.ListProductReviews(ctx, productID, review.StatusApproved, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, approved.ID, listed[0].ID)
+	}
+
+	_, total, err = s.ListProductReviews(ctx, productID, "", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+
+	_, _, err = s.ListProductReviews(ctx, primitive.NewObjectID().Hex(), review.StatusApproved, 1, 10)
+	assert.ErrorIs(t, err, product.ErrProductNotFound)
+}
@@ -2,19 +2,23 @@ package store
 
 import (
 	"context"
+	"math"
+	"time"
 
+	appregion "github.com/stasshander/ddd/internal/application/region"
 	"github.com/stasshander/ddd/internal/domain/store"
-	"github.com/stasshander/ddd/internal/infrastructure/mongodb"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Service struct {
-	repo *mongodb.StoreRepository
+	repo    store.Repository
+	regions *appregion.Service
 }
 
-func NewService(repo *mongodb.StoreRepository) *Service {
+func NewService(repo store.Repository, regions *appregion.Service) *Service {
 	return &Service{
-		repo: repo,
+		repo:    repo,
+		regions: regions,
 	}
 }
 
@@ -29,10 +33,27 @@ func (s *Service) CreateStore(ctx context.Context, name, address string) (*store
 	return store, nil
 }
 
+func (s *Service) CreateStoreWithAddress(ctx context.Context, name string, address *store.Address) (*store.Store, error) {
+	store, err := store.NewStoreWithAddress(name, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
 func (s *Service) GetStore(ctx context.Context, id string) (*store.Store, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+// GetStoreWithProducts returns a store along with a page of its products
+// resolved into product documents
+func (s *Service) GetStoreWithProducts(ctx context.Context, id string, expansion store.ProductExpansion) (*store.Store, *store.ProductPage, error) {
+	return s.repo.GetWithProducts(ctx, id, expansion)
+}
+
 func (s *Service) UpdateStoreName(ctx context.Context, id, name string) error {
 	store, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -59,12 +80,180 @@ func (s *Service) UpdateStoreAddress(ctx context.Context, id, address string) er
 	return s.repo.Update(ctx, store)
 }
 
+func (s *Service) UpdateStorePostalAddress(ctx context.Context, id string, address *store.Address) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := store.UpdatePostalAddress(address); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, store)
+}
+
+func (s *Service) UpdateStoreLocation(ctx context.Context, id string, latitude, longitude float64) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := store.UpdateLocation(latitude, longitude); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, store)
+}
+
+func (s *Service) UpdateOpeningHours(ctx context.Context, id string, hours *store.OpeningHours) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := store.UpdateOpeningHours(hours); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, store)
+}
+
+func (s *Service) SetOpeningException(ctx context.Context, id string, day *store.ExceptionDay) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetOpeningException(day); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, store)
+}
+
+func (s *Service) RemoveOpeningException(ctx context.Context, id, date string) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := store.RemoveOpeningException(date); err != nil {
+		return err
+	}
+
+	return s.repo.Update(ctx, store)
+}
+
+func (s *Service) IsStoreOpen(ctx context.Context, id string, at time.Time) (bool, error) {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return store.IsOpenAt(at)
+}
+
+func (s *Service) OpenStore(ctx context.Context, id string) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	from := store.CurrentStatus()
+	if err := store.Open(); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateStatus(ctx, id, from, store.Status)
+}
+
+func (s *Service) SuspendStore(ctx context.Context, id string) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	from := store.CurrentStatus()
+	if err := store.Suspend(); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateStatus(ctx, id, from, store.Status)
+}
+
+func (s *Service) CloseStore(ctx context.Context, id string) error {
+	store, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	from := store.CurrentStatus()
+	if err := store.Close(); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateStatus(ctx, id, from, store.Status)
+}
+
 func (s *Service) DeleteStore(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *Service) ListStores(ctx context.Context, page, limit int) ([]*store.Store, int, error) {
-	return s.repo.List(ctx, page, limit)
+// ListStores lists the stores matching filter, newest first. A non-empty
+// regionID keeps only stores in that region or any region below it, and
+// replaces the filter's RegionIDs.
+func (s *Service) ListStores(ctx context.Context, filter store.ListFilter, regionID string, page, limit int) ([]*store.Store, int, error) {
+	if regionID != "" {
+		ids, err := s.regions.SubtreeIDs(ctx, regionID)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.RegionIDs = ids
+	}
+
+	if filter.OpenAt == nil {
+		return s.repo.List(ctx, filter, page, limit)
+	}
+
+	// Only stores in the open status can be open at any time
+	if filter.Status != "" && filter.Status != store.StatusOpen {
+		return []*store.Store{}, 0, nil
+	}
+
+	// Opening hours depend on each store's time zone and exceptions, which
+	// cannot be evaluated in a query. The query narrows the candidates to
+	// open stores with hours, which are streamed oldest first and checked one
+	// at a time. Only the newest open stores up to the end of the requested
+	// page are kept in memory.
+	keep := math.MaxInt
+	if page <= math.MaxInt/limit {
+		keep = page * limit
+	}
+	var newest []*store.Store
+	total := 0
+	err := s.repo.Each(ctx, filter, func(st *store.Store) error {
+		if isOpen, err := st.IsOpenAt(*filter.OpenAt); err != nil || !isOpen {
+			return nil
+		}
+		total++
+		newest = append(newest, st)
+		if len(newest) > keep {
+			newest = newest[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	start := min(keep-limit, len(newest))
+	end := min(start+limit, len(newest))
+	stores := make([]*store.Store, 0, end-start)
+	for i := start; i < end; i++ {
+		stores = append(stores, newest[len(newest)-1-i])
+	}
+	return stores, total, nil
 }
 
 func (s *Service) AddProductToStore(ctx context.Context, storeID string, productID primitive.ObjectID) error {
@@ -77,7 +266,7 @@ func (s *Service) AddProductToStore(ctx context.Context, storeID string, product
 		return err
 	}
 
-	return s.repo.Update(ctx, store)
+	return s.repo.AddProduct(ctx, storeID, productID.Hex())
 }
 
 func (s *Service) RemoveProductFromStore(ctx context.Context, storeID string, productID primitive.ObjectID) error {
@@ -90,5 +279,9 @@ func (s *Service) RemoveProductFromStore(ctx context.Context, storeID string, pr
 		return err
 	}
 
-	return s.repo.Update(ctx, store)
+	return s.repo.RemoveProduct(ctx, storeID, productID.Hex())
+}
+
+func (s *Service) FindNearbyStores(ctx context.Context, query *store.NearbyQuery, page, limit int) ([]*store.NearbyStore, int, error) {
+	return s.repo.FindNearby(ctx, query, page, limit)
 }
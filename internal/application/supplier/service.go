@@ -0,0 +1,111 @@
+package supplier
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service struct {
+	repo     supplier.Repository
+	products product.Repository
+}
+
+func NewService(repo supplier.Repository, products product.Repository) *Service {
+	return &Service{
+		repo:     repo,
+		products: products,
+	}
+}
+
+// Details holds the fields of a supplier that can be set by clients
+type Details struct {
+	Name         string
+	Contact      supplier.Contact
+	LeadTimeDays int
+}
+
+func (s *Service) CreateSupplier(ctx context.Context, details Details) (*supplier.Supplier, error) {
+	sup, err := supplier.NewSupplier(details.Name, details.Contact, details.LeadTimeDays)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, sup); err != nil {
+		return nil, err
+	}
+	return sup, nil
+}
+
+func (s *Service) GetSupplier(ctx context.Context, id string) (*supplier.Supplier, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) UpdateSupplier(ctx context.Context, id string, details Details) (*supplier.Supplier, error) {
+	sup, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sup.UpdateDetails(details.Name, details.Contact, details.LeadTimeDays); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, sup); err != nil {
+		return nil, err
+	}
+	return sup, nil
+}
+
+func (s *Service) DeleteSupplier(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) ListSuppliers(ctx context.Context, page, limit int) ([]*supplier.Supplier, int, error) {
+	return s.repo.List(ctx, page, limit)
+}
+
+// SetProduct records the cost price at which the supplier sells a product
+func (s *Service) SetProduct(ctx context.Context, id, productID string, costPrice float64) (*supplier.Supplier, error) {
+	sup, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sup.SetProduct(p.ID, costPrice); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, sup); err != nil {
+		return nil, err
+	}
+	return sup, nil
+}
+
+func (s *Service) RemoveProduct(ctx context.Context, id, productID string) (*supplier.Supplier, error) {
+	sup, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sup.RemoveProduct(objectID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, sup); err != nil {
+		return nil, err
+	}
+	return sup, nil
+}
@@ -0,0 +1,57 @@
+package tax
+
+import (
+	"context"
+
+	"github.com/stasshander/ddd/internal/domain/tax"
+)
+
+type Service struct {
+	repo tax.Repository
+}
+
+func NewService(repo tax.Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+func (s *Service) CreateRateTable(ctx context.Context, country, region string, rates map[string]float64, pricesIncludeTax bool) (*tax.RateTable, error) {
+	table, err := tax.NewRateTable(country, region, rates, pricesIncludeTax)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (s *Service) GetRateTable(ctx context.Context, id string) (*tax.RateTable, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) UpdateRateTable(ctx context.Context, id string, rates map[string]float64, pricesIncludeTax bool) (*tax.RateTable, error) {
+	table, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := table.UpdateRates(rates, pricesIncludeTax); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, table); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (s *Service) DeleteRateTable(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) ListRateTables(ctx context.Context) ([]*tax.RateTable, error) {
+	return s.repo.List(ctx)
+}
@@ -0,0 +1,12 @@
+package transaction
+
+import "context"
+
+// Runner runs a unit of work atomically: either every change fn makes
+// through repositories called with the context it is given is stored, or
+// none is. fn may be run more than once when the transaction has to be
+// retried, so it must not have other side effects. Running a unit of work
+// inside another joins the outer one.
+type Runner interface {
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
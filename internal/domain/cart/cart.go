@@ -0,0 +1,204 @@
+package cart
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCartNotFound      = errors.New("cart not found")
+	ErrLineNotFound      = errors.New("product is not in the cart")
+	ErrInvalidQuantity   = errors.New("quantity must be greater than 0")
+	ErrEmptyCart         = errors.New("cart is empty")
+	ErrProductNotInStore = errors.New("product is not sold in this store")
+	ErrUnavailableItems  = errors.New("cart contains products that are no longer available")
+	ErrPricesChanged     = errors.New("prices have changed since the cart was last viewed")
+	ErrInvalidTTL        = errors.New("cart lifetime must be positive")
+)
+
+const (
+	unavailableNotFound   = "product no longer exists"
+	unavailableNotInStore = "product is no longer sold in this store"
+)
+
+// Line is a product in the cart. UnitPrice is the price the customer was last
+// shown, so a later re-price can tell them what changed.
+type Line struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+	UnitPrice float64            `bson:"unit_price" json:"unit_price"`
+}
+
+// Cart is a customer's basket for a single store. Its ID is an opaque random
+// token so that clients can hold on to it without authenticating.
+type Cart struct {
+	ID        string             `bson:"_id" json:"id"`
+	StoreID   primitive.ObjectID `bson:"store_id" json:"store_id"`
+	Lines     []Line             `bson:"lines" json:"lines"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// NewCart creates an empty cart that expires after ttl without activity
+func NewCart(storeID primitive.ObjectID, ttl time.Duration) (*Cart, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Cart{
+		ID:        id,
+		StoreID:   storeID,
+		Lines:     []Line{},
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// SetLine puts quantity units of p in the cart, replacing any quantity
+// already there
+func (c *Cart) SetLine(p *product.Product, quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	for i := range c.Lines {
+		if c.Lines[i].ProductID == p.ID {
+			c.Lines[i].Quantity = quantity
+			c.Lines[i].UnitPrice = p.Price
+			c.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+
+	c.Lines = append(c.Lines, Line{ProductID: p.ID, Quantity: quantity, UnitPrice: p.Price})
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *Cart) RemoveLine(productID primitive.ObjectID) error {
+	for i := range c.Lines {
+		if c.Lines[i].ProductID == productID {
+			c.Lines = append(c.Lines[:i], c.Lines[i+1:]...)
+			c.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return ErrLineNotFound
+}
+
+// Touch pushes the expiry back so that carts in use are not removed
+func (c *Cart) Touch(ttl time.Duration) {
+	c.ExpiresAt = time.Now().Add(ttl)
+}
+
+// ProductIDs returns the products in the cart in line order
+func (c *Cart) ProductIDs() []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(c.Lines))
+	for _, line := range c.Lines {
+		ids = append(ids, line.ProductID)
+	}
+	return ids
+}
+
+// PricedLine is a cart line priced against the current catalog
+type PricedLine struct {
+	ProductID     primitive.ObjectID `json:"product_id"`
+	Name          string             `json:"name,omitempty"`
+	Quantity      int                `json:"quantity"`
+	UnitPrice     float64            `json:"unit_price"`
+	LineTotal     float64            `json:"line_total"`
+	PreviousPrice *float64           `json:"previous_price,omitempty"`
+	Available     bool               `json:"available"`
+	Reason        string             `json:"reason,omitempty"`
+}
+
+// Priced is the view of a cart returned to clients
+type Priced struct {
+	ID           string             `json:"id"`
+	StoreID      primitive.ObjectID `json:"store_id"`
+	Lines        []PricedLine       `json:"lines"`
+	Total        float64            `json:"total"`
+	PriceChanged bool               `json:"price_changed"`
+	Unavailable  bool               `json:"unavailable"`
+	ExpiresAt    time.Time          `json:"expires_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// Reprice prices every line at the current catalog price. Products that no
+// longer exist or that the store no longer carries are flagged unavailable
+// and left out of the total. Lines whose price moved report the price the
+// customer last saw, and the cart remembers the new price from now on.
+func (c *Cart) Reprice(catalog map[primitive.ObjectID]*product.Product, carried map[primitive.ObjectID]bool) *Priced {
+	priced := &Priced{
+		ID:        c.ID,
+		StoreID:   c.StoreID,
+		Lines:     make([]PricedLine, 0, len(c.Lines)),
+		ExpiresAt: c.ExpiresAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+
+	var total float64
+	for i := range c.Lines {
+		line := &c.Lines[i]
+		pl := PricedLine{
+			ProductID: line.ProductID,
+			Quantity:  line.Quantity,
+			UnitPrice: line.UnitPrice,
+		}
+
+		p, ok := catalog[line.ProductID]
+		switch {
+		case !ok:
+			pl.Reason = unavailableNotFound
+		case !carried[line.ProductID]:
+			pl.Name = p.Name
+			pl.Reason = unavailableNotInStore
+		default:
+			pl.Name = p.Name
+			pl.Available = true
+			if p.Price != line.UnitPrice {
+				previous := line.UnitPrice
+				pl.PreviousPrice = &previous
+				pl.UnitPrice = p.Price
+				line.UnitPrice = p.Price
+				priced.PriceChanged = true
+			}
+			pl.LineTotal = roundCents(pl.UnitPrice * float64(pl.Quantity))
+			total += pl.LineTotal
+		}
+
+		if !pl.Available {
+			priced.Unavailable = true
+		}
+		priced.Lines = append(priced.Lines, pl)
+	}
+	priced.Total = roundCents(total)
+
+	return priced
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
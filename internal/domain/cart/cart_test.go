@@ -0,0 +1,80 @@
+package cart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newProduct(t *testing.T, name string, price float64) *product.Product {
+	p, err := product.NewProduct(name, name+" description", price)
+	assert.NoError(t, err)
+	p.ID = primitive.NewObjectID()
+	return p
+}
+
+func TestNewCart(t *testing.T) {
+	c, err := NewCart(primitive.NewObjectID(), time.Hour)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, c.ID)
+	assert.Empty(t, c.Lines)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), c.ExpiresAt, time.Second)
+
+	other, err := NewCart(primitive.NewObjectID(), time.Hour)
+	assert.NoError(t, err)
+	assert.NotEqual(t, c.ID, other.ID)
+
+	_, err = NewCart(primitive.NewObjectID(), 0)
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+}
+
+func TestCartLines(t *testing.T) {
+	c, err := NewCart(primitive.NewObjectID(), time.Hour)
+	assert.NoError(t, err)
+	coffee := newProduct(t, "Coffee", 4.99)
+
+	assert.ErrorIs(t, c.SetLine(coffee, 0), ErrInvalidQuantity)
+
+	assert.NoError(t, c.SetLine(coffee, 2))
+	assert.NoError(t, c.SetLine(coffee, 5))
+	assert.Len(t, c.Lines, 1)
+	assert.Equal(t, 5, c.Lines[0].Quantity)
+
+	assert.NoError(t, c.RemoveLine(coffee.ID))
+	assert.Empty(t, c.Lines)
+	assert.ErrorIs(t, c.RemoveLine(coffee.ID), ErrLineNotFound)
+}
+
+func TestCartReprice(t *testing.T) {
+	c, err := NewCart(primitive.NewObjectID(), time.Hour)
+	assert.NoError(t, err)
+	coffee := newProduct(t, "Coffee", 4.99)
+	tea := newProduct(t, "Tea", 2.50)
+	sugar := newProduct(t, "Sugar", 1.00)
+	assert.NoError(t, c.SetLine(coffee, 2))
+	assert.NoError(t, c.SetLine(tea, 1))
+	assert.NoError(t, c.SetLine(sugar, 3))
+
+	assert.NoError(t, coffee.UpdatePrice(5.49))
+	catalog := map[primitive.ObjectID]*product.Product{coffee.ID: coffee, tea.ID: tea}
+	carried := map[primitive.ObjectID]bool{coffee.ID: true}
+
+	priced := c.Reprice(catalog, carried)
+	assert.True(t, priced.PriceChanged)
+	assert.True(t, priced.Unavailable)
+	assert.Equal(t, 10.98, priced.Total)
+
+	assert.Equal(t, 5.49, priced.Lines[0].UnitPrice)
+	assert.Equal(t, 4.99, *priced.Lines[0].PreviousPrice)
+	assert.False(t, priced.Lines[1].Available)
+	assert.Equal(t, unavailableNotInStore, priced.Lines[1].Reason)
+	assert.False(t, priced.Lines[2].Available)
+	assert.Equal(t, unavailableNotFound, priced.Lines[2].Reason)
+
+	again := c.Reprice(catalog, carried)
+	assert.False(t, again.PriceChanged)
+	assert.Nil(t, again.Lines[0].PreviousPrice)
+}
@@ -0,0 +1,14 @@
+package cart
+
+import (
+	"context"
+)
+
+// Repository stores carts. Carts past their expiry are treated as missing
+// even before the database has removed them.
+type Repository interface {
+	Create(ctx context.Context, cart *Cart) error
+	GetByID(ctx context.Context, id string) (*Cart, error)
+	Update(ctx context.Context, cart *Cart) error
+	Delete(ctx context.Context, id string) error
+}
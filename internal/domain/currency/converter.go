@@ -0,0 +1,36 @@
+package currency
+
+// Converter converts amounts from the base currency, in which catalog prices
+// are kept, into other currencies.
+type Converter struct {
+	base  string
+	rules map[string]RoundingRule
+}
+
+// NewConverter creates a converter from base. Currencies without an entry in
+// rules are rounded with DefaultRule.
+func NewConverter(base string, rules map[string]RoundingRule) *Converter {
+	return &Converter{
+		base:  base,
+		rules: rules,
+	}
+}
+
+// Base returns the base currency code
+func (c *Converter) Base() string {
+	return c.base
+}
+
+// Convert converts amount from the base currency at rate and rounds the
+// result according to the rule of the target currency.
+func (c *Converter) Convert(amount float64, rate *Rate) float64 {
+	return c.RuleFor(rate.Currency).Apply(amount * rate.Rate)
+}
+
+// RuleFor returns the rounding rule applied to a currency
+func (c *Converter) RuleFor(code string) RoundingRule {
+	if rule, ok := c.rules[code]; ok {
+		return rule
+	}
+	return DefaultRule(code)
+}
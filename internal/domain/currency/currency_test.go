@@ -0,0 +1,79 @@
+package currency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRate(t *testing.T) {
+	rate, err := NewRate(" chf", 0.94, "2026-10-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "CHF", rate.Currency)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), rate.EffectiveFrom)
+
+	_, err = NewRate("Swiss", 0.94, "2026-10-01")
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+
+	_, err = NewRate("CHF", 0, "2026-10-01")
+	assert.ErrorIs(t, err, ErrInvalidRate)
+
+	_, err = NewRate("CHF", 0.94, "01/10/2026")
+	assert.ErrorIs(t, err, ErrInvalidEffectiveDate)
+}
+
+func TestRoundingRuleApply(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rule   RoundingRule
+		amount float64
+		want   float64
+	}{
+		{name: "cents", rule: RoundingRule{Increment: 0.01, Mode: RoundNearest}, amount: 10.345, want: 10.35},
+		{name: "five cents", rule: RoundingRule{Increment: 0.05, Mode: RoundNearest}, amount: 1.1499, want: 1.15},
+		{name: "exact multiple stays", rule: RoundingRule{Increment: 0.05, Mode: RoundUp}, amount: 1.15, want: 1.15},
+		{name: "whole units up", rule: RoundingRule{Increment: 1, Mode: RoundUp}, amount: 99.01, want: 100},
+		{name: "tens down", rule: RoundingRule{Increment: 10, Mode: RoundDown}, amount: 1299, want: 1290},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.Apply(tc.amount))
+		})
+	}
+}
+
+func TestParseRoundingRules(t *testing.T) {
+	rules, err := ParseRoundingRules("chf=0.05, SEK=1:up,JPY=10:down")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]RoundingRule{
+		"CHF": {Increment: 0.05, Mode: RoundNearest},
+		"SEK": {Increment: 1, Mode: RoundUp},
+		"JPY": {Increment: 10, Mode: RoundDown},
+	}, rules)
+
+	rules, err = ParseRoundingRules("")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for _, invalid := range []string{"CHF", "CHF=abc", "CHF=0", "CHF=0.05:sideways", "SWISS=0.05"} {
+		_, err := ParseRoundingRules(invalid)
+		assert.ErrorIs(t, err, ErrInvalidRoundingRule, invalid)
+	}
+}
+
+func TestConverter(t *testing.T) {
+	converter := NewConverter("EUR", map[string]RoundingRule{
+		"CHF": {Increment: 0.05, Mode: RoundNearest},
+	})
+
+	chf, _ := NewRate("CHF", 0.9412, "2026-10-01")
+	jpy, _ := NewRate("JPY", 162.37, "2026-10-01")
+	usd, _ := NewRate("USD", 1.0873, "2026-10-01")
+
+	assert.Equal(t, "EUR", converter.Base())
+	assert.Equal(t, 18.8, converter.Convert(19.99, chf))
+	assert.Equal(t, 3246.0, converter.Convert(19.99, jpy))
+	assert.Equal(t, 21.74, converter.Convert(19.99, usd))
+}
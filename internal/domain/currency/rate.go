@@ -0,0 +1,64 @@
+package currency
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidCurrency      = errors.New("currency must be an ISO 4217 code")
+	ErrInvalidRate          = errors.New("exchange rate must be greater than 0")
+	ErrInvalidEffectiveDate = errors.New("effective date must be in YYYY-MM-DD format")
+	ErrRateNotFound         = errors.New("no exchange rate in effect for currency")
+	ErrInvalidRoundingRule  = errors.New("rounding rules must look like CODE=INCREMENT[:nearest|up|down]")
+)
+
+const dateLayout = "2006-01-02"
+
+// Rate is the number of units of Currency one unit of the base currency buys
+// from EffectiveFrom onwards, until a rate with a later date takes over.
+type Rate struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Currency      string             `bson:"currency" json:"currency"`
+	Rate          float64            `bson:"rate" json:"rate"`
+	EffectiveFrom time.Time          `bson:"effective_from" json:"effective_from"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// NewRate creates an exchange rate effective from the start of date, a
+// YYYY-MM-DD date in UTC.
+func NewRate(code string, rate float64, date string) (*Rate, error) {
+	code, err := ParseCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if rate <= 0 {
+		return nil, ErrInvalidRate
+	}
+
+	effectiveFrom, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return nil, ErrInvalidEffectiveDate
+	}
+
+	return &Rate{
+		ID:            primitive.NewObjectID(),
+		Currency:      code,
+		Rate:          rate,
+		EffectiveFrom: effectiveFrom,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// ParseCode validates and upper-cases a three letter currency code
+func ParseCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
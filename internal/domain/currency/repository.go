@@ -0,0 +1,17 @@
+package currency
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Save stores rates, replacing any existing rate for the same currency
+	// and effective date.
+	Save(ctx context.Context, rates []*Rate) error
+	// List returns the rates of a currency, or of every currency if code is
+	// empty, newest first.
+	List(ctx context.Context, code string) ([]*Rate, error)
+	// FindEffective returns the rate of a currency in effect at the given time
+	FindEffective(ctx context.Context, code string, at time.Time) (*Rate, error)
+}
@@ -0,0 +1,100 @@
+package currency
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode decides which way converted amounts are rounded
+type RoundingMode string
+
+const (
+	RoundNearest RoundingMode = "nearest"
+	RoundUp      RoundingMode = "up"
+	RoundDown    RoundingMode = "down"
+)
+
+// RoundingRule rounds converted amounts to a multiple of Increment, such as
+// 0.01 for cents, 0.05 for Swiss cash prices or 1 for currencies without
+// minor units.
+type RoundingRule struct {
+	Increment float64
+	Mode      RoundingMode
+}
+
+// zeroDecimalCurrencies have no minor unit in everyday use
+var zeroDecimalCurrencies = map[string]bool{
+	"CLP": true,
+	"ISK": true,
+	"JPY": true,
+	"KRW": true,
+	"PYG": true,
+	"UGX": true,
+	"VND": true,
+}
+
+// DefaultRule rounds to the nearest minor unit of the currency
+func DefaultRule(code string) RoundingRule {
+	if zeroDecimalCurrencies[code] {
+		return RoundingRule{Increment: 1, Mode: RoundNearest}
+	}
+	return RoundingRule{Increment: 0.01, Mode: RoundNearest}
+}
+
+// Apply rounds amount according to the rule
+func (r RoundingRule) Apply(amount float64) float64 {
+	// Snap to a millionth of a step first so that amounts like 1.15 / 0.05,
+	// which is 22.999999999999996 in floating point, round as expected.
+	steps := math.Round(amount/r.Increment*1e6) / 1e6
+	switch r.Mode {
+	case RoundUp:
+		steps = math.Ceil(steps)
+	case RoundDown:
+		steps = math.Floor(steps)
+	default:
+		steps = math.Round(steps)
+	}
+	return math.Round(steps*r.Increment*1e6) / 1e6
+}
+
+// ParseRoundingRules parses a comma separated list of per-currency rules such
+// as "CHF=0.05,JPY=10:down,SEK=1:up". The mode defaults to nearest.
+func ParseRoundingRules(s string) (map[string]RoundingRule, error) {
+	rules := make(map[string]RoundingRule)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		code, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, ErrInvalidRoundingRule
+		}
+		code, err := ParseCode(code)
+		if err != nil {
+			return nil, ErrInvalidRoundingRule
+		}
+
+		increment, mode, _ := strings.Cut(spec, ":")
+		rule := RoundingRule{Mode: RoundNearest}
+		rule.Increment, err = strconv.ParseFloat(strings.TrimSpace(increment), 64)
+		if err != nil || rule.Increment <= 0 {
+			return nil, ErrInvalidRoundingRule
+		}
+
+		switch RoundingMode(strings.TrimSpace(mode)) {
+		case "", RoundNearest:
+		case RoundUp:
+			rule.Mode = RoundUp
+		case RoundDown:
+			rule.Mode = RoundDown
+		default:
+			return nil, ErrInvalidRoundingRule
+		}
+
+		rules[code] = rule
+	}
+	return rules, nil
+}
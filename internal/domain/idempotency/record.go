@@ -0,0 +1,88 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRecordNotFound   = errors.New("idempotency record not found")
+	ErrKeyExists        = errors.New("idempotency key already used")
+	ErrKeyMismatch      = errors.New("idempotency key was already used with a different request")
+	ErrKeyInProgress    = errors.New("a request with this idempotency key is still being processed")
+	ErrInvalidKey       = errors.New("idempotency key must be between 1 and 255 characters")
+	ErrConcurrentUpdate = errors.New("idempotency record was modified concurrently")
+)
+
+// MaxKeyLength is the longest accepted idempotency key
+const MaxKeyLength = 255
+
+// Record remembers the first request made with an idempotency key and, once
+// it has finished, its response. Fingerprint identifies the request by its
+// method, path and body. A record that is not completed is locked by the
+// request processing it until LockedUntil, after which a retry may take it
+// over.
+type Record struct {
+	Key         string    `bson:"_id"`
+	Fingerprint string    `bson:"fingerprint"`
+	Completed   bool      `bson:"completed"`
+	Status      int       `bson:"status,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	LockedUntil time.Time `bson:"locked_until"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+func ValidateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLength {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// NewRecord starts a record for a request being processed. It is kept for
+// ttl and locked for lockTimeout.
+func NewRecord(key, fingerprint string, ttl, lockTimeout time.Duration) (*Record, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	// Stored times have millisecond precision, and the lock time identifies
+	// the owner of the record, so it must survive a round trip unchanged
+	now := time.Now().Truncate(time.Millisecond)
+	return &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedUntil: now.Add(lockTimeout),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}, nil
+}
+
+// Check compares a repeated request with the record. It returns the record's
+// response to replay, or an error if the request differs or the first one
+// has not finished yet.
+func (r *Record) Check(fingerprint string) error {
+	if r.Fingerprint != fingerprint {
+		return ErrKeyMismatch
+	}
+	if !r.Completed {
+		return ErrKeyInProgress
+	}
+	return nil
+}
+
+// Abandoned reports whether the request processing an incomplete record has
+// held its lock past the timeout at t
+func (r *Record) Abandoned(t time.Time) bool {
+	return !r.Completed && t.After(r.LockedUntil)
+}
+
+// Complete stores the response of the request
+func (r *Record) Complete(status int, contentType string, body []byte) {
+	r.Completed = true
+	r.Status = status
+	r.ContentType = contentType
+	r.Body = body
+}
@@ -0,0 +1,59 @@
+package idempotency
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecord(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{name: "valid key", key: "order-42"},
+		{name: "empty key", key: "", wantErr: ErrInvalidKey},
+		{name: "key too long", key: strings.Repeat("k", MaxKeyLength+1), wantErr: ErrInvalidKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			record, err := NewRecord(tc.key, "fp", time.Hour, time.Minute)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, record)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, record.Completed)
+			assert.Equal(t, time.Hour, record.ExpiresAt.Sub(record.CreatedAt))
+			assert.Equal(t, time.Minute, record.LockedUntil.Sub(record.CreatedAt))
+		})
+	}
+}
+
+func TestRecordCheck(t *testing.T) {
+	record, err := NewRecord("order-42", "fp", time.Hour, time.Minute)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, record.Check("other"), ErrKeyMismatch)
+	assert.ErrorIs(t, record.Check("fp"), ErrKeyInProgress)
+
+	record.Complete(201, "application/json", []byte(`{"id":1}`))
+	assert.NoError(t, record.Check("fp"))
+	assert.ErrorIs(t, record.Check("other"), ErrKeyMismatch)
+}
+
+func TestRecordAbandoned(t *testing.T) {
+	record, err := NewRecord("order-42", "fp", time.Hour, time.Minute)
+	assert.NoError(t, err)
+
+	assert.False(t, record.Abandoned(time.Now()))
+	assert.True(t, record.Abandoned(time.Now().Add(2*time.Minute)))
+
+	record.Complete(201, "application/json", nil)
+	assert.False(t, record.Abandoned(time.Now().Add(2*time.Minute)))
+}
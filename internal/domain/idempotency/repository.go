@@ -0,0 +1,21 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Create stores a new record, failing with ErrKeyExists if the key is
+	// in use by a record that has not expired
+	Create(ctx context.Context, record *Record) error
+	GetByKey(ctx context.Context, key string) (*Record, error)
+	// TakeOver replaces an abandoned record, provided it is still locked
+	// until lockedUntil, failing with ErrConcurrentUpdate otherwise
+	TakeOver(ctx context.Context, record *Record, lockedUntil time.Time) error
+	// Complete stores the response of a record's request. Complete and
+	// Release fail with ErrConcurrentUpdate once the record was taken over.
+	Complete(ctx context.Context, record *Record) error
+	// Release removes an incomplete record so that the key can be retried
+	Release(ctx context.Context, record *Record) error
+}
@@ -0,0 +1,203 @@
+package importjob
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrJobNotFound    = errors.New("import job not found")
+	ErrInvalidKind    = errors.New("import kind must be products or assortments")
+	ErrInvalidFormat  = errors.New("import format must be csv or ndjson")
+	ErrEmptyImport    = errors.New("import file has no rows")
+	ErrMissingColumns = errors.New("import file is missing required columns")
+	ErrJobNotRunning  = errors.New("import job is not running")
+	ErrLeaseLost      = errors.New("import job was claimed by another worker")
+)
+
+// Kind is what an import file holds
+type Kind string
+
+const (
+	// KindProducts rows create products: name, description, price and
+	// optionally category and tax_class
+	KindProducts Kind = "products"
+	// KindAssortments rows add a product to a store: store_id and product_id
+	KindAssortments Kind = "assortments"
+)
+
+// Columns returns the columns every row of the kind must have
+func (k Kind) Columns() []string {
+	if k == KindAssortments {
+		return []string{"store_id", "product_id"}
+	}
+	return []string{"name", "description", "price"}
+}
+
+func ParseKind(s string) (Kind, error) {
+	switch kind := Kind(s); kind {
+	case KindProducts, KindAssortments:
+		return kind, nil
+	}
+	return "", ErrInvalidKind
+}
+
+// Format is the encoding of an import file
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatCSV, FormatNDJSON:
+		return format, nil
+	}
+	return "", ErrInvalidFormat
+}
+
+// Status is the lifecycle state of an import job
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// MaxStoredRejections is the number of rejected rows kept with a job. Rows
+// rejected beyond it are only counted.
+const MaxStoredRejections = 1000
+
+// MaxRejectionRawLength is the longest original line kept with a rejection,
+// in bytes
+const MaxRejectionRawLength = 1024
+
+// Rejection is a row that was not imported. Row is the line number in the
+// file, counting a CSV header as line 1.
+type Rejection struct {
+	Row   int    `bson:"row" json:"row"`
+	Error string `bson:"error" json:"error"`
+	Raw   string `bson:"raw" json:"raw"`
+}
+
+// Job is an import of a file, run in the background. A dry run validates
+// every row without writing anything; its imported rows are the rows that
+// would have been imported. Payload holds the uploaded file, which is stored
+// apart from the job until the job finishes. A running job is leased to the
+// worker that claimed it at ClaimedAt; once LeaseUntil passes without
+// progress, another worker may claim it and carry on.
+type Job struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind          Kind               `bson:"kind" json:"kind"`
+	Format        Format             `bson:"format" json:"format"`
+	DryRun        bool               `bson:"dry_run" json:"dry_run"`
+	Status        Status             `bson:"status" json:"status"`
+	TotalRows     int                `bson:"total_rows" json:"total_rows"`
+	ProcessedRows int                `bson:"processed_rows" json:"processed_rows"`
+	ImportedRows  int                `bson:"imported_rows" json:"imported_rows"`
+	RejectedRows  int                `bson:"rejected_rows" json:"rejected_rows"`
+	Rejections    []Rejection        `bson:"rejections" json:"-"`
+	Error         string             `bson:"error,omitempty" json:"error,omitempty"`
+	Payload       []byte             `bson:"-" json:"-"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	StartedAt     *time.Time         `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	ClaimedAt     *time.Time         `bson:"claimed_at,omitempty" json:"-"`
+	LeaseUntil    *time.Time         `bson:"lease_until,omitempty" json:"-"`
+	FinishedAt    *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+func NewJob(kind Kind, format Format, dryRun bool, totalRows int, payload []byte) (*Job, error) {
+	if _, err := ParseKind(string(kind)); err != nil {
+		return nil, err
+	}
+	if _, err := ParseFormat(string(format)); err != nil {
+		return nil, err
+	}
+	if totalRows == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	now := time.Now()
+	return &Job{
+		ID:         primitive.NewObjectID(),
+		Kind:       kind,
+		Format:     format,
+		DryRun:     dryRun,
+		Status:     StatusQueued,
+		TotalRows:  totalRows,
+		Rejections: make([]Rejection, 0),
+		Payload:    payload,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// Record counts a processed row, rejecting it when err is not nil. Only the
+// first MaxStoredRejections rejections are kept, with their lines cut to
+// MaxRejectionRawLength.
+func (j *Job) Record(row int, raw string, err error) error {
+	if j.Status != StatusRunning {
+		return ErrJobNotRunning
+	}
+
+	j.ProcessedRows++
+	if err != nil {
+		j.RejectedRows++
+		if len(j.Rejections) < MaxStoredRejections {
+			j.Rejections = append(j.Rejections, Rejection{Row: row, Error: err.Error(), Raw: truncate(raw, MaxRejectionRawLength)})
+		}
+	} else {
+		j.ImportedRows++
+	}
+	j.UpdatedAt = time.Now()
+	return nil
+}
+
+// RenewLease keeps the job leased to its worker for d from now
+func (j *Job) RenewLease(d time.Duration) {
+	until := time.Now().Add(d)
+	j.LeaseUntil = &until
+}
+
+// Complete finishes a job whose rows have all been processed
+func (j *Job) Complete() error {
+	return j.finish(StatusCompleted, "")
+}
+
+// Fail finishes a job that could not process its file
+func (j *Job) Fail(reason string) error {
+	return j.finish(StatusFailed, reason)
+}
+
+func (j *Job) finish(status Status, reason string) error {
+	if j.Status != StatusRunning {
+		return ErrJobNotRunning
+	}
+
+	now := time.Now()
+	j.Status = status
+	j.Error = reason
+	j.Payload = nil
+	j.FinishedAt = &now
+	j.UpdatedAt = now
+	return nil
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
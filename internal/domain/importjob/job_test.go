@@ -0,0 +1,81 @@
+package importjob
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJob(t *testing.T) {
+	testCases := []struct {
+		name      string
+		kind      Kind
+		format    Format
+		totalRows int
+		wantErr   error
+	}{
+		{name: "valid job", kind: KindProducts, format: FormatCSV, totalRows: 3},
+		{name: "unknown kind", kind: "prices", format: FormatCSV, totalRows: 3, wantErr: ErrInvalidKind},
+		{name: "unknown format", kind: KindAssortments, format: "xlsx", totalRows: 3, wantErr: ErrInvalidFormat},
+		{name: "no rows", kind: KindProducts, format: FormatNDJSON, totalRows: 0, wantErr: ErrEmptyImport},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job, err := NewJob(tc.kind, tc.format, false, tc.totalRows, []byte("data"))
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, job)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, StatusQueued, job.Status)
+			assert.Equal(t, tc.totalRows, job.TotalRows)
+		})
+	}
+}
+
+func TestJobLifecycle(t *testing.T) {
+	job, err := NewJob(KindProducts, FormatCSV, false, 2, []byte("data"))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, job.Record(2, "a", nil), ErrJobNotRunning)
+
+	job.Status = StatusRunning
+	assert.NoError(t, job.Record(2, "a", nil))
+	assert.NoError(t, job.Record(3, "b", errors.New("invalid price")))
+
+	assert.Equal(t, 2, job.ProcessedRows)
+	assert.Equal(t, 1, job.ImportedRows)
+	assert.Equal(t, 1, job.RejectedRows)
+	assert.Equal(t, []Rejection{{Row: 3, Error: "invalid price", Raw: "b"}}, job.Rejections)
+
+	assert.NoError(t, job.Complete())
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Nil(t, job.Payload)
+	assert.NotNil(t, job.FinishedAt)
+
+	assert.ErrorIs(t, job.Fail("late"), ErrJobNotRunning)
+}
+
+func TestRecordCapsRejections(t *testing.T) {
+	job, err := NewJob(KindProducts, FormatCSV, false, MaxStoredRejections+10, []byte("data"))
+	assert.NoError(t, err)
+	job.Status = StatusRunning
+
+	long := strings.Repeat("é", MaxRejectionRawLength)
+	for row := 2; row < MaxStoredRejections+12; row++ {
+		assert.NoError(t, job.Record(row, long, errors.New("invalid price")))
+	}
+
+	assert.Equal(t, MaxStoredRejections+10, job.RejectedRows)
+	assert.Len(t, job.Rejections, MaxStoredRejections)
+	assert.Equal(t, MaxStoredRejections+1, job.Rejections[MaxStoredRejections-1].Row)
+	raw := job.Rejections[0].Raw
+	assert.LessOrEqual(t, len(raw), MaxRejectionRawLength)
+	assert.True(t, utf8.ValidString(raw))
+	assert.True(t, strings.HasPrefix(long, raw))
+}
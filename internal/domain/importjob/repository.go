@@ -0,0 +1,24 @@
+package importjob
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Create stores a job along with its uploaded file
+	Create(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, id string) (*Job, error)
+	List(ctx context.Context, page, limit int) ([]*Job, int, error)
+	// ClaimNext marks the oldest job that is queued, or running under an
+	// expired lease, as running under a lease for lease from now, and returns
+	// it with its file. It returns nil if there is no such job. A job is
+	// claimed by one worker at a time.
+	ClaimNext(ctx context.Context, lease time.Duration) (*Job, error)
+	// SaveProgress stores the row counts, rejections and lease of a running
+	// job, failing with ErrLeaseLost if another worker has claimed it since
+	SaveProgress(ctx context.Context, job *Job) error
+	// Finish stores a finished job and drops its file, failing with
+	// ErrLeaseLost if another worker has claimed it since
+	Finish(ctx context.Context, job *Job) error
+}
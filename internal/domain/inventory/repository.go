@@ -0,0 +1,48 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repository stores stock levels. Reserve, Release and Commit change stock
+// atomically so that concurrent orders can never oversell a product.
+type Repository interface {
+	Get(ctx context.Context, storeID, productID primitive.ObjectID) (*StockItem, error)
+	ListByStore(ctx context.Context, storeID primitive.ObjectID, page, limit int) ([]*StockItem, int, error)
+	// SetOnHand records the stock on hand after a count, creating the item
+	// if needed. It fails with ErrStockBelowReserved rather than leaving
+	// reservations uncovered.
+	SetOnHand(ctx context.Context, storeID, productID primitive.ObjectID, onHand int) (*StockItem, error)
+	// SetReorderRule records the reorder point and quantity of an item,
+	// creating the item if needed
+	SetReorderRule(ctx context.Context, storeID, productID primitive.ObjectID, point, quantity int) (*StockItem, error)
+	// ListBelowReorderPoint returns every item, across all stores, whose
+	// available units have fallen to its reorder point
+	ListBelowReorderPoint(ctx context.Context) ([]*StockItem, error)
+	// Receive adds delivered units to the stock on hand, creating the item
+	// if needed
+	Receive(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error
+	// Reserve holds quantity units, failing with ErrInsufficientStock if
+	// fewer are available.
+	Reserve(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error
+	// Release returns reserved units to the available stock
+	Release(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error
+	// Commit removes reserved units from stock once they have left the store
+	Commit(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error
+}
+
+// ReservationRepository stores reservations. The stock they hold is kept in
+// Repository; callers change both.
+type ReservationRepository interface {
+	Create(ctx context.Context, reservation *Reservation) error
+	GetByID(ctx context.Context, id string) (*Reservation, error)
+	// UpdateStatus saves a status change, failing with ErrConcurrentUpdate if
+	// the stored reservation no longer has the status previous.
+	UpdateStatus(ctx context.Context, reservation *Reservation, previous ReservationStatus) error
+	// ListExpired returns up to limit active reservations that expired
+	// before t, oldest first.
+	ListExpired(ctx context.Context, t time.Time, limit int) ([]*Reservation, error)
+}
@@ -0,0 +1,111 @@
+package inventory
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationExpired   = errors.New("reservation has expired")
+	ErrReservationNotActive = errors.New("reservation is no longer active")
+	ErrConcurrentUpdate     = errors.New("reservation was modified concurrently")
+	ErrInvalidTTL           = errors.New("reservation lifetime must be positive")
+)
+
+// ReservationStatus is the lifecycle state of a reservation
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationCancelled ReservationStatus = "cancelled"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// Reservation holds units of a product in a store while a customer checks
+// out. An active reservation counts towards the reserved stock until it is
+// confirmed, cancelled or expires.
+type Reservation struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StoreID   primitive.ObjectID `bson:"store_id" json:"store_id"`
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+	Status    ReservationStatus  `bson:"status" json:"status"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+func NewReservation(storeID, productID primitive.ObjectID, quantity int, ttl time.Duration) (*Reservation, error) {
+	if err := ValidateQuantity(quantity); err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	now := time.Now()
+	return &Reservation{
+		ID:        primitive.NewObjectID(),
+		StoreID:   storeID,
+		ProductID: productID,
+		Quantity:  quantity,
+		Status:    ReservationActive,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// ExpiredAt reports whether the reservation's hold has lapsed at t
+func (r *Reservation) ExpiredAt(t time.Time) bool {
+	return !t.Before(r.ExpiresAt)
+}
+
+// Confirm turns the hold into a sale. It fails once the reservation has
+// expired, even if the sweeper has not released it yet.
+func (r *Reservation) Confirm() error {
+	if err := r.checkActive(); err != nil {
+		return err
+	}
+	if r.ExpiredAt(time.Now()) {
+		return ErrReservationExpired
+	}
+	r.setStatus(ReservationConfirmed)
+	return nil
+}
+
+func (r *Reservation) Cancel() error {
+	if err := r.checkActive(); err != nil {
+		return err
+	}
+	r.setStatus(ReservationCancelled)
+	return nil
+}
+
+// Expire marks a lapsed reservation as expired
+func (r *Reservation) Expire() error {
+	if err := r.checkActive(); err != nil {
+		return err
+	}
+	if !r.ExpiredAt(time.Now()) {
+		return ErrReservationNotActive
+	}
+	r.setStatus(ReservationExpired)
+	return nil
+}
+
+func (r *Reservation) checkActive() error {
+	if r.Status != ReservationActive {
+		return ErrReservationNotActive
+	}
+	return nil
+}
+
+func (r *Reservation) setStatus(status ReservationStatus) {
+	r.Status = status
+	r.UpdatedAt = time.Now()
+}
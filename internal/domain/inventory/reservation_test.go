@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewReservationValidation(t *testing.T) {
+	_, err := NewReservation(primitive.NewObjectID(), primitive.NewObjectID(), 0, time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidQuantity)
+
+	_, err = NewReservation(primitive.NewObjectID(), primitive.NewObjectID(), 1, 0)
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+
+	r, err := NewReservation(primitive.NewObjectID(), primitive.NewObjectID(), 2, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, ReservationActive, r.Status)
+	assert.False(t, r.ExpiredAt(time.Now()))
+}
+
+func TestReservationTransitions(t *testing.T) {
+	r, err := NewReservation(primitive.NewObjectID(), primitive.NewObjectID(), 1, time.Minute)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, r.Expire(), ErrReservationNotActive)
+	assert.NoError(t, r.Confirm())
+	assert.Equal(t, ReservationConfirmed, r.Status)
+	assert.ErrorIs(t, r.Cancel(), ErrReservationNotActive)
+
+	expired, err := NewReservation(primitive.NewObjectID(), primitive.NewObjectID(), 1, time.Minute)
+	assert.NoError(t, err)
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+
+	assert.ErrorIs(t, expired.Confirm(), ErrReservationExpired)
+	assert.NoError(t, expired.Expire())
+	assert.Equal(t, ReservationExpired, expired.Status)
+}
@@ -0,0 +1,68 @@
+package inventory
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrStockNotFound      = errors.New("no stock recorded for product in store")
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrInvalidQuantity    = errors.New("quantity must be greater than 0")
+	ErrInvalidStockLevel  = errors.New("stock on hand cannot be negative")
+	ErrStockBelowReserved = errors.New("stock on hand cannot drop below the reserved quantity")
+	ErrInvalidReorderRule = errors.New("reorder point cannot be negative and a reorder point needs a reorder quantity greater than 0")
+)
+
+// StockItem is the stock of one product in one store. Reserved units are
+// held for orders that have not been fulfilled yet and cannot be sold again.
+// When the available units fall to ReorderPoint, ReorderQuantity units
+// should be ordered; a zero ReorderPoint disables reordering.
+type StockItem struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StoreID         primitive.ObjectID `bson:"store_id" json:"store_id"`
+	ProductID       primitive.ObjectID `bson:"product_id" json:"product_id"`
+	OnHand          int                `bson:"on_hand" json:"on_hand"`
+	Reserved        int                `bson:"reserved" json:"reserved"`
+	ReorderPoint    int                `bson:"reorder_point" json:"reorder_point"`
+	ReorderQuantity int                `bson:"reorder_quantity" json:"reorder_quantity"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Available returns the units that can still be reserved
+func (s *StockItem) Available() int {
+	return s.OnHand - s.Reserved
+}
+
+// ValidateOnHand checks a stock level before it is recorded
+func ValidateOnHand(onHand int) error {
+	if onHand < 0 {
+		return ErrInvalidStockLevel
+	}
+	return nil
+}
+
+// ValidateQuantity checks the quantity of a reservation
+func ValidateQuantity(quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	return nil
+}
+
+// BelowReorderPoint reports whether the item needs reordering, counting
+// onOrder units that have been ordered but not yet delivered as available
+func (s *StockItem) BelowReorderPoint(onOrder int) bool {
+	return s.ReorderPoint > 0 && s.Available()+onOrder <= s.ReorderPoint
+}
+
+// ValidateReorderRule checks a reorder point and quantity before they are
+// recorded
+func ValidateReorderRule(point, quantity int) error {
+	if point < 0 || quantity < 0 || (point > 0 && quantity == 0) {
+		return ErrInvalidReorderRule
+	}
+	return nil
+}
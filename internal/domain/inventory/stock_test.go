@@ -0,0 +1,25 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReorderRule(t *testing.T) {
+	assert.NoError(t, ValidateReorderRule(0, 0))
+	assert.NoError(t, ValidateReorderRule(5, 20))
+	assert.ErrorIs(t, ValidateReorderRule(-1, 20), ErrInvalidReorderRule)
+	assert.ErrorIs(t, ValidateReorderRule(5, 0), ErrInvalidReorderRule)
+}
+
+func TestBelowReorderPoint(t *testing.T) {
+	item := &StockItem{OnHand: 8, Reserved: 3, ReorderPoint: 5, ReorderQuantity: 10}
+	assert.True(t, item.BelowReorderPoint(0))
+	assert.False(t, item.BelowReorderPoint(1))
+
+	item.ReorderPoint = 0
+	item.OnHand = 0
+	item.Reserved = 0
+	assert.False(t, item.BelowReorderPoint(0))
+}
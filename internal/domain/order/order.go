@@ -0,0 +1,121 @@
+package order
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrEmptyOrder        = errors.New("order must have at least one line item")
+	ErrInvalidQuantity   = errors.New("line item quantity must be greater than 0")
+	ErrDuplicateLine     = errors.New("product appears in more than one line item")
+	ErrConcurrentUpdate  = errors.New("order was modified concurrently")
+	ErrStoreNotOpen      = errors.New("store is not open for orders")
+	ErrProductNotInStore = errors.New("product is not sold in this store")
+)
+
+// LineItem is one product on an order. Name and UnitPrice are copied from the
+// product when the order is placed, so later catalog changes do not alter
+// existing orders.
+type LineItem struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Name      string             `bson:"name" json:"name"`
+	UnitPrice float64            `bson:"unit_price" json:"unit_price"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+	LineTotal float64            `bson:"line_total" json:"line_total"`
+}
+
+// NewLineItem snapshots the product's current name and price
+func NewLineItem(p *product.Product, quantity int) (LineItem, error) {
+	if quantity <= 0 {
+		return LineItem{}, ErrInvalidQuantity
+	}
+	return LineItem{
+		ProductID: p.ID,
+		Name:      p.Name,
+		UnitPrice: p.Price,
+		Quantity:  quantity,
+		LineTotal: roundCents(p.Price * float64(quantity)),
+	}, nil
+}
+
+// Order is a customer purchase from a single store
+type Order struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StoreID     primitive.ObjectID `bson:"store_id" json:"store_id"`
+	Lines       []LineItem         `bson:"lines" json:"lines"`
+	Total       float64            `bson:"total" json:"total"`
+	Status      Status             `bson:"status" json:"status"`
+	PlacedAt    time.Time          `bson:"placed_at" json:"placed_at"`
+	PaidAt      *time.Time         `bson:"paid_at,omitempty" json:"paid_at,omitempty"`
+	FulfilledAt *time.Time         `bson:"fulfilled_at,omitempty" json:"fulfilled_at,omitempty"`
+	CancelledAt *time.Time         `bson:"cancelled_at,omitempty" json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+func NewOrder(storeID primitive.ObjectID, lines []LineItem) (*Order, error) {
+	if len(lines) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(lines))
+	var total float64
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if seen[line.ProductID] {
+			return nil, ErrDuplicateLine
+		}
+		seen[line.ProductID] = true
+		total += line.LineTotal
+	}
+
+	now := time.Now()
+	return &Order{
+		ID:        primitive.NewObjectID(),
+		StoreID:   storeID,
+		Lines:     append([]LineItem(nil), lines...),
+		Total:     roundCents(total),
+		Status:    StatusPlaced,
+		PlacedAt:  now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Pay records payment of a placed order
+func (o *Order) Pay() error {
+	return o.transitionTo(StatusPaid, &o.PaidAt)
+}
+
+// Fulfil records that a paid order has been handed over to the customer
+func (o *Order) Fulfil() error {
+	return o.transitionTo(StatusFulfilled, &o.FulfilledAt)
+}
+
+// Cancel cancels an order that has not been fulfilled
+func (o *Order) Cancel() error {
+	return o.transitionTo(StatusCancelled, &o.CancelledAt)
+}
+
+func (o *Order) transitionTo(next Status, at **time.Time) error {
+	if !o.Status.CanTransitionTo(next) {
+		return ErrInvalidStatusTransition
+	}
+	now := time.Now()
+	o.Status = next
+	*at = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
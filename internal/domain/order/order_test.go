@@ -0,0 +1,77 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewOrderSnapshotsPrices(t *testing.T) {
+	p, err := product.NewProduct("Coffee", "Ground coffee", 4.99)
+	assert.NoError(t, err)
+
+	line, err := NewLineItem(p, 3)
+	assert.NoError(t, err)
+
+	o, err := NewOrder(primitive.NewObjectID(), []LineItem{line})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPlaced, o.Status)
+	assert.Equal(t, 14.97, o.Total)
+
+	assert.NoError(t, p.UpdatePrice(5.49))
+	assert.Equal(t, 4.99, o.Lines[0].UnitPrice)
+	assert.Equal(t, 14.97, o.Total)
+}
+
+func TestNewOrderValidation(t *testing.T) {
+	p, err := product.NewProduct("Coffee", "Ground coffee", 4.99)
+	assert.NoError(t, err)
+	line, err := NewLineItem(p, 1)
+	assert.NoError(t, err)
+
+	_, err = NewLineItem(p, 0)
+	assert.ErrorIs(t, err, ErrInvalidQuantity)
+
+	_, err = NewOrder(primitive.NewObjectID(), nil)
+	assert.ErrorIs(t, err, ErrEmptyOrder)
+
+	_, err = NewOrder(primitive.NewObjectID(), []LineItem{line, line})
+	assert.ErrorIs(t, err, ErrDuplicateLine)
+}
+
+func TestOrderTransitions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		steps   []func(*Order) error
+		want    Status
+		wantErr error
+	}{
+		{name: "pay then fulfil", steps: []func(*Order) error{(*Order).Pay, (*Order).Fulfil}, want: StatusFulfilled},
+		{name: "cancel placed", steps: []func(*Order) error{(*Order).Cancel}, want: StatusCancelled},
+		{name: "cancel paid", steps: []func(*Order) error{(*Order).Pay, (*Order).Cancel}, want: StatusCancelled},
+		{name: "fulfil unpaid", steps: []func(*Order) error{(*Order).Fulfil}, want: StatusPlaced, wantErr: ErrInvalidStatusTransition},
+		{name: "cancel fulfilled", steps: []func(*Order) error{(*Order).Pay, (*Order).Fulfil, (*Order).Cancel}, want: StatusFulfilled, wantErr: ErrInvalidStatusTransition},
+		{name: "pay cancelled", steps: []func(*Order) error{(*Order).Cancel, (*Order).Pay}, want: StatusCancelled, wantErr: ErrInvalidStatusTransition},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, _ := product.NewProduct("Coffee", "Ground coffee", 4.99)
+			line, _ := NewLineItem(p, 1)
+			o, err := NewOrder(primitive.NewObjectID(), []LineItem{line})
+			assert.NoError(t, err)
+
+			for _, step := range tc.steps {
+				err = step(o)
+			}
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.want, o.Status)
+		})
+	}
+}
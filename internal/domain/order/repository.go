@@ -0,0 +1,22 @@
+package order
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListFilter narrows order listings. Zero values match every order.
+type ListFilter struct {
+	StoreID *primitive.ObjectID
+	Status  Status
+}
+
+type Repository interface {
+	Create(ctx context.Context, order *Order) error
+	GetByID(ctx context.Context, id string) (*Order, error)
+	// UpdateStatus saves a status change, failing with ErrConcurrentUpdate if
+	// the stored order no longer has the status previous.
+	UpdateStatus(ctx context.Context, order *Order, previous Status) error
+	List(ctx context.Context, filter ListFilter, page, limit int) ([]*Order, int, error)
+}
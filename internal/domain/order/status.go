@@ -0,0 +1,43 @@
+package order
+
+import "errors"
+
+var (
+	ErrInvalidStatus           = errors.New("status must be one of placed, paid, fulfilled or cancelled")
+	ErrInvalidStatusTransition = errors.New("order status transition not allowed")
+)
+
+// Status is the lifecycle state of an order
+type Status string
+
+const (
+	StatusPlaced    Status = "placed"
+	StatusPaid      Status = "paid"
+	StatusFulfilled Status = "fulfilled"
+	StatusCancelled Status = "cancelled"
+)
+
+// transitions lists the states each status may move to. Fulfilled and
+// cancelled orders are final.
+var transitions = map[Status][]Status{
+	StatusPlaced: {StatusPaid, StatusCancelled},
+	StatusPaid:   {StatusFulfilled, StatusCancelled},
+}
+
+func ParseStatus(s string) (Status, error) {
+	switch Status(s) {
+	case StatusPlaced, StatusPaid, StatusFulfilled, StatusCancelled:
+		return Status(s), nil
+	}
+	return "", ErrInvalidStatus
+}
+
+// CanTransitionTo reports whether an order may move from s to next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
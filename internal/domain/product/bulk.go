@@ -0,0 +1,33 @@
+package product
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// MaxBulkSize is the most operations a single bulk request may hold
+const MaxBulkSize = 1000
+
+// BulkAction is the kind of write a bulk operation performs
+type BulkAction string
+
+const (
+	BulkCreate BulkAction = "create"
+	BulkUpdate BulkAction = "update"
+	BulkDelete BulkAction = "delete"
+)
+
+// ParseBulkAction validates a bulk action name
+func ParseBulkAction(s string) (BulkAction, error) {
+	switch action := BulkAction(s); action {
+	case BulkCreate, BulkUpdate, BulkDelete:
+		return action, nil
+	}
+	return "", ErrInvalidBulkAction
+}
+
+// BulkWrite is a validated write ready to be stored. Product is the product
+// to insert for a create and the changed product for an update; a delete only
+// needs ProductID.
+type BulkWrite struct {
+	Action    BulkAction
+	ProductID primitive.ObjectID
+	Product   *Product
+}
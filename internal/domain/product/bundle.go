@@ -0,0 +1,224 @@
+package product
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BundleComponent is a product contained in a bundle, Quantity times
+type BundleComponent struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+}
+
+// Bundle makes a product a kit sold as a unit, such as a gift set. Components
+// may themselves be bundles as long as no bundle contains itself. Unless
+// PriceOverridden is set, the product price is derived from the components.
+type Bundle struct {
+	Components      []BundleComponent `bson:"components" json:"components"`
+	PriceOverridden bool              `bson:"price_overridden" json:"price_overridden"`
+}
+
+// NewBundle creates a bundle product. catalog must hold every component.
+// When price is nil the bundle price is derived from its components.
+func NewBundle(name, description string, components []BundleComponent, price *float64, catalog map[primitive.ObjectID]*Product) (*Product, error) {
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+
+	if description == "" {
+		return nil, ErrInvalidDescription
+	}
+
+	if err := validateComponents(components, catalog); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	p := &Product{
+		ID:          primitive.NewObjectID(),
+		Name:        name,
+		Description: description,
+		Bundle: &Bundle{
+			Components: append([]BundleComponent(nil), components...),
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if price != nil {
+		if err := p.UpdatePrice(*price); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	if err := p.RecalculatePrice(catalog); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Product) IsBundle() bool {
+	return p.Bundle != nil
+}
+
+// UpdateComponents replaces the bundle's components. catalog must hold every
+// product reachable from the new components so that cycles can be detected.
+func (p *Product) UpdateComponents(components []BundleComponent, catalog map[primitive.ObjectID]*Product) error {
+	if !p.IsBundle() {
+		return ErrNotABundle
+	}
+
+	if err := validateComponents(components, catalog); err != nil {
+		return err
+	}
+
+	if err := checkCycle(p.ID, components, catalog); err != nil {
+		return err
+	}
+
+	p.Bundle.Components = append([]BundleComponent(nil), components...)
+	p.UpdatedAt = time.Now()
+	return p.RecalculatePrice(catalog)
+}
+
+// UseDerivedPrice drops a price override and derives the price from the
+// components again.
+func (p *Product) UseDerivedPrice(catalog map[primitive.ObjectID]*Product) error {
+	if !p.IsBundle() {
+		return ErrNotABundle
+	}
+
+	p.Bundle.PriceOverridden = false
+	p.UpdatedAt = time.Now()
+	return p.RecalculatePrice(catalog)
+}
+
+// RecalculatePrice sets a derived bundle price to the sum of its components'
+// prices. It does nothing for products that are not bundles or whose price
+// has been overridden.
+func (p *Product) RecalculatePrice(catalog map[primitive.ObjectID]*Product) error {
+	if !p.IsBundle() || p.Bundle.PriceOverridden {
+		return nil
+	}
+
+	var total float64
+	for _, c := range p.Bundle.Components {
+		component, ok := catalog[c.ProductID]
+		if !ok {
+			return ErrComponentNotFound
+		}
+		total += component.Price * float64(c.Quantity)
+	}
+
+	total = math.Round(total*100) / 100
+	if total <= 0 {
+		return ErrInvalidPrice
+	}
+
+	p.Price = total
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// Contains reports whether id is a direct component of the bundle
+func (p *Product) Contains(id primitive.ObjectID) bool {
+	if !p.IsBundle() {
+		return false
+	}
+	for _, c := range p.Bundle.Components {
+		if c.ProductID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandComponents resolves nested bundles into the quantities of the plain
+// products a bundle is made of. catalog must hold every reachable product.
+func (p *Product) ExpandComponents(catalog map[primitive.ObjectID]*Product) (map[primitive.ObjectID]int, error) {
+	if !p.IsBundle() {
+		return nil, ErrNotABundle
+	}
+
+	leaves := make(map[primitive.ObjectID]int)
+	var expand func(components []BundleComponent, multiplier int, depth int) error
+	expand = func(components []BundleComponent, multiplier int, depth int) error {
+		if depth > len(catalog) {
+			return ErrCyclicBundle
+		}
+		for _, c := range components {
+			component, ok := catalog[c.ProductID]
+			if !ok {
+				return ErrComponentNotFound
+			}
+			if component.IsBundle() {
+				if err := expand(component.Bundle.Components, multiplier*c.Quantity, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			leaves[c.ProductID] += multiplier * c.Quantity
+		}
+		return nil
+	}
+
+	if err := expand(p.Bundle.Components, 1, 0); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+func validateComponents(components []BundleComponent, catalog map[primitive.ObjectID]*Product) error {
+	if len(components) == 0 {
+		return ErrEmptyBundle
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(components))
+	for _, c := range components {
+		if c.Quantity <= 0 {
+			return ErrInvalidComponentQuantity
+		}
+		if seen[c.ProductID] {
+			return ErrDuplicateComponent
+		}
+		seen[c.ProductID] = true
+
+		if _, ok := catalog[c.ProductID]; !ok {
+			return ErrComponentNotFound
+		}
+	}
+	return nil
+}
+
+// checkCycle reports ErrCyclicBundle if bundle id is reachable from components
+func checkCycle(id primitive.ObjectID, components []BundleComponent, catalog map[primitive.ObjectID]*Product) error {
+	visited := make(map[primitive.ObjectID]bool)
+	var visit func(components []BundleComponent) error
+	visit = func(components []BundleComponent) error {
+		for _, c := range components {
+			if c.ProductID == id {
+				return ErrCyclicBundle
+			}
+			if visited[c.ProductID] {
+				continue
+			}
+			visited[c.ProductID] = true
+
+			component, ok := catalog[c.ProductID]
+			if !ok {
+				return ErrComponentNotFound
+			}
+			if component.IsBundle() {
+				if err := visit(component.Bundle.Components); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return visit(components)
+}
@@ -0,0 +1,110 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testCatalog(products ...*Product) map[primitive.ObjectID]*Product {
+	catalog := make(map[primitive.ObjectID]*Product, len(products))
+	for _, p := range products {
+		catalog[p.ID] = p
+	}
+	return catalog
+}
+
+func TestNewBundle(t *testing.T) {
+	soap, _ := NewProduct("Soap", "Lavender soap", 4.5)
+	towel, _ := NewProduct("Towel", "Cotton towel", 12.0)
+	catalog := testCatalog(soap, towel)
+
+	components := []BundleComponent{
+		{ProductID: soap.ID, Quantity: 2},
+		{ProductID: towel.ID, Quantity: 1},
+	}
+
+	bundle, err := NewBundle("Spa Set", "Soap and towel", components, nil, catalog)
+	assert.NoError(t, err)
+	assert.True(t, bundle.IsBundle())
+	assert.Equal(t, 21.0, bundle.Price)
+	assert.False(t, bundle.Bundle.PriceOverridden)
+
+	price := 19.99
+	overridden, err := NewBundle("Spa Set", "Soap and towel", components, &price, catalog)
+	assert.NoError(t, err)
+	assert.Equal(t, 19.99, overridden.Price)
+	assert.True(t, overridden.Bundle.PriceOverridden)
+
+	testCases := []struct {
+		name       string
+		components []BundleComponent
+		wantErr    error
+	}{
+		{name: "no components", components: nil, wantErr: ErrEmptyBundle},
+		{name: "zero quantity", components: []BundleComponent{{ProductID: soap.ID}}, wantErr: ErrInvalidComponentQuantity},
+		{name: "duplicate", components: []BundleComponent{{ProductID: soap.ID, Quantity: 1}, {ProductID: soap.ID, Quantity: 1}}, wantErr: ErrDuplicateComponent},
+		{name: "unknown component", components: []BundleComponent{{ProductID: primitive.NewObjectID(), Quantity: 1}}, wantErr: ErrComponentNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewBundle("Spa Set", "Soap and towel", tc.components, nil, catalog)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestUpdateComponentsRejectsCycles(t *testing.T) {
+	soap, _ := NewProduct("Soap", "Lavender soap", 4.5)
+	inner, _ := NewBundle("Soap Pair", "Two soaps", []BundleComponent{{ProductID: soap.ID, Quantity: 2}}, nil, testCatalog(soap))
+	outer, _ := NewBundle("Gift Box", "Soap pair in a box", []BundleComponent{{ProductID: inner.ID, Quantity: 1}}, nil, testCatalog(soap, inner))
+	catalog := testCatalog(soap, inner, outer)
+
+	err := inner.UpdateComponents([]BundleComponent{{ProductID: outer.ID, Quantity: 1}}, catalog)
+	assert.ErrorIs(t, err, ErrCyclicBundle)
+
+	err = inner.UpdateComponents([]BundleComponent{{ProductID: inner.ID, Quantity: 1}}, catalog)
+	assert.ErrorIs(t, err, ErrCyclicBundle)
+
+	err = inner.UpdateComponents([]BundleComponent{{ProductID: soap.ID, Quantity: 3}}, catalog)
+	assert.NoError(t, err)
+	assert.Equal(t, 13.5, inner.Price)
+
+	err = soap.UpdateComponents([]BundleComponent{{ProductID: inner.ID, Quantity: 1}}, catalog)
+	assert.ErrorIs(t, err, ErrNotABundle)
+}
+
+func TestBundlePricing(t *testing.T) {
+	soap, _ := NewProduct("Soap", "Lavender soap", 4.5)
+	bundle, _ := NewBundle("Soap Pair", "Two soaps", []BundleComponent{{ProductID: soap.ID, Quantity: 2}}, nil, testCatalog(soap))
+	catalog := testCatalog(soap)
+
+	assert.NoError(t, bundle.UpdatePrice(7.99))
+	assert.True(t, bundle.Bundle.PriceOverridden)
+
+	soap.Price = 5
+	assert.NoError(t, bundle.RecalculatePrice(catalog))
+	assert.Equal(t, 7.99, bundle.Price)
+
+	assert.NoError(t, bundle.UseDerivedPrice(catalog))
+	assert.False(t, bundle.Bundle.PriceOverridden)
+	assert.Equal(t, 10.0, bundle.Price)
+}
+
+func TestExpandComponents(t *testing.T) {
+	soap, _ := NewProduct("Soap", "Lavender soap", 4.5)
+	towel, _ := NewProduct("Towel", "Cotton towel", 12.0)
+	inner, _ := NewBundle("Soap Pair", "Two soaps", []BundleComponent{{ProductID: soap.ID, Quantity: 2}}, nil, testCatalog(soap))
+	outer, _ := NewBundle("Gift Box", "Soaps and towels", []BundleComponent{
+		{ProductID: inner.ID, Quantity: 2},
+		{ProductID: towel.ID, Quantity: 1},
+		{ProductID: soap.ID, Quantity: 1},
+	}, nil, testCatalog(soap, towel, inner))
+
+	leaves, err := outer.ExpandComponents(testCatalog(soap, towel, inner))
+	assert.NoError(t, err)
+	assert.Equal(t, map[primitive.ObjectID]int{soap.ID: 5, towel.ID: 1}, leaves)
+	assert.Equal(t, 34.5, outer.Price)
+}
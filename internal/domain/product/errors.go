@@ -15,4 +15,71 @@ var (
 
 	// ErrInvalidDescription is returned when a product description is invalid (empty)
 	ErrInvalidDescription = errors.New("invalid description")
+
+	// ErrInvalidCategory is returned when a product category is invalid (empty)
+	ErrInvalidCategory = errors.New("invalid category")
+
+	// ErrInvalidTaxClass is returned when a tax class is not one of the known classes
+	ErrInvalidTaxClass = errors.New("invalid tax class")
+
+	// ErrInvalidLocale is returned when a locale is not a valid BCP 47 language tag
+	ErrInvalidLocale = errors.New("invalid locale")
+
+	// ErrTranslationNotFound is returned when a product has no translation for a locale
+	ErrTranslationNotFound = errors.New("translation not found")
+
+	// ErrDefaultLocaleRequired is returned when removing the translation of the default locale
+	ErrDefaultLocaleRequired = errors.New("the default locale translation cannot be removed")
+
+	// ErrEmptySearchQuery is returned when a search query contains no searchable terms
+	ErrEmptySearchQuery = errors.New("search query is empty")
+
+	// ErrNotABundle is returned when a bundle operation is applied to a plain product
+	ErrNotABundle = errors.New("product is not a bundle")
+
+	// ErrEmptyBundle is returned when a bundle has no components
+	ErrEmptyBundle = errors.New("bundle must have at least one component")
+
+	// ErrInvalidComponentQuantity is returned when a bundle component quantity is not positive
+	ErrInvalidComponentQuantity = errors.New("bundle component quantity must be positive")
+
+	// ErrDuplicateComponent is returned when a product is listed more than once in a bundle
+	ErrDuplicateComponent = errors.New("bundle component listed more than once")
+
+	// ErrComponentNotFound is returned when a bundle component does not exist
+	ErrComponentNotFound = errors.New("bundle component not found")
+
+	// ErrCyclicBundle is returned when a bundle would directly or indirectly contain itself
+	ErrCyclicBundle = errors.New("bundle cannot contain itself")
+
+	// ErrProductInBundle is returned when deleting a product that is a component of a bundle
+	ErrProductInBundle = errors.New("product is a component of a bundle")
+
+	// ErrTooManyIDs is returned when a batch lookup asks for more products than allowed
+	ErrTooManyIDs = errors.New("too many product IDs")
+
+	// ErrInvalidBulkAction is returned when a bulk operation is not a create, update or delete
+	ErrInvalidBulkAction = errors.New("bulk action must be create, update or delete")
+
+	// ErrEmptyBulk is returned when a bulk request holds no operations
+	ErrEmptyBulk = errors.New("bulk request has no operations")
+
+	// ErrBulkTooLarge is returned when a bulk request holds more operations than allowed
+	ErrBulkTooLarge = errors.New("bulk request has too many operations")
+
+	// ErrEmptyUpdate is returned when a bulk update changes nothing
+	ErrEmptyUpdate = errors.New("update must change the price, description, category or tax class")
+
+	// ErrProductExists is returned when a create uses the ID of a product that already exists
+	ErrProductExists = errors.New("product already exists")
+
+	// ErrDuplicateTarget is returned when more than one bulk operation targets the same product
+	ErrDuplicateTarget = errors.New("product is targeted by more than one operation")
+
+	// ErrBulkAborted is returned for the operations of an all-or-nothing bulk
+	// request that were not applied because another operation failed
+	ErrBulkAborted = errors.New("not applied because another operation failed")
+
+	// ErrUnknownField is returned when a field selection names a field products do not have
+	ErrUnknownField = errors.New("unknown product field")
 )
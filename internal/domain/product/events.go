@@ -0,0 +1,26 @@
+package product
+
+import "context"
+
+// ChangeType describes what happened to a product
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// Change is published after a product has been successfully persisted.
+// Product is nil for deletions.
+type Change struct {
+	Type      ChangeType
+	ProductID string
+	Product   *Product
+}
+
+// ChangeListener receives product change notifications. Listeners are called
+// synchronously and must not block.
+type ChangeListener interface {
+	ProductChanged(ctx context.Context, change Change)
+}
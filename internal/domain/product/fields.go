@@ -0,0 +1,43 @@
+package product
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selectableFields are the product fields a client can select, by their JSON
+// names. Stored documents use the same names, except for id which is _id.
+var selectableFields = map[string]bool{
+	"id":             true,
+	"name":           true,
+	"description":    true,
+	"price":          true,
+	"category":       true,
+	"default_locale": true,
+	"translations":   true,
+	"tax_class":      true,
+	"bundle":         true,
+	"rating":         true,
+	"created_at":     true,
+	"updated_at":     true,
+}
+
+// ParseFields parses a comma separated field selection such as
+// "name,price". Blank entries and duplicates are dropped, and an empty
+// selection returns nil, meaning every field.
+func ParseFields(raw string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !selectableFields[field] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -7,12 +7,18 @@ import (
 )
 
 type Product struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name        string             `bson:"name" json:"name"`
-	Description string             `bson:"description" json:"description"`
-	Price       float64            `bson:"price" json:"price"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Name          string                 `bson:"name" json:"name"`
+	Description   string                 `bson:"description" json:"description"`
+	Price         float64                `bson:"price" json:"price"`
+	Category      string                 `bson:"category,omitempty" json:"category,omitempty"`
+	DefaultLocale string                 `bson:"default_locale,omitempty" json:"default_locale,omitempty"`
+	Translations  map[string]Translation `bson:"translations,omitempty" json:"translations,omitempty"`
+	TaxClass      TaxClass               `bson:"tax_class,omitempty" json:"tax_class,omitempty"`
+	Bundle        *Bundle                `bson:"bundle,omitempty" json:"bundle,omitempty"`
+	Rating        RatingSummary          `bson:"rating" json:"rating"`
+	CreatedAt     time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time              `bson:"updated_at" json:"updated_at"`
 }
 
 func NewProduct(name, description string, price float64) (*Product, error) {
@@ -39,12 +45,17 @@ func NewProduct(name, description string, price float64) (*Product, error) {
 	}, nil
 }
 
+// UpdatePrice sets the product price. For bundles this overrides the price
+// derived from the components.
 func (p *Product) UpdatePrice(price float64) error {
 	if price <= 0 {
 		return ErrInvalidPrice
 	}
 
 	p.Price = price
+	if p.IsBundle() {
+		p.Bundle.PriceOverridden = true
+	}
 	p.UpdatedAt = time.Now()
 	return nil
 }
@@ -55,6 +66,17 @@ func (p *Product) UpdateDescription(description string) error {
 	}
 
 	p.Description = description
+	p.syncDefaultTranslation()
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+func (p *Product) UpdateCategory(category string) error {
+	if category == "" {
+		return ErrInvalidCategory
+	}
+
+	p.Category = category
 	p.UpdatedAt = time.Now()
 	return nil
 }
@@ -134,3 +134,83 @@ func TestUpdateDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateCategory(t *testing.T) {
+	testCases := []struct {
+		name     string
+		category string
+		wantErr  error
+	}{
+		{
+			name:     "valid category",
+			category: "Beverages",
+			wantErr:  nil,
+		},
+		{
+			name:     "empty category",
+			category: "",
+			wantErr:  ErrInvalidCategory,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewProduct("Test Product", "Test Description", 10.0)
+			assert.NoError(t, err)
+
+			err = p.UpdateCategory(tc.category)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Empty(t, p.Category)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.category, p.Category)
+		})
+	}
+}
+
+func TestUpdateTaxClass(t *testing.T) {
+	p, err := NewProduct("Test Product", "Test Description", 10.0)
+	assert.NoError(t, err)
+	assert.Equal(t, TaxClassStandard, p.EffectiveTaxClass())
+
+	assert.NoError(t, p.UpdateTaxClass("reduced"))
+	assert.Equal(t, TaxClassReduced, p.TaxClass)
+	assert.Equal(t, TaxClassReduced, p.EffectiveTaxClass())
+
+	assert.ErrorIs(t, p.UpdateTaxClass("luxury"), ErrInvalidTaxClass)
+	assert.Equal(t, TaxClassReduced, p.TaxClass)
+}
+
+func TestNewRatingSummary(t *testing.T) {
+	assert.Equal(t, RatingSummary{}, NewRatingSummary(0, 0))
+	assert.Equal(t, RatingSummary{Average: 4.3, Count: 3}, NewRatingSummary(13, 3))
+	assert.Equal(t, RatingSummary{Average: 5, Count: 1}, NewRatingSummary(5, 1))
+}
+
+func TestParseFields(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr error
+	}{
+		{name: "empty selects every field", raw: "", want: nil},
+		{name: "trims and drops duplicates", raw: " name, price,name,,", want: []string{"name", "price"}},
+		{name: "unknown field", raw: "name,cost", wantErr: ErrUnknownField},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := ParseFields(tc.raw)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, fields)
+		})
+	}
+}
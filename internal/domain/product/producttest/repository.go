@@ -0,0 +1,166 @@
+// Package producttest provides an in-memory product repository for tests of
+// the packages built on products.
+package producttest
+
+import (
+	"context"
+	"maps"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repository keeps products in a map, keyed by their hex ID. It behaves like
+// the MongoDB repository wherever the product service depends on it.
+type Repository struct {
+	Products map[string]*product.Product
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		Products: make(map[string]*product.Product),
+	}
+}
+
+func (m *Repository) Create(ctx context.Context, p *product.Product) error {
+	m.Products[p.ID.Hex()] = p
+	return nil
+}
+
+func (m *Repository) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	if p, ok := m.Products[id]; ok {
+		return p, nil
+	}
+	return nil, product.ErrProductNotFound
+}
+
+// GetByIDs returns the products in reverse request order, so callers cannot
+// rely on the order MongoDB happens to return them in
+func (m *Repository) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	products := make([]*product.Product, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		if p, ok := m.Products[ids[i]]; ok {
+			products = append(products, p)
+		}
+	}
+	return products, nil
+}
+
+func (m *Repository) ListBundlesContaining(ctx context.Context, ids []primitive.ObjectID) ([]*product.Product, error) {
+	bundles := make([]*product.Product, 0)
+	for _, p := range m.Products {
+		for _, id := range ids {
+			if p.Contains(id) {
+				bundles = append(bundles, p)
+				break
+			}
+		}
+	}
+	return bundles, nil
+}
+
+func (m *Repository) Each(ctx context.Context, fn func(*product.Product) error) error {
+	for _, p := range m.Products {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BulkWrite applies each write the way MongoDB does and, like the MongoDB
+// repository, reports updates and deletes that matched no product
+func (m *Repository) BulkWrite(ctx context.Context, writes []*product.BulkWrite) ([]error, error) {
+	errs := make([]error, len(writes))
+	for i, w := range writes {
+		matched, err := m.apply(w)
+		if err == nil && !matched {
+			err = product.ErrProductNotFound
+		}
+		errs[i] = err
+	}
+	return errs, nil
+}
+
+// BulkWriteAtomic looks for the products targeted by updates and deletes
+// first, as the MongoDB repository does inside its transaction, and rolls
+// every write back if one fails
+func (m *Repository) BulkWriteAtomic(ctx context.Context, writes []*product.BulkWrite) ([]error, error) {
+	errs := make([]error, len(writes))
+	abort := func(failed map[int]error) ([]error, error) {
+		for i := range errs {
+			errs[i] = product.ErrBulkAborted
+			if err, ok := failed[i]; ok {
+				errs[i] = err
+			}
+		}
+		return errs, nil
+	}
+
+	missing := make(map[int]error)
+	for i, w := range writes {
+		if _, ok := m.Products[w.ProductID.Hex()]; w.Action != product.BulkCreate && !ok {
+			missing[i] = product.ErrProductNotFound
+		}
+	}
+	if len(missing) > 0 {
+		return abort(missing)
+	}
+
+	snapshot := maps.Clone(m.Products)
+	for i, w := range writes {
+		if _, err := m.apply(w); err != nil {
+			m.Products = snapshot
+			return abort(map[int]error{i: err})
+		}
+	}
+	return errs, nil
+}
+
+// apply performs a write the way MongoDB does: inserting an existing ID
+// fails with ErrProductExists, as the repository reports it, while updating
+// or deleting a missing product matches nothing without an error
+func (m *Repository) apply(w *product.BulkWrite) (bool, error) {
+	id := w.ProductID.Hex()
+	_, ok := m.Products[id]
+	switch w.Action {
+	case product.BulkCreate:
+		if ok {
+			return false, product.ErrProductExists
+		}
+		m.Products[id] = w.Product
+		return true, nil
+	case product.BulkUpdate:
+		if ok {
+			m.Products[id] = w.Product
+		}
+		return ok, nil
+	default:
+		delete(m.Products, id)
+		return ok, nil
+	}
+}
+
+func (m *Repository) Update(ctx context.Context, p *product.Product) error {
+	if _, ok := m.Products[p.ID.Hex()]; !ok {
+		return product.ErrProductNotFound
+	}
+	m.Products[p.ID.Hex()] = p
+	return nil
+}
+
+func (m *Repository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.Products[id]; !ok {
+		return product.ErrProductNotFound
+	}
+	delete(m.Products, id)
+	return nil
+}
+
+func (m *Repository) List(ctx context.Context) ([]*product.Product, error) {
+	products := make([]*product.Product, 0, len(m.Products))
+	for _, p := range m.Products {
+		products = append(products, p)
+	}
+	return products, nil
+}
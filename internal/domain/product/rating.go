@@ -0,0 +1,23 @@
+package product
+
+import "math"
+
+// RatingSummary aggregates the approved customer reviews of a product. It is
+// maintained by the review context and never changed through the product
+// itself.
+type RatingSummary struct {
+	Average float64 `bson:"average" json:"average"`
+	Count   int     `bson:"count" json:"count"`
+}
+
+// NewRatingSummary summarises count ratings adding up to total, rounding
+// the average to one decimal place
+func NewRatingSummary(total, count int) RatingSummary {
+	if count == 0 {
+		return RatingSummary{}
+	}
+	return RatingSummary{
+		Average: math.Round(float64(total)/float64(count)*10) / 10,
+		Count:   count,
+	}
+}
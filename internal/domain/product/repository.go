@@ -2,12 +2,32 @@ package product
 
 import (
 	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Repository interface {
 	Create(ctx context.Context, product *Product) error
 	GetByID(ctx context.Context, id string) (*Product, error)
+	// GetByIDs returns the products with the given IDs that exist, in no
+	// particular order. Malformed IDs are skipped like unknown ones.
+	GetByIDs(ctx context.Context, ids []string) ([]*Product, error)
 	Update(ctx context.Context, product *Product) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*Product, error)
+	// ListBundlesContaining returns the bundles that have any of the given
+	// products as a direct component
+	ListBundlesContaining(ctx context.Context, ids []primitive.ObjectID) ([]*Product, error)
+	// Each calls fn for every product, oldest first, without loading them
+	// all at once. It stops at the first error fn returns.
+	Each(ctx context.Context, fn func(*Product) error) error
+	// BulkWrite stores every write it can, returning one error per write,
+	// nil for writes that were stored. A failed write does not stop the
+	// others. Updates and deletes of products that do not exist fail with
+	// ErrProductNotFound.
+	BulkWrite(ctx context.Context, writes []*BulkWrite) ([]error, error)
+	// BulkWriteAtomic stores all the writes or none of them. When a write
+	// fails, it reports its own error and every other write reports
+	// ErrBulkAborted.
+	BulkWriteAtomic(ctx context.Context, writes []*BulkWrite) ([]error, error)
 }
@@ -0,0 +1,149 @@
+package product
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// SearchHit is a product matched by a full-text query together with its relevance score
+type SearchHit struct {
+	Product    *Product          `json:"product"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+	// MatchedTerms are the indexed words the query matched other than by
+	// prefix, such as typo corrections, so that they can be highlighted too
+	MatchedTerms []string `json:"-"`
+}
+
+// SearchRepository performs full-text search over the product catalog.
+// Hits are returned ordered by descending relevance.
+type SearchRepository interface {
+	Search(ctx context.Context, query string, page, limit int) ([]*SearchHit, int, error)
+}
+
+// SearchOptions describes a faceted catalog query. An empty Query matches
+// every product, which allows browsing by facets alone.
+type SearchOptions struct {
+	Query     string
+	Category  string
+	PriceBand string
+	Fuzzy     bool
+	Page      int
+	Limit     int
+}
+
+// FacetCount is the number of matching products sharing a facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchFacets holds facet counts for the products matched by a query
+type SearchFacets struct {
+	PriceBands []FacetCount `json:"price_bands"`
+	Categories []FacetCount `json:"categories"`
+}
+
+// FacetedSearchRepository performs catalog search with prefix and typo
+// tolerant matching and returns facet counts alongside the hits.
+type FacetedSearchRepository interface {
+	FacetedSearch(ctx context.Context, opts SearchOptions) ([]*SearchHit, int, *SearchFacets, error)
+}
+
+// PriceBand is a half-open price range [Min, Max) used for faceting.
+// A zero Max means the band has no upper bound.
+type PriceBand struct {
+	Label string
+	Min   float64
+	Max   float64
+}
+
+// PriceBands are the price ranges products are grouped into for facets
+var PriceBands = []PriceBand{
+	{Label: "0-10", Min: 0, Max: 10},
+	{Label: "10-50", Min: 10, Max: 50},
+	{Label: "50-100", Min: 50, Max: 100},
+	{Label: "100-500", Min: 100, Max: 500},
+	{Label: "500+", Min: 500},
+}
+
+// PriceBandOf returns the label of the price band the given price falls into
+func PriceBandOf(price float64) string {
+	for _, band := range PriceBands {
+		if price >= band.Min && (band.Max == 0 || price < band.Max) {
+			return band.Label
+		}
+	}
+	return ""
+}
+
+// SearchTerms splits a free-text query into distinct lowercase terms
+func SearchTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(fields))
+	terms := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		terms = append(terms, f)
+	}
+	return terms
+}
+
+// Highlight HTML-escapes text and wraps every word starting with one of the
+// given terms in <em> tags. Prefix matching mirrors the stemming done by the
+// text index, so "shoe" highlights "shoes" as well.
+func Highlight(text string, terms []string) string {
+	if len(terms) == 0 {
+		return html.EscapeString(text)
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			j := i
+			for j < len(runes) && !isWordRune(runes[j]) {
+				j++
+			}
+			b.WriteString(html.EscapeString(string(runes[i:j])))
+			i = j
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		if matchesAnyTerm(strings.ToLower(word), terms) {
+			b.WriteString("<em>")
+			b.WriteString(html.EscapeString(word))
+			b.WriteString("</em>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func matchesAnyTerm(word string, terms []string) bool {
+	for _, t := range terms {
+		if strings.HasPrefix(word, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTerms(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "single term",
+			query: "Coffee",
+			want:  []string{"coffee"},
+		},
+		{
+			name:  "punctuation and duplicates",
+			query: "red, RED shoes!",
+			want:  []string{"red", "shoes"},
+		},
+		{
+			name:  "blank query",
+			query: "   ",
+			want:  []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, SearchTerms(tc.query))
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{
+			name:  "exact match",
+			text:  "Fresh coffee beans",
+			terms: []string{"coffee"},
+			want:  "Fresh <em>coffee</em> beans",
+		},
+		{
+			name:  "prefix match is case insensitive",
+			text:  "Running Shoes",
+			terms: []string{"shoe"},
+			want:  "Running <em>Shoes</em>",
+		},
+		{
+			name:  "html is escaped",
+			text:  "Salt & <b>pepper</b>",
+			terms: []string{"pepper"},
+			want:  "Salt &amp; &lt;b&gt;<em>pepper</em>&lt;/b&gt;",
+		},
+		{
+			name:  "no terms",
+			text:  "Plain text",
+			terms: nil,
+			want:  "Plain text",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Highlight(tc.text, tc.terms))
+		})
+	}
+}
+
+func TestPriceBandOf(t *testing.T) {
+	testCases := []struct {
+		price float64
+		want  string
+	}{
+		{price: 0.5, want: "0-10"},
+		{price: 10, want: "10-50"},
+		{price: 99.99, want: "50-100"},
+		{price: 500, want: "500+"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, PriceBandOf(tc.price))
+	}
+}
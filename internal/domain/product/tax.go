@@ -0,0 +1,56 @@
+package product
+
+import "time"
+
+// TaxClass groups products that are taxed at the same rate, such as food
+// qualifying for a reduced rate. The rate for each class is set per
+// jurisdiction.
+type TaxClass string
+
+const (
+	TaxClassStandard     TaxClass = "standard"
+	TaxClassReduced      TaxClass = "reduced"
+	TaxClassSuperReduced TaxClass = "super_reduced"
+	TaxClassZero         TaxClass = "zero"
+	TaxClassExempt       TaxClass = "exempt"
+)
+
+// TaxClasses lists every valid tax class
+var TaxClasses = []TaxClass{
+	TaxClassStandard,
+	TaxClassReduced,
+	TaxClassSuperReduced,
+	TaxClassZero,
+	TaxClassExempt,
+}
+
+// ParseTaxClass validates a tax class name
+func ParseTaxClass(s string) (TaxClass, error) {
+	for _, c := range TaxClasses {
+		if string(c) == s {
+			return c, nil
+		}
+	}
+	return "", ErrInvalidTaxClass
+}
+
+// UpdateTaxClass sets the product's tax class
+func (p *Product) UpdateTaxClass(class string) error {
+	parsed, err := ParseTaxClass(class)
+	if err != nil {
+		return err
+	}
+
+	p.TaxClass = parsed
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// EffectiveTaxClass returns the product's tax class, defaulting to standard
+// for products that have none.
+func (p *Product) EffectiveTaxClass() TaxClass {
+	if p.TaxClass == "" {
+		return TaxClassStandard
+	}
+	return p.TaxClass
+}
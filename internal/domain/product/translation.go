@@ -0,0 +1,175 @@
+package product
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of products created before translations were
+// introduced, whose Name and Description are not tagged with a locale.
+const DefaultLocale = "en"
+
+// Translation is the name and description of a product in one locale
+type Translation struct {
+	Name        string `bson:"name" json:"name"`
+	Description string `bson:"description" json:"description"`
+}
+
+// ParseLocale validates a BCP 47 language tag and returns it in canonical
+// form, e.g. "de-CH" for "de-ch".
+func ParseLocale(locale string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+	if err != nil || tag == language.Und {
+		return "", ErrInvalidLocale
+	}
+	return tag.String(), nil
+}
+
+// PrimaryLocale returns the locale of Name and Description
+func (p *Product) PrimaryLocale() string {
+	if p.DefaultLocale == "" {
+		return DefaultLocale
+	}
+	return p.DefaultLocale
+}
+
+// Locales returns every locale the product is available in, sorted
+func (p *Product) Locales() []string {
+	if len(p.Translations) == 0 {
+		return []string{p.PrimaryLocale()}
+	}
+	locales := make([]string, 0, len(p.Translations))
+	for locale := range p.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetTranslation adds or replaces the product's name and description in a
+// locale. Translating the default locale updates Name and Description.
+func (p *Product) SetTranslation(locale, name, description string) error {
+	locale, err := ParseLocale(locale)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return ErrInvalidName
+	}
+	if description == "" {
+		return ErrInvalidDescription
+	}
+
+	p.ensureTranslations()
+	p.Translations[locale] = Translation{Name: name, Description: description}
+	if locale == p.DefaultLocale {
+		p.Name = name
+		p.Description = description
+	}
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveTranslation removes a locale. The default locale cannot be removed.
+func (p *Product) RemoveTranslation(locale string) error {
+	locale, err := ParseLocale(locale)
+	if err != nil {
+		return err
+	}
+	if locale == p.PrimaryLocale() {
+		return ErrDefaultLocaleRequired
+	}
+	if _, ok := p.Translations[locale]; !ok {
+		return ErrTranslationNotFound
+	}
+
+	delete(p.Translations, locale)
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetDefaultLocale makes an existing translation the default, so that Name
+// and Description hold its text.
+func (p *Product) SetDefaultLocale(locale string) error {
+	locale, err := ParseLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	p.ensureTranslations()
+	translation, ok := p.Translations[locale]
+	if !ok {
+		return ErrTranslationNotFound
+	}
+
+	p.DefaultLocale = locale
+	p.Name = translation.Name
+	p.Description = translation.Description
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// Localize returns a copy of the product whose Name and Description are in
+// the locale that best matches the preferred languages, falling back to the
+// default locale. It also returns the chosen locale.
+func (p *Product) Localize(preferred []language.Tag) (*Product, string) {
+	localized := *p
+	locale := p.BestLocale(preferred)
+	if translation, ok := p.Translations[locale]; ok {
+		localized.Name = translation.Name
+		localized.Description = translation.Description
+	}
+	return &localized, locale
+}
+
+// BestLocale picks the product locale that best matches the preferred
+// languages, in order of preference, or the default locale if none matches.
+func (p *Product) BestLocale(preferred []language.Tag) string {
+	locales := p.Locales()
+	if len(preferred) == 0 || len(locales) == 1 {
+		return p.PrimaryLocale()
+	}
+
+	// The default locale goes first so that it wins when nothing matches
+	tags := []language.Tag{language.Make(p.PrimaryLocale())}
+	for _, locale := range locales {
+		if locale != p.PrimaryLocale() {
+			tags = append(tags, language.Make(locale))
+		}
+	}
+
+	_, index, confidence := language.NewMatcher(tags).Match(preferred...)
+	if confidence == language.No {
+		return p.PrimaryLocale()
+	}
+	if index == 0 {
+		return p.PrimaryLocale()
+	}
+	return tags[index].String()
+}
+
+// ensureTranslations seeds the translations with the default locale the first
+// time a product is translated.
+func (p *Product) ensureTranslations() {
+	if p.DefaultLocale == "" {
+		p.DefaultLocale = DefaultLocale
+	}
+	if p.Translations == nil {
+		p.Translations = make(map[string]Translation)
+	}
+	if _, ok := p.Translations[p.DefaultLocale]; !ok {
+		p.Translations[p.DefaultLocale] = Translation{Name: p.Name, Description: p.Description}
+	}
+}
+
+// syncDefaultTranslation keeps the default translation in step with Name and
+// Description after they are edited directly.
+func (p *Product) syncDefaultTranslation() {
+	if p.Translations == nil {
+		return
+	}
+	p.Translations[p.PrimaryLocale()] = Translation{Name: p.Name, Description: p.Description}
+}
@@ -0,0 +1,76 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestSetTranslation(t *testing.T) {
+	p, err := NewProduct("Green Tea", "Loose leaf green tea", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en"}, p.Locales())
+
+	assert.NoError(t, p.SetTranslation("de", "Grüner Tee", "Loser grüner Tee"))
+	assert.Equal(t, "en", p.DefaultLocale)
+	assert.Equal(t, []string{"de", "en"}, p.Locales())
+	assert.Equal(t, "Green Tea", p.Name)
+	assert.Equal(t, Translation{Name: "Green Tea", Description: "Loose leaf green tea"}, p.Translations["en"])
+
+	assert.NoError(t, p.SetTranslation("EN", "Sencha", "Japanese green tea"))
+	assert.Equal(t, "Sencha", p.Name)
+	assert.Equal(t, "Japanese green tea", p.Description)
+
+	assert.NoError(t, p.UpdateDescription("Steamed Japanese green tea"))
+	assert.Equal(t, "Steamed Japanese green tea", p.Translations["en"].Description)
+
+	assert.ErrorIs(t, p.SetTranslation("not a locale!", "x", "y"), ErrInvalidLocale)
+	assert.ErrorIs(t, p.SetTranslation("fr", "", "Thé vert"), ErrInvalidName)
+}
+
+func TestDefaultLocale(t *testing.T) {
+	p, _ := NewProduct("Green Tea", "Loose leaf green tea", 5)
+	assert.ErrorIs(t, p.SetDefaultLocale("de"), ErrTranslationNotFound)
+
+	assert.NoError(t, p.SetTranslation("de", "Grüner Tee", "Loser grüner Tee"))
+	assert.NoError(t, p.SetDefaultLocale("de"))
+	assert.Equal(t, "Grüner Tee", p.Name)
+	assert.Equal(t, "de", p.PrimaryLocale())
+
+	assert.ErrorIs(t, p.RemoveTranslation("de"), ErrDefaultLocaleRequired)
+	assert.NoError(t, p.RemoveTranslation("en"))
+	assert.ErrorIs(t, p.RemoveTranslation("en"), ErrTranslationNotFound)
+	assert.Equal(t, []string{"de"}, p.Locales())
+}
+
+func TestLocalize(t *testing.T) {
+	p, _ := NewProduct("Green Tea", "Loose leaf green tea", 5)
+	assert.NoError(t, p.SetTranslation("de", "Grüner Tee", "Loser grüner Tee"))
+	assert.NoError(t, p.SetTranslation("fr-CH", "Thé vert", "Thé vert en vrac"))
+
+	testCases := []struct {
+		name       string
+		accept     string
+		wantLocale string
+		wantName   string
+	}{
+		{name: "no preference", accept: "", wantLocale: "en", wantName: "Green Tea"},
+		{name: "exact match", accept: "de", wantLocale: "de", wantName: "Grüner Tee"},
+		{name: "regional variant", accept: "de-AT", wantLocale: "de", wantName: "Grüner Tee"},
+		{name: "quality order", accept: "it;q=0.9, fr-CH;q=0.8, de;q=0.5", wantLocale: "fr-CH", wantName: "Thé vert"},
+		{name: "no match falls back", accept: "ja", wantLocale: "en", wantName: "Green Tea"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preferred, _, err := language.ParseAcceptLanguage(tc.accept)
+			assert.NoError(t, err)
+
+			localized, locale := p.Localize(preferred)
+			assert.Equal(t, tc.wantLocale, locale)
+			assert.Equal(t, tc.wantName, localized.Name)
+			assert.Equal(t, "Green Tea", p.Name)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package promotion
+
+import (
+	"math"
+	"sort"
+)
+
+// AppliedDiscount is the amount a single promotion took off a quote
+type AppliedDiscount struct {
+	PromotionID string  `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Discount    float64 `json:"discount"`
+}
+
+// Quote is the price of a quantity of a product after promotions
+type Quote struct {
+	BasePrice float64           `json:"base_price"`
+	Quantity  int               `json:"quantity"`
+	Subtotal  float64           `json:"subtotal"`
+	Discount  float64           `json:"discount"`
+	Total     float64           `json:"total"`
+	UnitPrice float64           `json:"unit_price"`
+	Applied   []AppliedDiscount `json:"applied"`
+}
+
+// Price applies promotions to quantity units at basePrice. The promotions
+// must already be filtered to those applicable; Price decides which of them
+// combine according to priority and stacking.
+func Price(basePrice float64, quantity int, promotions []*Promotion) (*Quote, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	subtotal := basePrice * float64(quantity)
+	quote := &Quote{
+		BasePrice: basePrice,
+		Quantity:  quantity,
+		Subtotal:  roundCents(subtotal),
+		Applied:   make([]AppliedDiscount, 0),
+	}
+
+	remaining := subtotal
+	for _, p := range Select(promotions) {
+		discount := math.Min(p.Rule.discount(remaining, quantity), remaining)
+		if discount <= 0 {
+			continue
+		}
+		remaining -= discount
+		quote.Applied = append(quote.Applied, AppliedDiscount{
+			PromotionID: p.ID.Hex(),
+			Name:        p.Name,
+			Discount:    roundCents(discount),
+		})
+	}
+
+	quote.Total = roundCents(remaining)
+	quote.Discount = roundCents(quote.Subtotal - quote.Total)
+	quote.UnitPrice = roundCents(remaining / float64(quantity))
+	return quote, nil
+}
+
+// Select orders promotions by priority and drops those that may not combine.
+// The highest priority promotion always applies; if it is not stackable it
+// applies alone, otherwise every other stackable promotion follows it. Ties
+// are broken by creation time, oldest first.
+func Select(promotions []*Promotion) []*Promotion {
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	sorted := append([]*Promotion(nil), promotions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	if !sorted[0].Stackable {
+		return sorted[:1]
+	}
+
+	selected := make([]*Promotion, 0, len(sorted))
+	for _, p := range sorted {
+		if p.Stackable {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// discount returns the amount the rule takes off total, the current price of
+// quantity units.
+func (r Rule) discount(total float64, quantity int) float64 {
+	switch r.Type {
+	case TypePercentage:
+		return total * r.Percent / 100
+	case TypeFixedAmount:
+		unit := total / float64(quantity)
+		return math.Min(r.Amount, unit) * float64(quantity)
+	case TypeBuyXGetY:
+		free := quantity / (r.BuyQuantity + r.GetQuantity) * r.GetQuantity
+		return total / float64(quantity) * float64(free)
+	}
+	return 0
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,175 @@
+package promotion
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPromotionNotFound = errors.New("promotion not found")
+	ErrInvalidName       = errors.New("promotion name cannot be empty")
+	ErrInvalidType       = errors.New("promotion type must be one of percentage, fixed_amount or buy_x_get_y")
+	ErrInvalidPercent    = errors.New("percentage must be greater than 0 and at most 100")
+	ErrInvalidAmount     = errors.New("amount must be greater than 0")
+	ErrInvalidBuyGet     = errors.New("buy and get quantities must be greater than 0")
+	ErrInvalidWindow     = errors.New("promotion must end after it starts")
+	ErrInvalidQuantity   = errors.New("quantity must be greater than 0")
+)
+
+// Type selects how a promotion discounts a price
+type Type string
+
+const (
+	// TypePercentage takes Percent percent off the price
+	TypePercentage Type = "percentage"
+	// TypeFixedAmount takes Amount off the price of every unit
+	TypeFixedAmount Type = "fixed_amount"
+	// TypeBuyXGetY gives GetQuantity units free for every BuyQuantity units
+	// bought, e.g. buy 2 get 1 free.
+	TypeBuyXGetY Type = "buy_x_get_y"
+)
+
+// Rule is the discount a promotion grants. Only the fields relevant to Type
+// are set.
+type Rule struct {
+	Type        Type    `bson:"type" json:"type"`
+	Percent     float64 `bson:"percent,omitempty" json:"percent,omitempty"`
+	Amount      float64 `bson:"amount,omitempty" json:"amount,omitempty"`
+	BuyQuantity int     `bson:"buy_quantity,omitempty" json:"buy_quantity,omitempty"`
+	GetQuantity int     `bson:"get_quantity,omitempty" json:"get_quantity,omitempty"`
+}
+
+func (r Rule) validate() error {
+	switch r.Type {
+	case TypePercentage:
+		if r.Percent <= 0 || r.Percent > 100 {
+			return ErrInvalidPercent
+		}
+	case TypeFixedAmount:
+		if r.Amount <= 0 {
+			return ErrInvalidAmount
+		}
+	case TypeBuyXGetY:
+		if r.BuyQuantity <= 0 || r.GetQuantity <= 0 {
+			return ErrInvalidBuyGet
+		}
+	default:
+		return ErrInvalidType
+	}
+	return nil
+}
+
+// Scope limits the stores and products a promotion applies to. An empty
+// StoreIDs matches every store. A product matches if it is listed in
+// ProductIDs or belongs to one of Categories; with both empty every product
+// matches, and with only Categories set the promotion is category-wide.
+type Scope struct {
+	StoreIDs   []primitive.ObjectID `bson:"store_ids,omitempty" json:"store_ids,omitempty"`
+	ProductIDs []primitive.ObjectID `bson:"product_ids,omitempty" json:"product_ids,omitempty"`
+	Categories []string             `bson:"categories,omitempty" json:"categories,omitempty"`
+}
+
+// Promotion is a discount rule that applies within its validity window to
+// the stores and products in its scope. When several promotions apply, the
+// one with the highest Priority wins; if it is Stackable, every other
+// stackable promotion is applied after it in priority order.
+type Promotion struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Rule      Rule               `bson:"rule" json:"rule"`
+	Scope     Scope              `bson:"scope" json:"scope"`
+	StartsAt  time.Time          `bson:"starts_at" json:"starts_at"`
+	EndsAt    *time.Time         `bson:"ends_at,omitempty" json:"ends_at,omitempty"`
+	Priority  int                `bson:"priority" json:"priority"`
+	Stackable bool               `bson:"stackable" json:"stackable"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// NewPromotion creates a promotion valid from startsAt until endsAt, or
+// indefinitely if endsAt is nil.
+func NewPromotion(name string, rule Rule, scope Scope, startsAt time.Time, endsAt *time.Time, priority int, stackable bool) (*Promotion, error) {
+	now := time.Now()
+	p := &Promotion{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: now,
+	}
+	if err := p.Revise(name, rule, scope, startsAt, endsAt, priority, stackable); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Revise replaces the promotion's definition
+func (p *Promotion) Revise(name string, rule Rule, scope Scope, startsAt time.Time, endsAt *time.Time, priority int, stackable bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidName
+	}
+
+	if err := rule.validate(); err != nil {
+		return err
+	}
+
+	if endsAt != nil && !endsAt.After(startsAt) {
+		return ErrInvalidWindow
+	}
+
+	categories := make([]string, 0, len(scope.Categories))
+	for _, c := range scope.Categories {
+		if c = strings.TrimSpace(c); c != "" {
+			categories = append(categories, c)
+		}
+	}
+	scope.Categories = categories
+
+	p.Name = name
+	p.Rule = rule
+	p.Scope = scope
+	p.StartsAt = startsAt
+	p.EndsAt = endsAt
+	p.Priority = priority
+	p.Stackable = stackable
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// ActiveAt reports whether t falls within the validity window
+func (p *Promotion) ActiveAt(t time.Time) bool {
+	if t.Before(p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || t.Before(*p.EndsAt)
+}
+
+// AppliesTo reports whether the promotion covers the product in the store
+func (p *Promotion) AppliesTo(productID primitive.ObjectID, category string, storeID primitive.ObjectID) bool {
+	if len(p.Scope.StoreIDs) > 0 && !containsID(p.Scope.StoreIDs, storeID) {
+		return false
+	}
+
+	if len(p.Scope.ProductIDs) == 0 && len(p.Scope.Categories) == 0 {
+		return true
+	}
+	if containsID(p.Scope.ProductIDs, productID) {
+		return true
+	}
+	for _, c := range p.Scope.Categories {
+		if category != "" && c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
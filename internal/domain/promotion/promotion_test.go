@@ -0,0 +1,124 @@
+package promotion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewPromotionValidation(t *testing.T) {
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+
+	testCases := []struct {
+		name    string
+		rule    Rule
+		endsAt  *time.Time
+		wantErr error
+	}{
+		{name: "percentage", rule: Rule{Type: TypePercentage, Percent: 10}},
+		{name: "percentage over 100", rule: Rule{Type: TypePercentage, Percent: 120}, wantErr: ErrInvalidPercent},
+		{name: "fixed amount", rule: Rule{Type: TypeFixedAmount, Amount: 2}},
+		{name: "zero amount", rule: Rule{Type: TypeFixedAmount}, wantErr: ErrInvalidAmount},
+		{name: "buy x get y", rule: Rule{Type: TypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1}},
+		{name: "buy x get nothing", rule: Rule{Type: TypeBuyXGetY, BuyQuantity: 2}, wantErr: ErrInvalidBuyGet},
+		{name: "unknown type", rule: Rule{Type: "bogus"}, wantErr: ErrInvalidType},
+		{name: "ends before start", rule: Rule{Type: TypePercentage, Percent: 10}, endsAt: &before, wantErr: ErrInvalidWindow},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewPromotion("Sale", tc.rule, Scope{}, start, tc.endsAt, 0, false)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestActiveAt(t *testing.T) {
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	p, err := NewPromotion("June", Rule{Type: TypePercentage, Percent: 10}, Scope{}, start, &end, 0, false)
+	assert.NoError(t, err)
+
+	assert.False(t, p.ActiveAt(start.Add(-time.Second)))
+	assert.True(t, p.ActiveAt(start))
+	assert.True(t, p.ActiveAt(end.Add(-time.Second)))
+	assert.False(t, p.ActiveAt(end))
+}
+
+func TestAppliesTo(t *testing.T) {
+	storeID := primitive.NewObjectID()
+	productID := primitive.NewObjectID()
+	rule := Rule{Type: TypePercentage, Percent: 10}
+
+	everything, _ := NewPromotion("All", rule, Scope{}, time.Now(), nil, 0, false)
+	assert.True(t, everything.AppliesTo(productID, "", storeID))
+
+	otherStore, _ := NewPromotion("Elsewhere", rule, Scope{StoreIDs: []primitive.ObjectID{primitive.NewObjectID()}}, time.Now(), nil, 0, false)
+	assert.False(t, otherStore.AppliesTo(productID, "", storeID))
+
+	category, _ := NewPromotion("Tea", rule, Scope{Categories: []string{"tea"}}, time.Now(), nil, 0, false)
+	assert.True(t, category.AppliesTo(productID, "tea", storeID))
+	assert.False(t, category.AppliesTo(productID, "coffee", storeID))
+	assert.False(t, category.AppliesTo(productID, "", storeID))
+
+	listed, _ := NewPromotion("Listed", rule, Scope{ProductIDs: []primitive.ObjectID{productID}, Categories: []string{"tea"}}, time.Now(), nil, 0, false)
+	assert.True(t, listed.AppliesTo(productID, "coffee", storeID))
+}
+
+func TestPrice(t *testing.T) {
+	start := time.Now()
+	newPromotion := func(name string, rule Rule, priority int, stackable bool) *Promotion {
+		p, err := NewPromotion(name, rule, Scope{}, start, nil, priority, stackable)
+		assert.NoError(t, err)
+		return p
+	}
+
+	tenPercent := newPromotion("10%", Rule{Type: TypePercentage, Percent: 10}, 1, true)
+	twoOff := newPromotion("2 off", Rule{Type: TypeFixedAmount, Amount: 2}, 2, true)
+	threeForTwo := newPromotion("3 for 2", Rule{Type: TypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1}, 5, false)
+	huge := newPromotion("Huge", Rule{Type: TypeFixedAmount, Amount: 50}, 0, false)
+
+	testCases := []struct {
+		name       string
+		quantity   int
+		promotions []*Promotion
+		wantTotal  float64
+		wantNames  []string
+	}{
+		{name: "no promotions", quantity: 2, wantTotal: 20},
+		{name: "percentage", quantity: 2, promotions: []*Promotion{tenPercent}, wantTotal: 18, wantNames: []string{"10%"}},
+		{name: "stacked in priority order", quantity: 1, promotions: []*Promotion{tenPercent, twoOff}, wantTotal: 7.2, wantNames: []string{"2 off", "10%"}},
+		{name: "exclusive wins", quantity: 3, promotions: []*Promotion{tenPercent, threeForTwo, twoOff}, wantTotal: 20, wantNames: []string{"3 for 2"}},
+		{name: "buy x get y needs enough units", quantity: 2, promotions: []*Promotion{threeForTwo}, wantTotal: 20, wantNames: []string{}},
+		{name: "never below zero", quantity: 1, promotions: []*Promotion{huge}, wantTotal: 0, wantNames: []string{"Huge"}},
+		{name: "exclusive with lower priority is skipped", quantity: 1, promotions: []*Promotion{huge, tenPercent}, wantTotal: 9, wantNames: []string{"10%"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := Price(10, tc.quantity, tc.promotions)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTotal, quote.Total)
+			assert.Equal(t, quote.Subtotal-quote.Total, quote.Discount)
+
+			names := make([]string, 0, len(quote.Applied))
+			for _, a := range quote.Applied {
+				names = append(names, a.Name)
+			}
+			if tc.wantNames == nil {
+				tc.wantNames = []string{}
+			}
+			assert.Equal(t, tc.wantNames, names)
+		})
+	}
+
+	_, err := Price(10, 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidQuantity)
+}
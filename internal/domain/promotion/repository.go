@@ -0,0 +1,16 @@
+package promotion
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, promotion *Promotion) error
+	GetByID(ctx context.Context, id string) (*Promotion, error)
+	Update(ctx context.Context, promotion *Promotion) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, page, limit int) ([]*Promotion, int, error)
+	// ListActive returns every promotion whose validity window contains at
+	ListActive(ctx context.Context, at time.Time) ([]*Promotion, error)
+}
@@ -0,0 +1,201 @@
+package purchaseorder
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+	ErrEmptyPurchaseOrder    = errors.New("purchase order must have at least one line")
+	ErrInvalidQuantity       = errors.New("quantity must be greater than 0")
+	ErrLineNotFound          = errors.New("product is not on the purchase order")
+	ErrOverReceipt           = errors.New("received quantity exceeds the quantity outstanding")
+	ErrNotDraft              = errors.New("only draft purchase orders can be changed")
+	ErrConcurrentUpdate      = errors.New("purchase order was modified concurrently")
+)
+
+// Line is a product ordered from the supplier at the supplier's cost price
+type Line struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+	UnitCost  float64            `bson:"unit_cost" json:"unit_cost"`
+	Received  int                `bson:"received" json:"received"`
+}
+
+// Outstanding returns the units still to be delivered
+func (l Line) Outstanding() int {
+	return l.Quantity - l.Received
+}
+
+// Receipt is a delivery of units of one product
+type Receipt struct {
+	ProductID primitive.ObjectID
+	Quantity  int
+}
+
+// PurchaseOrder replenishes one store from one supplier. Version is bumped on
+// every save so that concurrent receipts cannot both be applied.
+type PurchaseOrder struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	StoreID    primitive.ObjectID `bson:"store_id" json:"store_id"`
+	SupplierID primitive.ObjectID `bson:"supplier_id" json:"supplier_id"`
+	Lines      []Line             `bson:"lines" json:"lines"`
+	Total      float64            `bson:"total" json:"total"`
+	Status     Status             `bson:"status" json:"status"`
+	SentAt     *time.Time         `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	ExpectedAt *time.Time         `bson:"expected_at,omitempty" json:"expected_at,omitempty"`
+	ReceivedAt *time.Time         `bson:"received_at,omitempty" json:"received_at,omitempty"`
+	Version    int                `bson:"version" json:"version"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// NewPurchaseOrder creates an empty draft order for a store
+func NewPurchaseOrder(storeID, supplierID primitive.ObjectID) *PurchaseOrder {
+	now := time.Now()
+	return &PurchaseOrder{
+		ID:         primitive.NewObjectID(),
+		StoreID:    storeID,
+		SupplierID: supplierID,
+		Lines:      []Line{},
+		Status:     StatusDraft,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// SetLine orders quantity units of a product at the supplier's cost price,
+// replacing any quantity already on the order
+func (po *PurchaseOrder) SetLine(s *supplier.Supplier, productID primitive.ObjectID, quantity int) error {
+	if po.Status != StatusDraft {
+		return ErrNotDraft
+	}
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	cost, err := s.CostFor(productID)
+	if err != nil {
+		return err
+	}
+
+	line := Line{ProductID: productID, Quantity: quantity, UnitCost: cost}
+	replaced := false
+	for i := range po.Lines {
+		if po.Lines[i].ProductID == productID {
+			po.Lines[i] = line
+			replaced = true
+		}
+	}
+	if !replaced {
+		po.Lines = append(po.Lines, line)
+	}
+
+	po.recalculate()
+	return nil
+}
+
+func (po *PurchaseOrder) RemoveLine(productID primitive.ObjectID) error {
+	if po.Status != StatusDraft {
+		return ErrNotDraft
+	}
+
+	for i := range po.Lines {
+		if po.Lines[i].ProductID == productID {
+			po.Lines = append(po.Lines[:i], po.Lines[i+1:]...)
+			po.recalculate()
+			return nil
+		}
+	}
+	return ErrLineNotFound
+}
+
+// Send marks the order as sent to the supplier, expecting delivery after
+// leadTime
+func (po *PurchaseOrder) Send(leadTime time.Duration) error {
+	if po.Status != StatusDraft {
+		return ErrInvalidStatusTransition
+	}
+	if len(po.Lines) == 0 {
+		return ErrEmptyPurchaseOrder
+	}
+
+	now := time.Now()
+	expected := now.Add(leadTime)
+	po.Status = StatusSent
+	po.SentAt = &now
+	po.ExpectedAt = &expected
+	po.UpdatedAt = now
+	return nil
+}
+
+// Receive books a delivery against the order. Either every receipt is
+// applied or, if any is invalid, none is. The order becomes received once
+// nothing is outstanding.
+func (po *PurchaseOrder) Receive(receipts []Receipt) error {
+	if !po.Status.Receivable() {
+		return ErrInvalidStatusTransition
+	}
+	if len(receipts) == 0 {
+		return ErrInvalidQuantity
+	}
+
+	received := make(map[primitive.ObjectID]int, len(receipts))
+	for _, r := range receipts {
+		if r.Quantity <= 0 {
+			return ErrInvalidQuantity
+		}
+		received[r.ProductID] += r.Quantity
+	}
+
+	for productID, quantity := range received {
+		line := po.line(productID)
+		if line == nil {
+			return ErrLineNotFound
+		}
+		if quantity > line.Outstanding() {
+			return ErrOverReceipt
+		}
+	}
+
+	now := time.Now()
+	for productID, quantity := range received {
+		po.line(productID).Received += quantity
+	}
+
+	po.Status = StatusReceived
+	for _, line := range po.Lines {
+		if line.Outstanding() > 0 {
+			po.Status = StatusPartiallyReceived
+			break
+		}
+	}
+	if po.Status == StatusReceived {
+		po.ReceivedAt = &now
+	}
+	po.UpdatedAt = now
+	return nil
+}
+
+func (po *PurchaseOrder) line(productID primitive.ObjectID) *Line {
+	for i := range po.Lines {
+		if po.Lines[i].ProductID == productID {
+			return &po.Lines[i]
+		}
+	}
+	return nil
+}
+
+func (po *PurchaseOrder) recalculate() {
+	var total float64
+	for _, line := range po.Lines {
+		total += line.UnitCost * float64(line.Quantity)
+	}
+	po.Total = math.Round(total*100) / 100
+	po.UpdatedAt = time.Now()
+}
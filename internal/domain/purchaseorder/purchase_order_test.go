@@ -0,0 +1,68 @@
+package purchaseorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newDraft(t *testing.T) (*PurchaseOrder, *supplier.Supplier, primitive.ObjectID, primitive.ObjectID) {
+	s, err := supplier.NewSupplier("Acme", supplier.Contact{}, 2)
+	assert.NoError(t, err)
+	coffee, tea := primitive.NewObjectID(), primitive.NewObjectID()
+	assert.NoError(t, s.SetProduct(coffee, 2.50))
+	assert.NoError(t, s.SetProduct(tea, 1.10))
+
+	return NewPurchaseOrder(primitive.NewObjectID(), s.ID), s, coffee, tea
+}
+
+func TestPurchaseOrderDraft(t *testing.T) {
+	po, s, coffee, tea := newDraft(t)
+
+	assert.ErrorIs(t, po.Send(time.Hour), ErrEmptyPurchaseOrder)
+	assert.ErrorIs(t, po.SetLine(s, primitive.NewObjectID(), 1), supplier.ErrProductNotSupplied)
+	assert.ErrorIs(t, po.SetLine(s, coffee, 0), ErrInvalidQuantity)
+
+	assert.NoError(t, po.SetLine(s, coffee, 10))
+	assert.NoError(t, po.SetLine(s, tea, 4))
+	assert.NoError(t, po.SetLine(s, coffee, 12))
+	assert.Len(t, po.Lines, 2)
+	assert.Equal(t, 34.4, po.Total)
+
+	assert.NoError(t, po.RemoveLine(tea))
+	assert.Equal(t, 30.0, po.Total)
+
+	assert.NoError(t, po.Send(48*time.Hour))
+	assert.Equal(t, StatusSent, po.Status)
+	assert.WithinDuration(t, po.SentAt.Add(48*time.Hour), *po.ExpectedAt, time.Second)
+
+	assert.ErrorIs(t, po.SetLine(s, tea, 1), ErrNotDraft)
+	assert.ErrorIs(t, po.Send(time.Hour), ErrInvalidStatusTransition)
+}
+
+func TestPurchaseOrderReceive(t *testing.T) {
+	po, s, coffee, tea := newDraft(t)
+	assert.NoError(t, po.SetLine(s, coffee, 10))
+	assert.NoError(t, po.SetLine(s, tea, 5))
+
+	assert.ErrorIs(t, po.Receive([]Receipt{{ProductID: coffee, Quantity: 1}}), ErrInvalidStatusTransition)
+	assert.NoError(t, po.Send(time.Hour))
+
+	assert.ErrorIs(t, po.Receive([]Receipt{{ProductID: coffee, Quantity: 11}}), ErrOverReceipt)
+	assert.ErrorIs(t, po.Receive([]Receipt{{ProductID: primitive.NewObjectID(), Quantity: 1}}), ErrLineNotFound)
+	assert.ErrorIs(t, po.Receive([]Receipt{{ProductID: coffee, Quantity: 4}, {ProductID: tea, Quantity: 6}}), ErrOverReceipt)
+	assert.Equal(t, 0, po.Lines[0].Received)
+
+	assert.NoError(t, po.Receive([]Receipt{{ProductID: coffee, Quantity: 4}}))
+	assert.Equal(t, StatusPartiallyReceived, po.Status)
+	assert.Nil(t, po.ReceivedAt)
+
+	assert.NoError(t, po.Receive([]Receipt{{ProductID: coffee, Quantity: 6}, {ProductID: tea, Quantity: 5}}))
+	assert.Equal(t, StatusReceived, po.Status)
+	assert.NotNil(t, po.ReceivedAt)
+
+	assert.ErrorIs(t, po.Receive([]Receipt{{ProductID: tea, Quantity: 1}}), ErrInvalidStatusTransition)
+}
@@ -0,0 +1,26 @@
+package purchaseorder
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListFilter narrows purchase order listings. Zero values match every order.
+type ListFilter struct {
+	StoreID    *primitive.ObjectID
+	SupplierID *primitive.ObjectID
+	Status     Status
+}
+
+type Repository interface {
+	Create(ctx context.Context, po *PurchaseOrder) error
+	GetByID(ctx context.Context, id string) (*PurchaseOrder, error)
+	// Update saves the order if its stored version still matches and bumps
+	// the version, failing with ErrConcurrentUpdate otherwise
+	Update(ctx context.Context, po *PurchaseOrder) error
+	List(ctx context.Context, filter ListFilter, page, limit int) ([]*PurchaseOrder, int, error)
+	// ListOpen returns every order, across all stores, that has been sent
+	// but not received in full
+	ListOpen(ctx context.Context) ([]*PurchaseOrder, error)
+}
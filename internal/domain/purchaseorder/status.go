@@ -0,0 +1,31 @@
+package purchaseorder
+
+import "errors"
+
+var (
+	ErrInvalidStatus           = errors.New("status must be one of draft, sent, partially_received or received")
+	ErrInvalidStatusTransition = errors.New("purchase order status transition not allowed")
+)
+
+// Status is the lifecycle state of a purchase order
+type Status string
+
+const (
+	StatusDraft             Status = "draft"
+	StatusSent              Status = "sent"
+	StatusPartiallyReceived Status = "partially_received"
+	StatusReceived          Status = "received"
+)
+
+func ParseStatus(s string) (Status, error) {
+	switch Status(s) {
+	case StatusDraft, StatusSent, StatusPartiallyReceived, StatusReceived:
+		return Status(s), nil
+	}
+	return "", ErrInvalidStatus
+}
+
+// Receivable reports whether goods can still be received against the order
+func (s Status) Receivable() bool {
+	return s == StatusSent || s == StatusPartiallyReceived
+}
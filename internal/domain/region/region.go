@@ -0,0 +1,139 @@
+package region
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidRegionName = errors.New("region name cannot be empty")
+	ErrRegionNotFound    = errors.New("region not found")
+	ErrInvalidParent     = errors.New("region cannot be moved under itself or one of its descendants")
+	ErrRegionHasChildren = errors.New("region has child regions")
+	ErrRegionHasStores   = errors.New("region has stores assigned")
+	ErrRegionChanged     = errors.New("region hierarchy was changed concurrently")
+)
+
+// Region is a node in the store network hierarchy, such as a sales region or
+// a district within it. Ancestors holds the IDs of every region above this
+// one, root first, so that whole subtrees can be selected with one query.
+type Region struct {
+	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name      string               `bson:"name" json:"name"`
+	ParentID  *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Ancestors []primitive.ObjectID `bson:"ancestors" json:"ancestors"`
+	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// NewRegion creates a region under parent, or a root region if parent is nil
+func NewRegion(name string, parent *Region) (*Region, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidRegionName
+	}
+
+	now := time.Now()
+	r := &Region{
+		ID:        primitive.NewObjectID(),
+		Name:      name,
+		Ancestors: make([]primitive.ObjectID, 0),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.setParent(parent)
+	return r, nil
+}
+
+func (r *Region) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidRegionName
+	}
+	r.Name = name
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// MoveTo re-parents the region, or makes it a root region if parent is nil.
+// The repository rebases its descendants when the move is saved.
+func (r *Region) MoveTo(parent *Region) error {
+	if parent != nil && (parent.ID == r.ID || r.IsAncestorOf(parent)) {
+		return ErrInvalidParent
+	}
+	r.setParent(parent)
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsAncestorOf reports whether other lies in the subtree below r
+func (r *Region) IsAncestorOf(other *Region) bool {
+	for _, id := range other.Ancestors {
+		if id == r.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Region) setParent(parent *Region) {
+	if parent == nil {
+		r.ParentID = nil
+		r.Ancestors = make([]primitive.ObjectID, 0)
+		return
+	}
+	parentID := parent.ID
+	r.ParentID = &parentID
+	r.Ancestors = append(append([]primitive.ObjectID{}, parent.Ancestors...), parent.ID)
+}
+
+// Node is a region with its child regions, used to render the hierarchy
+type Node struct {
+	*Region
+	Children []*Node `json:"children"`
+}
+
+// BuildTree arranges regions into their hierarchy, returning the roots.
+// Regions whose parent is not in the list are treated as roots, so a subtree
+// can be rendered from its descendants. Siblings are sorted by name.
+func BuildTree(regions []*Region) []*Node {
+	nodes := make(map[primitive.ObjectID]*Node, len(regions))
+	for _, r := range regions {
+		nodes[r.ID] = &Node{Region: r, Children: make([]*Node, 0)}
+	}
+
+	roots := make([]*Node, 0)
+	for _, r := range regions {
+		node := nodes[r.ID]
+		if r.ParentID != nil {
+			if parent, ok := nodes[*r.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	sortNodes(roots)
+	return roots
+}
+
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+}
+
+// Stats are aggregate figures over all stores in a region's subtree
+type Stats struct {
+	RegionID             string `json:"region_id"`
+	StoreCount           int    `json:"store_count"`
+	DistinctProductCount int    `json:"distinct_product_count"`
+}
@@ -0,0 +1,70 @@
+package region
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewRegion(t *testing.T) {
+	root, err := NewRegion("  Europe ", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe", root.Name)
+	assert.Nil(t, root.ParentID)
+	assert.Empty(t, root.Ancestors)
+
+	child, err := NewRegion("Germany", root)
+	assert.NoError(t, err)
+	assert.Equal(t, root.ID, *child.ParentID)
+	assert.Equal(t, []primitive.ObjectID{root.ID}, child.Ancestors)
+
+	grandchild, err := NewRegion("Berlin", child)
+	assert.NoError(t, err)
+	assert.Equal(t, []primitive.ObjectID{root.ID, child.ID}, grandchild.Ancestors)
+	assert.True(t, root.IsAncestorOf(grandchild))
+	assert.False(t, grandchild.IsAncestorOf(root))
+
+	_, err = NewRegion(" ", nil)
+	assert.ErrorIs(t, err, ErrInvalidRegionName)
+}
+
+func TestMoveRegion(t *testing.T) {
+	europe, _ := NewRegion("Europe", nil)
+	germany, _ := NewRegion("Germany", europe)
+	berlin, _ := NewRegion("Berlin", germany)
+	dach, _ := NewRegion("DACH", europe)
+
+	assert.ErrorIs(t, germany.MoveTo(germany), ErrInvalidParent)
+	assert.ErrorIs(t, germany.MoveTo(berlin), ErrInvalidParent)
+
+	assert.NoError(t, germany.MoveTo(dach))
+	assert.Equal(t, dach.ID, *germany.ParentID)
+	assert.Equal(t, []primitive.ObjectID{europe.ID, dach.ID}, germany.Ancestors)
+
+	assert.NoError(t, germany.MoveTo(nil))
+	assert.Nil(t, germany.ParentID)
+	assert.Empty(t, germany.Ancestors)
+}
+
+func TestBuildTree(t *testing.T) {
+	europe, _ := NewRegion("Europe", nil)
+	asia, _ := NewRegion("Asia", nil)
+	germany, _ := NewRegion("Germany", europe)
+	austria, _ := NewRegion("Austria", europe)
+	berlin, _ := NewRegion("Berlin", germany)
+
+	roots := BuildTree([]*Region{berlin, germany, europe, austria, asia})
+	assert.Len(t, roots, 2)
+	assert.Equal(t, "Asia", roots[0].Name)
+	assert.Equal(t, "Europe", roots[1].Name)
+	assert.Len(t, roots[1].Children, 2)
+	assert.Equal(t, "Austria", roots[1].Children[0].Name)
+	assert.Equal(t, "Germany", roots[1].Children[1].Name)
+	assert.Len(t, roots[1].Children[1].Children, 1)
+	assert.Equal(t, "Berlin", roots[1].Children[1].Children[0].Name)
+
+	subtree := BuildTree([]*Region{berlin, germany})
+	assert.Len(t, subtree, 1)
+	assert.Equal(t, "Germany", subtree[0].Name)
+}
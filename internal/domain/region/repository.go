@@ -0,0 +1,22 @@
+package region
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Repository interface {
+	Create(ctx context.Context, region *Region) error
+	GetByID(ctx context.Context, id string) (*Region, error)
+	Update(ctx context.Context, region *Region) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*Region, error)
+	ListDescendants(ctx context.Context, id string) ([]*Region, error)
+	CountChildren(ctx context.Context, id string) (int, error)
+	// Move saves a region re-parented with MoveTo under parent, or as a
+	// root if parent is nil, and rebases its whole subtree, all or nothing.
+	// It fails with ErrRegionChanged unless the region still has the
+	// ancestors it had before the move and parent's ancestors are unchanged.
+	Move(ctx context.Context, moved *Region, previousAncestors []primitive.ObjectID, parent *Region) error
+}
@@ -0,0 +1,16 @@
+package reorder
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Repository interface {
+	// Replace stores the suggestions of a scan made at generatedAt and
+	// removes those of earlier scans
+	Replace(ctx context.Context, suggestions []*Suggestion, generatedAt time.Time) error
+	// ListByStore returns the store's suggestions from the latest scan
+	ListByStore(ctx context.Context, storeID primitive.ObjectID) ([]*Suggestion, error)
+}
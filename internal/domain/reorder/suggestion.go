@@ -0,0 +1,127 @@
+package reorder
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Line suggests ordering Quantity units of a product whose stock has fallen
+// to its reorder point
+type Line struct {
+	ProductID    primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Available    int                `bson:"available" json:"available"`
+	OnOrder      int                `bson:"on_order" json:"on_order"`
+	ReorderPoint int                `bson:"reorder_point" json:"reorder_point"`
+	Quantity     int                `bson:"quantity" json:"quantity"`
+	UnitCost     float64            `bson:"unit_cost,omitempty" json:"unit_cost,omitempty"`
+}
+
+// Suggestion groups the products a store should reorder from one supplier.
+// Products that no supplier sells are grouped under a nil SupplierID.
+type Suggestion struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	StoreID       primitive.ObjectID  `bson:"store_id" json:"store_id"`
+	SupplierID    *primitive.ObjectID `bson:"supplier_id" json:"supplier_id"`
+	SupplierName  string              `bson:"supplier_name,omitempty" json:"supplier_name,omitempty"`
+	Lines         []Line              `bson:"lines" json:"lines"`
+	EstimatedCost float64             `bson:"estimated_cost" json:"estimated_cost"`
+	GeneratedAt   time.Time           `bson:"generated_at" json:"generated_at"`
+}
+
+// StockKey identifies the stock of a product in a store
+type StockKey struct {
+	StoreID   primitive.ObjectID
+	ProductID primitive.ObjectID
+}
+
+// OnOrder totals the units still to be delivered on open purchase orders
+type OnOrder map[StockKey]int
+
+// Build turns items below their reorder point into suggestions per store and
+// supplier. Units already on order count as available, so a product is not
+// suggested again while a delivery is pending. Each product is ordered from
+// the supplier in suppliers with the lowest cost price, preferring the
+// shorter lead time on a tie.
+func Build(items []*inventory.StockItem, onOrder OnOrder, suppliers map[primitive.ObjectID][]*supplier.Supplier, at time.Time) []*Suggestion {
+	type groupKey struct {
+		storeID    primitive.ObjectID
+		supplierID primitive.ObjectID
+	}
+	groups := make(map[groupKey]*Suggestion)
+	var order []groupKey
+
+	for _, item := range items {
+		pending := onOrder[StockKey{StoreID: item.StoreID, ProductID: item.ProductID}]
+		if !item.BelowReorderPoint(pending) {
+			continue
+		}
+
+		sup, cost := cheapest(suppliers[item.ProductID], item.ProductID)
+		key := groupKey{storeID: item.StoreID}
+		if sup != nil {
+			key.supplierID = sup.ID
+		}
+
+		suggestion, ok := groups[key]
+		if !ok {
+			suggestion = &Suggestion{StoreID: item.StoreID, Lines: []Line{}, GeneratedAt: at}
+			if sup != nil {
+				id := sup.ID
+				suggestion.SupplierID = &id
+				suggestion.SupplierName = sup.Name
+			}
+			groups[key] = suggestion
+			order = append(order, key)
+		}
+
+		suggestion.Lines = append(suggestion.Lines, Line{
+			ProductID:    item.ProductID,
+			Available:    item.Available(),
+			OnOrder:      pending,
+			ReorderPoint: item.ReorderPoint,
+			Quantity:     item.ReorderQuantity,
+			UnitCost:     cost,
+		})
+		suggestion.EstimatedCost = math.Round((suggestion.EstimatedCost+cost*float64(item.ReorderQuantity))*100) / 100
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].storeID.Hex() < order[j].storeID.Hex()
+	})
+
+	suggestions := make([]*Suggestion, 0, len(order))
+	for _, key := range order {
+		suggestions = append(suggestions, groups[key])
+	}
+	return suggestions
+}
+
+// CountByStore returns the number of products below their reorder point in
+// each store
+func CountByStore(suggestions []*Suggestion) map[primitive.ObjectID]int {
+	counts := make(map[primitive.ObjectID]int)
+	for _, s := range suggestions {
+		counts[s.StoreID] += len(s.Lines)
+	}
+	return counts
+}
+
+func cheapest(suppliers []*supplier.Supplier, productID primitive.ObjectID) (*supplier.Supplier, float64) {
+	var best *supplier.Supplier
+	var bestCost float64
+	for _, s := range suppliers {
+		cost, err := s.CostFor(productID)
+		if err != nil {
+			continue
+		}
+		if best == nil || cost < bestCost || (cost == bestCost && s.LeadTimeDays < best.LeadTimeDays) {
+			best, bestCost = s, cost
+		}
+	}
+	return best, bestCost
+}
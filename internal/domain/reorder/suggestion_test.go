@@ -0,0 +1,58 @@
+package reorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newSupplier(t *testing.T, name string, leadTime int, costs map[primitive.ObjectID]float64) *supplier.Supplier {
+	s, err := supplier.NewSupplier(name, supplier.Contact{}, leadTime)
+	assert.NoError(t, err)
+	for productID, cost := range costs {
+		assert.NoError(t, s.SetProduct(productID, cost))
+	}
+	return s
+}
+
+func TestBuild(t *testing.T) {
+	storeID := primitive.NewObjectID()
+	coffee, tea, sugar, salt := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	acme := newSupplier(t, "Acme", 5, map[primitive.ObjectID]float64{coffee: 2.00, tea: 1.00})
+	fast := newSupplier(t, "Fast", 1, map[primitive.ObjectID]float64{coffee: 2.00})
+	cheap := newSupplier(t, "Cheap", 9, map[primitive.ObjectID]float64{tea: 0.80})
+
+	items := []*inventory.StockItem{
+		{StoreID: storeID, ProductID: coffee, OnHand: 5, Reserved: 2, ReorderPoint: 3, ReorderQuantity: 20},
+		{StoreID: storeID, ProductID: tea, OnHand: 1, ReorderPoint: 4, ReorderQuantity: 10},
+		{StoreID: storeID, ProductID: sugar, OnHand: 0, ReorderPoint: 2, ReorderQuantity: 6},
+		{StoreID: storeID, ProductID: salt, OnHand: 1, ReorderPoint: 2, ReorderQuantity: 6},
+	}
+	onOrder := OnOrder{{StoreID: storeID, ProductID: salt}: 6}
+	suppliers := map[primitive.ObjectID][]*supplier.Supplier{
+		coffee: {acme, fast},
+		tea:    {acme, cheap},
+	}
+
+	suggestions := Build(items, onOrder, suppliers, time.Now())
+	assert.Len(t, suggestions, 3)
+
+	assert.Equal(t, fast.ID, *suggestions[0].SupplierID)
+	assert.Equal(t, coffee, suggestions[0].Lines[0].ProductID)
+	assert.Equal(t, 3, suggestions[0].Lines[0].Available)
+	assert.Equal(t, 40.0, suggestions[0].EstimatedCost)
+
+	assert.Equal(t, cheap.ID, *suggestions[1].SupplierID)
+	assert.Equal(t, 8.0, suggestions[1].EstimatedCost)
+
+	assert.Nil(t, suggestions[2].SupplierID)
+	assert.Len(t, suggestions[2].Lines, 1)
+	assert.Equal(t, sugar, suggestions[2].Lines[0].ProductID)
+
+	assert.Equal(t, map[primitive.ObjectID]int{storeID: 3}, CountByStore(suggestions))
+}
@@ -0,0 +1,31 @@
+package review
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListFilter narrows review listings. Zero values match every review.
+type ListFilter struct {
+	ProductID *primitive.ObjectID
+	Status    Status
+}
+
+type Repository interface {
+	Create(ctx context.Context, review *Review) error
+	GetByID(ctx context.Context, id string) (*Review, error)
+	// UpdateStatus saves a moderation decision, failing with
+	// ErrConcurrentUpdate if the stored review no longer has the status
+	// previous.
+	UpdateStatus(ctx context.Context, review *Review, previous Status) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filter ListFilter, page, limit int) ([]*Review, int, error)
+}
+
+// RatingWriter maintains a product's rating summary on the product read side
+type RatingWriter interface {
+	// RefreshRating recomputes the product's rating from its approved
+	// reviews and stores it in one write. Deleted products are skipped.
+	RefreshRating(ctx context.Context, productID primitive.ObjectID) error
+}
@@ -0,0 +1,108 @@
+package review
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxTextLength is the longest review text accepted, in characters
+const MaxTextLength = 5000
+
+var (
+	ErrReviewNotFound          = errors.New("review not found")
+	ErrInvalidRating           = errors.New("rating must be between 1 and 5")
+	ErrInvalidAuthor           = errors.New("review author cannot be empty")
+	ErrTextTooLong             = errors.New("review text is too long")
+	ErrInvalidStatus           = errors.New("status must be one of pending, approved or rejected")
+	ErrInvalidStatusTransition = errors.New("review status transition not allowed")
+	ErrConcurrentUpdate        = errors.New("review was modified concurrently")
+)
+
+// Status is the moderation state of a review
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+func ParseStatus(s string) (Status, error) {
+	switch Status(s) {
+	case StatusPending, StatusApproved, StatusRejected:
+		return Status(s), nil
+	}
+	return "", ErrInvalidStatus
+}
+
+// Review is a customer's rating of a product. New reviews are pending and
+// only count towards the product's rating once approved.
+type Review struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProductID       primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Author          string             `bson:"author" json:"author"`
+	Rating          int                `bson:"rating" json:"rating"`
+	Text            string             `bson:"text,omitempty" json:"text,omitempty"`
+	Status          Status             `bson:"status" json:"status"`
+	RejectionReason string             `bson:"rejection_reason,omitempty" json:"rejection_reason,omitempty"`
+	ModeratedAt     *time.Time         `bson:"moderated_at,omitempty" json:"moderated_at,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+func NewReview(productID primitive.ObjectID, author string, rating int, text string) (*Review, error) {
+	author = strings.TrimSpace(author)
+	if author == "" {
+		return nil, ErrInvalidAuthor
+	}
+	if rating < 1 || rating > 5 {
+		return nil, ErrInvalidRating
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return nil, ErrTextTooLong
+	}
+
+	now := time.Now()
+	return &Review{
+		ID:        primitive.NewObjectID(),
+		ProductID: productID,
+		Author:    author,
+		Rating:    rating,
+		Text:      text,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Approve publishes the review. A rejected review can be approved on appeal.
+func (r *Review) Approve() error {
+	if r.Status == StatusApproved {
+		return ErrInvalidStatusTransition
+	}
+	r.RejectionReason = ""
+	r.moderate(StatusApproved)
+	return nil
+}
+
+// Reject hides the review, including one that was approved earlier
+func (r *Review) Reject(reason string) error {
+	if r.Status == StatusRejected {
+		return ErrInvalidStatusTransition
+	}
+	r.RejectionReason = strings.TrimSpace(reason)
+	r.moderate(StatusRejected)
+	return nil
+}
+
+func (r *Review) moderate(status Status) {
+	now := time.Now()
+	r.Status = status
+	r.ModeratedAt = &now
+	r.UpdatedAt = now
+}
@@ -0,0 +1,54 @@
+package review
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewReviewValidation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		author  string
+		rating  int
+		text    string
+		wantErr error
+	}{
+		{name: "valid", author: "Sam", rating: 5, text: "Great coffee"},
+		{name: "no text", author: "Sam", rating: 1},
+		{name: "blank author", author: " ", rating: 3, wantErr: ErrInvalidAuthor},
+		{name: "rating too low", author: "Sam", rating: 0, wantErr: ErrInvalidRating},
+		{name: "rating too high", author: "Sam", rating: 6, wantErr: ErrInvalidRating},
+		{name: "text too long", author: "Sam", rating: 4, text: strings.Repeat("a", MaxTextLength+1), wantErr: ErrTextTooLong},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReview(primitive.NewObjectID(), tc.author, tc.rating, tc.text)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, StatusPending, r.Status)
+		})
+	}
+}
+
+func TestReviewModeration(t *testing.T) {
+	r, err := NewReview(primitive.NewObjectID(), "Sam", 4, "Good")
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Reject("spam"))
+	assert.Equal(t, StatusRejected, r.Status)
+	assert.Equal(t, "spam", r.RejectionReason)
+	assert.NotNil(t, r.ModeratedAt)
+	assert.ErrorIs(t, r.Reject("again"), ErrInvalidStatusTransition)
+
+	assert.NoError(t, r.Approve())
+	assert.Equal(t, StatusApproved, r.Status)
+	assert.Empty(t, r.RejectionReason)
+	assert.ErrorIs(t, r.Approve(), ErrInvalidStatusTransition)
+}
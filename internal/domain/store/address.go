@@ -0,0 +1,128 @@
+package store
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidAddressLines = errors.New("address must have between 1 and 3 non-empty lines")
+	ErrInvalidCity         = errors.New("city cannot be empty")
+	ErrInvalidCountry      = errors.New("country must be an ISO 3166-1 alpha-2 code")
+	ErrInvalidPostalCode   = errors.New("postal code is not valid for country")
+)
+
+const maxAddressLines = 3
+
+// Address is a structured postal address. Country is an upper-case ISO 3166-1
+// alpha-2 code.
+type Address struct {
+	Lines      []string `bson:"lines" json:"lines"`
+	City       string   `bson:"city" json:"city"`
+	Region     string   `bson:"region,omitempty" json:"region,omitempty"`
+	PostalCode string   `bson:"postal_code,omitempty" json:"postal_code,omitempty"`
+	Country    string   `bson:"country" json:"country"`
+}
+
+// postalCodePatterns holds the postal code format of countries we validate.
+// Countries without an entry accept any postal code, including none.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"CZ": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FI": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IE": regexp.MustCompile(`^[A-Z]\d[\dW] ?[A-Z\d]{4}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"RU": regexp.MustCompile(`^\d{6}$`),
+	"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// countryCodes lists every officially assigned ISO 3166-1 alpha-2 code
+var countryCodes = func() map[string]bool {
+	codes := map[string]bool{}
+	for _, code := range strings.Fields(`
+		AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS
+		BT BV BW BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE
+		EG EH ER ES ET FI FJ FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM
+		HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC
+		LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ NA
+		NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW
+		SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO
+		TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`) {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// NewAddress validates and normalises a postal address. Surrounding
+// whitespace is trimmed and country and postal code are upper-cased.
+func NewAddress(lines []string, city, region, postalCode, country string) (*Address, error) {
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+	if len(cleaned) == 0 || len(cleaned) > maxAddressLines {
+		return nil, ErrInvalidAddressLines
+	}
+
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return nil, ErrInvalidCity
+	}
+
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if !countryCodes[country] {
+		return nil, ErrInvalidCountry
+	}
+
+	postalCode = strings.ToUpper(strings.TrimSpace(postalCode))
+	if pattern, ok := postalCodePatterns[country]; ok && !pattern.MatchString(postalCode) {
+		return nil, ErrInvalidPostalCode
+	}
+
+	return &Address{
+		Lines:      cleaned,
+		City:       city,
+		Region:     strings.TrimSpace(region),
+		PostalCode: postalCode,
+		Country:    country,
+	}, nil
+}
+
+// String formats the address on a single line, e.g.
+// "1 Main St, Suite 2, 10115 Berlin, DE".
+func (a *Address) String() string {
+	parts := append([]string{}, a.Lines...)
+
+	locality := a.City
+	if a.PostalCode != "" {
+		locality = a.PostalCode + " " + a.City
+	}
+	parts = append(parts, locality)
+
+	if a.Region != "" {
+		parts = append(parts, a.Region)
+	}
+	parts = append(parts, a.Country)
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAddress(t *testing.T) {
+	testCases := []struct {
+		name       string
+		lines      []string
+		city       string
+		postalCode string
+		country    string
+		wantErr    error
+		wantString string
+	}{
+		{
+			name:       "valid german address",
+			lines:      []string{"Friedrichstr. 1"},
+			city:       "Berlin",
+			postalCode: "10117",
+			country:    "de",
+			wantString: "Friedrichstr. 1, 10117 Berlin, DE",
+		},
+		{
+			name:       "valid british address is normalised",
+			lines:      []string{" 10 Downing Street ", ""},
+			city:       "London",
+			postalCode: "sw1a 2aa",
+			country:    "GB",
+			wantString: "10 Downing Street, SW1A 2AA London, GB",
+		},
+		{
+			name:       "country without postal code format",
+			lines:      []string{"1 Queen's Road"},
+			city:       "Hong Kong",
+			country:    "HK",
+			wantString: "1 Queen's Road, Hong Kong, HK",
+		},
+		{
+			name:       "invalid us zip code",
+			lines:      []string{"1 Main St"},
+			city:       "Springfield",
+			postalCode: "1234",
+			country:    "US",
+			wantErr:    ErrInvalidPostalCode,
+		},
+		{
+			name:    "missing postal code where required",
+			lines:   []string{"1 Rue de Rivoli"},
+			city:    "Paris",
+			country: "FR",
+			wantErr: ErrInvalidPostalCode,
+		},
+		{
+			name:       "unknown country",
+			lines:      []string{"1 Main St"},
+			city:       "Nowhere",
+			postalCode: "12345",
+			country:    "XX",
+			wantErr:    ErrInvalidCountry,
+		},
+		{
+			name:       "no lines",
+			lines:      []string{" "},
+			city:       "Berlin",
+			postalCode: "10117",
+			country:    "DE",
+			wantErr:    ErrInvalidAddressLines,
+		},
+		{
+			name:       "empty city",
+			lines:      []string{"Friedrichstr. 1"},
+			postalCode: "10117",
+			country:    "DE",
+			wantErr:    ErrInvalidCity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			address, err := NewAddress(tc.lines, tc.city, "", tc.postalCode, tc.country)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, address)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantString, address.String())
+		})
+	}
+}
+
+func TestUpdatePostalAddress(t *testing.T) {
+	store, err := NewStore("Test Store", "Old Address")
+	assert.NoError(t, err)
+
+	address, err := NewAddress([]string{"Friedrichstr. 1"}, "Berlin", "", "10117", "DE")
+	assert.NoError(t, err)
+
+	err = store.UpdatePostalAddress(address)
+	assert.NoError(t, err)
+	assert.Equal(t, address, store.PostalAddress)
+	assert.Equal(t, "Friedrichstr. 1, 10117 Berlin, DE", store.Address)
+
+	// A legacy free-text update drops the structured address
+	err = store.UpdateAddress("Somewhere else")
+	assert.NoError(t, err)
+	assert.Nil(t, store.PostalAddress)
+	assert.Equal(t, "Somewhere else", store.Address)
+}
+
+func TestNewStoreWithAddress(t *testing.T) {
+	address, err := NewAddress([]string{"1 Main St"}, "Springfield", "IL", "62701", "US")
+	assert.NoError(t, err)
+
+	store, err := NewStoreWithAddress("Test Store", address)
+	assert.NoError(t, err)
+	assert.Equal(t, "1 Main St, 62701 Springfield, IL, US", store.Address)
+	assert.Equal(t, address, store.PostalAddress)
+
+	_, err = NewStoreWithAddress("Test Store", nil)
+	assert.ErrorIs(t, err, ErrInvalidStoreAddress)
+}
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTimeZone      = errors.New("invalid time zone")
+	ErrInvalidWeekday       = errors.New("weekday must be a lowercase English day name")
+	ErrInvalidTimeOfDay     = errors.New("time must be in HH:MM format between 00:00 and 24:00")
+	ErrInvalidTimeRange     = errors.New("opening time range must close after it opens")
+	ErrOverlappingRanges    = errors.New("opening time ranges overlap")
+	ErrInvalidExceptionDate = errors.New("exception date must be in YYYY-MM-DD format")
+	ErrInvalidException     = errors.New("exception must either be closed or have opening time ranges")
+	ErrOpeningHoursNotSet   = errors.New("store has no opening hours")
+	ErrExceptionNotFound    = errors.New("opening hours exception not found")
+)
+
+const dateLayout = "2006-01-02"
+
+// TimeRange is a period of a day during which a store is open, as local wall
+// clock times in HH:MM format. Close may be "24:00" for ranges that run until
+// midnight; ranges crossing midnight must be split across two days.
+type TimeRange struct {
+	Open  string `bson:"open" json:"open"`
+	Close string `bson:"close" json:"close"`
+}
+
+// ExceptionDay overrides the weekly hours on a specific date, either closing
+// the store for the day or replacing its opening ranges.
+type ExceptionDay struct {
+	Date   string      `bson:"date" json:"date"`
+	Closed bool        `bson:"closed" json:"closed"`
+	Ranges []TimeRange `bson:"ranges,omitempty" json:"ranges,omitempty"`
+	Note   string      `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+// OpeningHours describes when a store is open. Weekly is keyed by lowercase
+// weekday name; days without an entry are closed. Times are interpreted in
+// TimeZone, an IANA time zone name.
+type OpeningHours struct {
+	TimeZone   string                 `bson:"time_zone" json:"time_zone"`
+	Weekly     map[string][]TimeRange `bson:"weekly" json:"weekly"`
+	Exceptions []ExceptionDay         `bson:"exceptions,omitempty" json:"exceptions,omitempty"`
+}
+
+var weekdays = map[string]bool{
+	"sunday":    true,
+	"monday":    true,
+	"tuesday":   true,
+	"wednesday": true,
+	"thursday":  true,
+	"friday":    true,
+	"saturday":  true,
+}
+
+func NewOpeningHours(timeZone string, weekly map[string][]TimeRange) (*OpeningHours, error) {
+	if timeZone == "" {
+		return nil, ErrInvalidTimeZone
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+
+	normalized := make(map[string][]TimeRange, len(weekly))
+	for day, ranges := range weekly {
+		if !weekdays[day] {
+			return nil, ErrInvalidWeekday
+		}
+		sorted, err := normalizeRanges(ranges)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", day, err)
+		}
+		if len(sorted) > 0 {
+			normalized[day] = sorted
+		}
+	}
+
+	return &OpeningHours{
+		TimeZone: timeZone,
+		Weekly:   normalized,
+	}, nil
+}
+
+func NewExceptionDay(date string, closed bool, ranges []TimeRange, note string) (*ExceptionDay, error) {
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return nil, ErrInvalidExceptionDate
+	}
+	if closed == (len(ranges) > 0) {
+		return nil, ErrInvalidException
+	}
+
+	sorted, err := normalizeRanges(ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExceptionDay{
+		Date:   date,
+		Closed: closed,
+		Ranges: sorted,
+		Note:   strings.TrimSpace(note),
+	}, nil
+}
+
+// IsOpenAt reports whether t falls within the opening hours, taking
+// exceptions for t's local date into account.
+func (h *OpeningHours) IsOpenAt(t time.Time) bool {
+	loc, err := time.LoadLocation(h.TimeZone)
+	if err != nil {
+		return false
+	}
+	local := t.In(loc)
+
+	ranges := h.rangesOn(local)
+	minute := local.Hour()*60 + local.Minute()
+	for _, r := range ranges {
+		open, _ := parseTimeOfDay(r.Open)
+		closeAt, _ := parseTimeOfDay(r.Close)
+		if minute >= open && minute < closeAt {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *OpeningHours) rangesOn(local time.Time) []TimeRange {
+	date := local.Format(dateLayout)
+	for _, e := range h.Exceptions {
+		if e.Date == date {
+			return e.Ranges
+		}
+	}
+	return h.Weekly[strings.ToLower(local.Weekday().String())]
+}
+
+func (h *OpeningHours) setException(day *ExceptionDay) {
+	for i, e := range h.Exceptions {
+		if e.Date == day.Date {
+			h.Exceptions[i] = *day
+			return
+		}
+	}
+	h.Exceptions = append(h.Exceptions, *day)
+	sort.Slice(h.Exceptions, func(i, j int) bool {
+		return h.Exceptions[i].Date < h.Exceptions[j].Date
+	})
+}
+
+func (h *OpeningHours) removeException(date string) error {
+	for i, e := range h.Exceptions {
+		if e.Date == date {
+			h.Exceptions = append(h.Exceptions[:i], h.Exceptions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrExceptionNotFound
+}
+
+// normalizeRanges validates time ranges and returns them sorted by opening
+// time, rejecting ranges that overlap.
+func normalizeRanges(ranges []TimeRange) ([]TimeRange, error) {
+	sorted := append([]TimeRange(nil), ranges...)
+	for _, r := range sorted {
+		open, err := parseTimeOfDay(r.Open)
+		if err != nil {
+			return nil, err
+		}
+		closeAt, err := parseTimeOfDay(r.Close)
+		if err != nil {
+			return nil, err
+		}
+		if closeAt <= open {
+			return nil, ErrInvalidTimeRange
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		a, _ := parseTimeOfDay(sorted[i].Open)
+		b, _ := parseTimeOfDay(sorted[j].Open)
+		return a < b
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		prevClose, _ := parseTimeOfDay(sorted[i-1].Close)
+		open, _ := parseTimeOfDay(sorted[i].Open)
+		if open < prevClose {
+			return nil, ErrOverlappingRanges
+		}
+	}
+	return sorted, nil
+}
+
+// parseTimeOfDay converts HH:MM to minutes after midnight
+func parseTimeOfDay(s string) (int, error) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, ErrInvalidTimeOfDay
+	}
+	for _, i := range []int{0, 1, 3, 4} {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, ErrInvalidTimeOfDay
+		}
+	}
+
+	hour := int(s[0]-'0')*10 + int(s[1]-'0')
+	minute := int(s[3]-'0')*10 + int(s[4]-'0')
+	if minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, ErrInvalidTimeOfDay
+	}
+	return hour*60 + minute, nil
+}
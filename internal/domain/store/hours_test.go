@@ -0,0 +1,199 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOpeningHours(t *testing.T) {
+	testCases := []struct {
+		name     string
+		timeZone string
+		weekly   map[string][]TimeRange
+		wantErr  error
+	}{
+		{
+			name:     "split day",
+			timeZone: "Europe/Berlin",
+			weekly: map[string][]TimeRange{
+				"monday": {{Open: "14:00", Close: "20:00"}, {Open: "09:00", Close: "13:00"}},
+			},
+		},
+		{
+			name:     "open until midnight",
+			timeZone: "UTC",
+			weekly:   map[string][]TimeRange{"friday": {{Open: "18:00", Close: "24:00"}}},
+		},
+		{
+			name:     "unknown time zone",
+			timeZone: "Mars/Olympus",
+			weekly:   map[string][]TimeRange{},
+			wantErr:  ErrInvalidTimeZone,
+		},
+		{
+			name:     "unknown weekday",
+			timeZone: "UTC",
+			weekly:   map[string][]TimeRange{"Mon": {{Open: "09:00", Close: "17:00"}}},
+			wantErr:  ErrInvalidWeekday,
+		},
+		{
+			name:     "malformed time",
+			timeZone: "UTC",
+			weekly:   map[string][]TimeRange{"monday": {{Open: "9:00", Close: "17:00"}}},
+			wantErr:  ErrInvalidTimeOfDay,
+		},
+		{
+			name:     "closes before opening",
+			timeZone: "UTC",
+			weekly:   map[string][]TimeRange{"monday": {{Open: "22:00", Close: "02:00"}}},
+			wantErr:  ErrInvalidTimeRange,
+		},
+		{
+			name:     "overlapping ranges",
+			timeZone: "UTC",
+			weekly: map[string][]TimeRange{
+				"monday": {{Open: "09:00", Close: "13:00"}, {Open: "12:30", Close: "18:00"}},
+			},
+			wantErr: ErrOverlappingRanges,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hours, err := NewOpeningHours(tc.timeZone, tc.weekly)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, hours)
+				return
+			}
+
+			assert.NoError(t, err)
+			for _, ranges := range hours.Weekly {
+				for i := 1; i < len(ranges); i++ {
+					assert.Less(t, ranges[i-1].Open, ranges[i].Open)
+				}
+			}
+		})
+	}
+}
+
+func TestNewExceptionDay(t *testing.T) {
+	_, err := NewExceptionDay("2026-12-25", true, nil, "Christmas")
+	assert.NoError(t, err)
+
+	_, err = NewExceptionDay("2026-12-24", false, []TimeRange{{Open: "09:00", Close: "12:00"}}, "")
+	assert.NoError(t, err)
+
+	_, err = NewExceptionDay("25.12.2026", true, nil, "")
+	assert.ErrorIs(t, err, ErrInvalidExceptionDate)
+
+	_, err = NewExceptionDay("2026-12-25", false, nil, "")
+	assert.ErrorIs(t, err, ErrInvalidException)
+
+	_, err = NewExceptionDay("2026-12-25", true, []TimeRange{{Open: "09:00", Close: "12:00"}}, "")
+	assert.ErrorIs(t, err, ErrInvalidException)
+}
+
+func TestStoreIsOpenAt(t *testing.T) {
+	store, err := NewStore("Test Store", "123 Test St")
+	assert.NoError(t, err)
+
+	_, err = store.IsOpenAt(time.Now())
+	assert.ErrorIs(t, err, ErrOpeningHoursNotSet)
+
+	hours, err := NewOpeningHours("Europe/Berlin", map[string][]TimeRange{
+		"monday":   {{Open: "09:00", Close: "13:00"}, {Open: "14:00", Close: "20:00"}},
+		"thursday": {{Open: "09:00", Close: "20:00"}},
+		"friday":   {{Open: "09:00", Close: "20:00"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, store.UpdateOpeningHours(hours))
+	assert.NoError(t, store.Open())
+
+	christmas, err := NewExceptionDay("2026-12-25", true, nil, "Christmas")
+	assert.NoError(t, err)
+	assert.NoError(t, store.SetOpeningException(christmas))
+
+	shortDay, err := NewExceptionDay("2026-12-24", false, []TimeRange{{Open: "09:00", Close: "12:00"}}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, store.SetOpeningException(shortDay))
+
+	testCases := []struct {
+		name string
+		at   string
+		want bool
+	}{
+		// 2026-12-21 is a Monday; Berlin is UTC+1 in winter
+		{name: "monday morning", at: "2026-12-21T08:30:00Z", want: true},
+		{name: "monday lunch break", at: "2026-12-21T12:30:00Z", want: false},
+		{name: "closing time is exclusive", at: "2026-12-21T19:00:00Z", want: false},
+		{name: "tuesday closed", at: "2026-12-22T10:00:00Z", want: false},
+		{name: "special hours morning", at: "2026-12-24T09:00:00Z", want: true},
+		{name: "special hours afternoon", at: "2026-12-24T13:00:00Z", want: false},
+		{name: "holiday", at: "2026-12-25T10:00:00Z", want: false},
+		{name: "evaluated in store time zone", at: "2026-12-21T07:59:00-01:00", want: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tc.at)
+			assert.NoError(t, err)
+
+			open, err := store.IsOpenAt(at)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, open)
+		})
+	}
+}
+
+func TestUpdateOpeningHoursKeepsExceptions(t *testing.T) {
+	store, err := NewStore("Test Store", "123 Test St")
+	assert.NoError(t, err)
+
+	hours, err := NewOpeningHours("UTC", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, store.UpdateOpeningHours(hours))
+
+	holiday, err := NewExceptionDay("2026-01-01", true, nil, "")
+	assert.NoError(t, err)
+	assert.NoError(t, store.SetOpeningException(holiday))
+
+	replacement, err := NewOpeningHours("Europe/Paris", map[string][]TimeRange{"monday": {{Open: "08:00", Close: "18:00"}}})
+	assert.NoError(t, err)
+	assert.NoError(t, store.UpdateOpeningHours(replacement))
+	assert.Equal(t, "Europe/Paris", store.OpeningHours.TimeZone)
+	assert.Len(t, store.OpeningHours.Exceptions, 1)
+
+	assert.NoError(t, store.RemoveOpeningException("2026-01-01"))
+	assert.ErrorIs(t, store.RemoveOpeningException("2026-01-01"), ErrExceptionNotFound)
+}
+
+func TestSuspendedStoreIsNotOpen(t *testing.T) {
+	store, err := NewStore("Test Store", "123 Test St")
+	assert.NoError(t, err)
+
+	hours, err := NewOpeningHours("UTC", map[string][]TimeRange{
+		"monday": {{Open: "00:00", Close: "24:00"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, store.UpdateOpeningHours(hours))
+
+	monday, err := time.Parse(time.RFC3339, "2026-12-21T12:00:00Z")
+	assert.NoError(t, err)
+
+	open, err := store.IsOpenAt(monday)
+	assert.NoError(t, err)
+	assert.False(t, open, "planned store")
+
+	assert.NoError(t, store.Open())
+	open, err = store.IsOpenAt(monday)
+	assert.NoError(t, err)
+	assert.True(t, open)
+
+	assert.NoError(t, store.Suspend())
+	open, err = store.IsOpenAt(monday)
+	assert.NoError(t, err)
+	assert.False(t, open, "temporarily closed store")
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const earthRadiusKm = 6378.1
+
+// MaxRadiusKm is the widest radius a nearby query may search
+const MaxRadiusKm = 1000
+
+var (
+	ErrInvalidLocation = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	ErrInvalidRadius   = fmt.Errorf("radius must be greater than 0 and at most %d km", MaxRadiusKm)
+)
+
+// Location is a GeoJSON point. Coordinates are stored as [longitude, latitude],
+// the order required by MongoDB 2dsphere indexes.
+type Location struct {
+	Type        string    `bson:"type" json:"type"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates"`
+}
+
+func NewLocation(latitude, longitude float64) (*Location, error) {
+	if math.IsNaN(latitude) || math.IsNaN(longitude) ||
+		latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return nil, ErrInvalidLocation
+	}
+
+	return &Location{
+		Type:        "Point",
+		Coordinates: []float64{longitude, latitude},
+	}, nil
+}
+
+func (l *Location) Latitude() float64 {
+	return l.Coordinates[1]
+}
+
+func (l *Location) Longitude() float64 {
+	return l.Coordinates[0]
+}
+
+// DistanceKm returns the great-circle distance between two locations
+func (l *Location) DistanceKm(other *Location) float64 {
+	lat1 := l.Latitude() * math.Pi / 180
+	lat2 := other.Latitude() * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (other.Longitude() - l.Longitude()) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// NearbyQuery selects stores within RadiusKm of Origin, optionally only those
+// carrying ProductID. Filter narrows the stores further; its OpenAt is
+// ignored.
+type NearbyQuery struct {
+	Origin    *Location
+	RadiusKm  float64
+	ProductID *primitive.ObjectID
+	Filter    ListFilter
+}
+
+func NewNearbyQuery(latitude, longitude, radiusKm float64, productID *primitive.ObjectID) (*NearbyQuery, error) {
+	origin, err := NewLocation(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(radiusKm) || math.IsInf(radiusKm, 0) || radiusKm <= 0 || radiusKm > MaxRadiusKm {
+		return nil, ErrInvalidRadius
+	}
+
+	return &NearbyQuery{
+		Origin:    origin,
+		RadiusKm:  radiusKm,
+		ProductID: productID,
+	}, nil
+}
+
+// RadiusRadians converts the query radius to radians on the earth's surface,
+// the unit used by $centerSphere.
+func (q *NearbyQuery) RadiusRadians() float64 {
+	return q.RadiusKm / earthRadiusKm
+}
+
+// NearbyStore is a store matched by a NearbyQuery with its distance from the origin
+type NearbyStore struct {
+	Store      *Store  `json:"store"`
+	DistanceKm float64 `json:"distance_km"`
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLocation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		wantErr   error
+	}{
+		{
+			name:      "valid location",
+			latitude:  52.52,
+			longitude: 13.405,
+		},
+		{
+			name:      "latitude out of range",
+			latitude:  91,
+			longitude: 0,
+			wantErr:   ErrInvalidLocation,
+		},
+		{
+			name:      "longitude out of range",
+			latitude:  0,
+			longitude: -181,
+			wantErr:   ErrInvalidLocation,
+		},
+		{
+			name:      "not a number",
+			latitude:  math.NaN(),
+			longitude: 0,
+			wantErr:   ErrInvalidLocation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			location, err := NewLocation(tc.latitude, tc.longitude)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, location)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "Point", location.Type)
+			assert.Equal(t, []float64{tc.longitude, tc.latitude}, location.Coordinates)
+			assert.Equal(t, tc.latitude, location.Latitude())
+			assert.Equal(t, tc.longitude, location.Longitude())
+		})
+	}
+}
+
+func TestLocationDistanceKm(t *testing.T) {
+	berlin, err := NewLocation(52.5200, 13.4050)
+	assert.NoError(t, err)
+	paris, err := NewLocation(48.8566, 2.3522)
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 878, berlin.DistanceKm(paris), 5)
+	assert.InDelta(t, 0, berlin.DistanceKm(berlin), 0.001)
+}
+
+func TestNewNearbyQuery(t *testing.T) {
+	query, err := NewNearbyQuery(52.52, 13.405, 5, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 5/earthRadiusKm, query.RadiusRadians(), 1e-9)
+
+	for _, radius := range []float64{0, -5, math.NaN(), math.Inf(1), MaxRadiusKm + 1} {
+		_, err = NewNearbyQuery(52.52, 13.405, radius, nil)
+		assert.ErrorIs(t, err, ErrInvalidRadius, "radius %v", radius)
+	}
+
+	_, err = NewNearbyQuery(52.52, 13.405, MaxRadiusKm, nil)
+	assert.NoError(t, err)
+
+	_, err = NewNearbyQuery(100, 13.405, 5, nil)
+	assert.ErrorIs(t, err, ErrInvalidLocation)
+}
+
+func TestUpdateLocation(t *testing.T) {
+	store, err := NewStore("Test Store", "123 Test St")
+	assert.NoError(t, err)
+
+	err = store.UpdateLocation(40.7128, -74.0060)
+	assert.NoError(t, err)
+	assert.Equal(t, 40.7128, store.Location.Latitude())
+	assert.True(t, store.UpdatedAt.After(store.CreatedAt))
+
+	err = store.UpdateLocation(200, 0)
+	assert.ErrorIs(t, err, ErrInvalidLocation)
+	assert.Equal(t, 40.7128, store.Location.Latitude())
+}
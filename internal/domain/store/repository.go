@@ -2,14 +2,73 @@ package store
 
 import (
 	"context"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ListFilter restricts store listings. Empty fields are ignored.
+type ListFilter struct {
+	Country string
+	City    string
+	Status  Status
+	// RegionIDs keeps only stores assigned to one of the given regions
+	RegionIDs []primitive.ObjectID
+	// ProductID keeps only stores that carry the product
+	ProductID *primitive.ObjectID
+	// OpenAt keeps only stores open at the given instant. Repositories only
+	// narrow the result to open stores with opening hours; the check against
+	// the hours themselves is done by the application service.
+	OpenAt *time.Time
+}
+
+// ProductExpansion selects a page of a store's products to resolve into full
+// product documents. Fields limits the product fields loaded; nil loads every
+// field.
+type ProductExpansion struct {
+	Fields []string
+	Page   int
+	Limit  int
+}
+
+// ProductPage is a page of a store's products, ordered by name, with the
+// number of the store's products that still exist.
+type ProductPage struct {
+	Products []*product.Product
+	Total    int
+}
+
+// Summary holds aggregate figures over a set of stores
+type Summary struct {
+	StoreCount           int
+	DistinctProductCount int
+}
+
 type Repository interface {
 	Create(ctx context.Context, store *Store) error
 	GetByID(ctx context.Context, id string) (*Store, error)
+	// Update writes the store's name, addresses, location and opening hours.
+	// The status, region and products are changed through UpdateStatus,
+	// AssignRegion, AddProduct and RemoveProduct, so that an update made
+	// from an earlier read cannot undo them.
 	Update(ctx context.Context, store *Store) error
+	// UpdateStatus moves the store from one status to another. It returns
+	// ErrStatusChanged when the stored status is no longer from.
+	UpdateStatus(ctx context.Context, id string, from, to Status) error
+	// AssignRegion places the store in a region, or removes it from its
+	// region when regionID is nil
+	AssignRegion(ctx context.Context, id string, regionID *primitive.ObjectID) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, page, limit int) ([]*Store, int, error)
+	List(ctx context.Context, filter ListFilter, page, limit int) ([]*Store, int, error)
+	// Each calls fn for every store matching filter, oldest first, without
+	// loading them all at once. It stops at the first error fn returns.
+	Each(ctx context.Context, filter ListFilter, fn func(*Store) error) error
+	Summarize(ctx context.Context, filter ListFilter) (*Summary, error)
+	// AddProduct adds a product to a store that is not closed. It returns
+	// ErrStoreClosed when the store was closed in the meantime.
 	AddProduct(ctx context.Context, storeID string, productID string) error
 	RemoveProduct(ctx context.Context, storeID string, productID string) error
+	FindNearby(ctx context.Context, query *NearbyQuery, page, limit int) ([]*NearbyStore, int, error)
+	GetWithProducts(ctx context.Context, id string, expansion ProductExpansion) (*Store, *ProductPage, error)
 }
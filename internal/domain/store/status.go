@@ -0,0 +1,47 @@
+package store
+
+import "errors"
+
+var (
+	ErrInvalidStatus           = errors.New("invalid store status")
+	ErrInvalidStatusTransition = errors.New("store status transition not allowed")
+	ErrStoreClosed             = errors.New("store is closed")
+	ErrStatusChanged           = errors.New("store status was changed concurrently")
+)
+
+// Status is the stage of a store's lifecycle
+type Status string
+
+const (
+	StatusPlanned           Status = "planned"
+	StatusOpen              Status = "open"
+	StatusTemporarilyClosed Status = "temporarily_closed"
+	StatusClosed            Status = "closed"
+)
+
+// statusTransitions lists the statuses each status may move to. Closed is
+// final: a closed store keeps its history but cannot be reopened.
+var statusTransitions = map[Status][]Status{
+	StatusPlanned:           {StatusOpen, StatusClosed},
+	StatusOpen:              {StatusTemporarilyClosed, StatusClosed},
+	StatusTemporarilyClosed: {StatusOpen, StatusClosed},
+	StatusClosed:            {},
+}
+
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if _, ok := statusTransitions[status]; !ok {
+		return "", ErrInvalidStatus
+	}
+	return status, nil
+}
+
+// CanTransitionTo reports whether a store in status s may move to next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		from       Status
+		transition func(*Store) error
+		want       Status
+		wantErr    error
+	}{
+		{name: "open planned store", from: StatusPlanned, transition: (*Store).Open, want: StatusOpen},
+		{name: "close planned store", from: StatusPlanned, transition: (*Store).Close, want: StatusClosed},
+		{name: "suspend planned store", from: StatusPlanned, transition: (*Store).Suspend, wantErr: ErrInvalidStatusTransition},
+		{name: "suspend open store", from: StatusOpen, transition: (*Store).Suspend, want: StatusTemporarilyClosed},
+		{name: "reopen suspended store", from: StatusTemporarilyClosed, transition: (*Store).Open, want: StatusOpen},
+		{name: "close suspended store", from: StatusTemporarilyClosed, transition: (*Store).Close, want: StatusClosed},
+		{name: "open already open store", from: StatusOpen, transition: (*Store).Open, wantErr: ErrInvalidStatusTransition},
+		{name: "reopen closed store", from: StatusClosed, transition: (*Store).Open, wantErr: ErrInvalidStatusTransition},
+		{name: "legacy store counts as open", from: "", transition: (*Store).Suspend, want: StatusTemporarilyClosed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, err := NewStore("Test Store", "123 Test St")
+			assert.NoError(t, err)
+			store.Status = tc.from
+
+			err = tc.transition(store)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Equal(t, tc.from, store.Status)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, store.Status)
+		})
+	}
+}
+
+func TestNewStoreIsPlanned(t *testing.T) {
+	store, err := NewStore("Test Store", "123 Test St")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPlanned, store.CurrentStatus())
+}
+
+func TestClosedStoreRejectsProducts(t *testing.T) {
+	store, err := NewStore("Test Store", "123 Test St")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Close())
+
+	err = store.AddProduct(primitive.NewObjectID())
+	assert.ErrorIs(t, err, ErrStoreClosed)
+	assert.Empty(t, store.Products)
+}
+
+func TestParseStatus(t *testing.T) {
+	status, err := ParseStatus("temporarily_closed")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusTemporarilyClosed, status)
+
+	_, err = ParseStatus("demolished")
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
@@ -15,13 +15,22 @@ var (
 	ErrProductNotFound      = errors.New("product not found in store")
 )
 
+// Store is the store aggregate. Address holds the single-line form of the
+// address; stores created before structured addresses were introduced only
+// have this free-text value and no PostalAddress. Likewise stores created
+// before the status lifecycle have no Status and are treated as open.
 type Store struct {
-	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
-	Name      string               `bson:"name" json:"name"`
-	Address   string               `bson:"address" json:"address"`
-	Products  []primitive.ObjectID `bson:"products" json:"products"`
-	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time            `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name          string               `bson:"name" json:"name"`
+	Status        Status               `bson:"status,omitempty" json:"status"`
+	Address       string               `bson:"address" json:"address"`
+	PostalAddress *Address             `bson:"postal_address,omitempty" json:"postal_address,omitempty"`
+	Location      *Location            `bson:"location,omitempty" json:"location,omitempty"`
+	OpeningHours  *OpeningHours        `bson:"opening_hours,omitempty" json:"opening_hours,omitempty"`
+	RegionID      *primitive.ObjectID  `bson:"region_id,omitempty" json:"region_id,omitempty"`
+	Products      []primitive.ObjectID `bson:"products" json:"products"`
+	CreatedAt     time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time            `bson:"updated_at" json:"updated_at"`
 }
 
 func NewStore(name, address string) (*Store, error) {
@@ -36,6 +45,7 @@ func NewStore(name, address string) (*Store, error) {
 	return &Store{
 		ID:        primitive.NewObjectID(),
 		Name:      name,
+		Status:    StatusPlanned,
 		Address:   address,
 		Products:  make([]primitive.ObjectID, 0),
 		CreatedAt: now,
@@ -43,7 +53,24 @@ func NewStore(name, address string) (*Store, error) {
 	}, nil
 }
 
+// NewStoreWithAddress creates a store with a structured postal address
+func NewStoreWithAddress(name string, address *Address) (*Store, error) {
+	if address == nil {
+		return nil, ErrInvalidStoreAddress
+	}
+
+	s, err := NewStore(name, address.String())
+	if err != nil {
+		return nil, err
+	}
+	s.PostalAddress = address
+	return s, nil
+}
+
 func (s *Store) AddProduct(productID primitive.ObjectID) error {
+	if s.CurrentStatus() == StatusClosed {
+		return ErrStoreClosed
+	}
 	for _, id := range s.Products {
 		if id == productID {
 			return ErrProductAlreadyExists
@@ -83,11 +110,121 @@ func (s *Store) UpdateName(name string) error {
 	return nil
 }
 
+// UpdateAddress replaces the address with legacy free text. Any structured
+// postal address is cleared since it no longer describes the store.
 func (s *Store) UpdateAddress(address string) error {
 	if address == "" {
 		return ErrInvalidStoreAddress
 	}
 	s.Address = address
+	s.PostalAddress = nil
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *Store) UpdatePostalAddress(address *Address) error {
+	if address == nil {
+		return ErrInvalidStoreAddress
+	}
+	s.Address = address.String()
+	s.PostalAddress = address
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *Store) UpdateLocation(latitude, longitude float64) error {
+	location, err := NewLocation(latitude, longitude)
+	if err != nil {
+		return err
+	}
+	s.Location = location
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateOpeningHours replaces the weekly schedule and time zone, keeping any
+// exception dates already set.
+func (s *Store) UpdateOpeningHours(hours *OpeningHours) error {
+	if hours == nil {
+		return ErrOpeningHoursNotSet
+	}
+	if s.OpeningHours != nil {
+		hours.Exceptions = s.OpeningHours.Exceptions
+	}
+	s.OpeningHours = hours
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetOpeningException adds or replaces the exception for day.Date
+func (s *Store) SetOpeningException(day *ExceptionDay) error {
+	if s.OpeningHours == nil {
+		return ErrOpeningHoursNotSet
+	}
+	s.OpeningHours.setException(day)
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *Store) RemoveOpeningException(date string) error {
+	if s.OpeningHours == nil {
+		return ErrOpeningHoursNotSet
+	}
+	if err := s.OpeningHours.removeException(date); err != nil {
+		return err
+	}
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsOpenAt reports whether the store is open at t according to its opening
+// hours. Stores that are not in the open status are never open.
+func (s *Store) IsOpenAt(t time.Time) (bool, error) {
+	if s.OpeningHours == nil {
+		return false, ErrOpeningHoursNotSet
+	}
+	if s.CurrentStatus() != StatusOpen {
+		return false, nil
+	}
+	return s.OpeningHours.IsOpenAt(t), nil
+}
+
+// CurrentStatus returns the store's status, treating stores persisted before
+// the lifecycle was introduced as open.
+func (s *Store) CurrentStatus() Status {
+	if s.Status == "" {
+		return StatusOpen
+	}
+	return s.Status
+}
+
+// Open starts trading at a planned store or reopens a temporarily closed one
+func (s *Store) Open() error {
+	return s.transitionTo(StatusOpen)
+}
+
+// Suspend temporarily closes an open store
+func (s *Store) Suspend() error {
+	return s.transitionTo(StatusTemporarilyClosed)
+}
+
+// Close permanently takes the store out of service
+func (s *Store) Close() error {
+	return s.transitionTo(StatusClosed)
+}
+
+func (s *Store) transitionTo(next Status) error {
+	if !s.CurrentStatus().CanTransitionTo(next) {
+		return ErrInvalidStatusTransition
+	}
+	s.Status = next
 	s.UpdatedAt = time.Now()
 	return nil
 }
+
+// AssignRegion places the store in a region, or removes it from its region
+// when regionID is nil.
+func (s *Store) AssignRegion(regionID *primitive.ObjectID) {
+	s.RegionID = regionID
+	s.UpdatedAt = time.Now()
+}
@@ -0,0 +1,17 @@
+package supplier
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Repository interface {
+	Create(ctx context.Context, supplier *Supplier) error
+	GetByID(ctx context.Context, id string) (*Supplier, error)
+	Update(ctx context.Context, supplier *Supplier) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, page, limit int) ([]*Supplier, int, error)
+	// ListByProduct returns the suppliers that sell a product
+	ListByProduct(ctx context.Context, productID primitive.ObjectID) ([]*Supplier, error)
+}
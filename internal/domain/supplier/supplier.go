@@ -0,0 +1,120 @@
+package supplier
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrSupplierNotFound   = errors.New("supplier not found")
+	ErrInvalidName        = errors.New("supplier name cannot be empty")
+	ErrInvalidEmail       = errors.New("contact email is not a valid address")
+	ErrInvalidLeadTime    = errors.New("lead time cannot be negative")
+	ErrInvalidCostPrice   = errors.New("cost price must be greater than 0")
+	ErrProductNotSupplied = errors.New("product is not supplied by this supplier")
+)
+
+// Contact is the person purchase orders are sent to
+type Contact struct {
+	Name  string `bson:"name,omitempty" json:"name,omitempty"`
+	Email string `bson:"email,omitempty" json:"email,omitempty"`
+	Phone string `bson:"phone,omitempty" json:"phone,omitempty"`
+}
+
+// SuppliedProduct is a product the supplier sells and what it costs us
+type SuppliedProduct struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	CostPrice float64            `bson:"cost_price" json:"cost_price"`
+}
+
+type Supplier struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name         string             `bson:"name" json:"name"`
+	Contact      Contact            `bson:"contact" json:"contact"`
+	LeadTimeDays int                `bson:"lead_time_days" json:"lead_time_days"`
+	Products     []SuppliedProduct  `bson:"products" json:"products"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+func NewSupplier(name string, contact Contact, leadTimeDays int) (*Supplier, error) {
+	s := &Supplier{
+		ID:       primitive.NewObjectID(),
+		Products: []SuppliedProduct{},
+	}
+	if err := s.UpdateDetails(name, contact, leadTimeDays); err != nil {
+		return nil, err
+	}
+	s.CreatedAt = s.UpdatedAt
+	return s, nil
+}
+
+// UpdateDetails replaces the supplier's name, contact and lead time
+func (s *Supplier) UpdateDetails(name string, contact Contact, leadTimeDays int) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidName
+	}
+	if contact.Email != "" {
+		if _, err := mail.ParseAddress(contact.Email); err != nil {
+			return ErrInvalidEmail
+		}
+	}
+	if leadTimeDays < 0 {
+		return ErrInvalidLeadTime
+	}
+
+	s.Name = name
+	s.Contact = contact
+	s.LeadTimeDays = leadTimeDays
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// LeadTime returns how long the supplier takes to deliver an order
+func (s *Supplier) LeadTime() time.Duration {
+	return time.Duration(s.LeadTimeDays) * 24 * time.Hour
+}
+
+// SetProduct records that the supplier sells a product at costPrice,
+// replacing any earlier cost price
+func (s *Supplier) SetProduct(productID primitive.ObjectID, costPrice float64) error {
+	if costPrice <= 0 {
+		return ErrInvalidCostPrice
+	}
+
+	s.UpdatedAt = time.Now()
+	for i := range s.Products {
+		if s.Products[i].ProductID == productID {
+			s.Products[i].CostPrice = costPrice
+			return nil
+		}
+	}
+	s.Products = append(s.Products, SuppliedProduct{ProductID: productID, CostPrice: costPrice})
+	return nil
+}
+
+func (s *Supplier) RemoveProduct(productID primitive.ObjectID) error {
+	for i := range s.Products {
+		if s.Products[i].ProductID == productID {
+			s.Products = append(s.Products[:i], s.Products[i+1:]...)
+			s.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return ErrProductNotSupplied
+}
+
+// CostFor returns the cost price of a supplied product
+func (s *Supplier) CostFor(productID primitive.ObjectID) (float64, error) {
+	for _, p := range s.Products {
+		if p.ProductID == productID {
+			return p.CostPrice, nil
+		}
+	}
+	return 0, ErrProductNotSupplied
+}
@@ -0,0 +1,57 @@
+package supplier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewSupplierValidation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		supplier string
+		contact  Contact
+		leadTime int
+		wantErr  error
+	}{
+		{name: "valid", supplier: "Acme", contact: Contact{Name: "Jo", Email: "orders@acme.example"}, leadTime: 3},
+		{name: "no contact", supplier: "Acme"},
+		{name: "blank name", supplier: "  ", wantErr: ErrInvalidName},
+		{name: "bad email", supplier: "Acme", contact: Contact{Email: "not-an-email"}, wantErr: ErrInvalidEmail},
+		{name: "negative lead time", supplier: "Acme", leadTime: -1, wantErr: ErrInvalidLeadTime},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewSupplier(tc.supplier, tc.contact, tc.leadTime)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(tc.leadTime)*24*time.Hour, s.LeadTime())
+		})
+	}
+}
+
+func TestSupplierProducts(t *testing.T) {
+	s, err := NewSupplier("Acme", Contact{}, 2)
+	assert.NoError(t, err)
+	productID := primitive.NewObjectID()
+
+	assert.ErrorIs(t, s.SetProduct(productID, 0), ErrInvalidCostPrice)
+	assert.NoError(t, s.SetProduct(productID, 1.20))
+	assert.NoError(t, s.SetProduct(productID, 1.35))
+	assert.Len(t, s.Products, 1)
+
+	cost, err := s.CostFor(productID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.35, cost)
+
+	assert.NoError(t, s.RemoveProduct(productID))
+	_, err = s.CostFor(productID)
+	assert.ErrorIs(t, err, ErrProductNotSupplied)
+	assert.ErrorIs(t, s.RemoveProduct(productID), ErrProductNotSupplied)
+}
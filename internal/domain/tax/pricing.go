@@ -0,0 +1,58 @@
+package tax
+
+import (
+	"math"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+)
+
+// Breakdown splits an amount into its net, tax and gross parts
+type Breakdown struct {
+	Net      float64          `json:"net"`
+	Tax      float64          `json:"tax"`
+	Gross    float64          `json:"gross"`
+	Rate     float64          `json:"rate"`
+	TaxClass product.TaxClass `json:"tax_class"`
+	Country  string           `json:"country"`
+	Region   string           `json:"region,omitempty"`
+}
+
+// PricingService computes tax breakdowns for catalog prices
+type PricingService struct{}
+
+func NewPricingService() *PricingService {
+	return &PricingService{}
+}
+
+// Breakdown computes the tax on amount for a product class under table. The
+// amount is read as gross or net according to the table's PricesIncludeTax.
+// The tax is rounded half away from zero to whole cents and the other part
+// derived from it, so net plus tax always equals gross exactly.
+func (s *PricingService) Breakdown(amount float64, class product.TaxClass, table *RateTable) (*Breakdown, error) {
+	rate, err := table.RateFor(class)
+	if err != nil {
+		return nil, err
+	}
+
+	cents := math.Round(amount * 100)
+	var net, tax, gross float64
+	if table.PricesIncludeTax {
+		gross = cents
+		tax = math.Round(gross * rate / (100 + rate))
+		net = gross - tax
+	} else {
+		net = cents
+		tax = math.Round(net * rate / 100)
+		gross = net + tax
+	}
+
+	return &Breakdown{
+		Net:      net / 100,
+		Tax:      tax / 100,
+		Gross:    gross / 100,
+		Rate:     rate,
+		TaxClass: class,
+		Country:  table.Country,
+		Region:   table.Region,
+	}, nil
+}
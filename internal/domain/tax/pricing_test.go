@@ -0,0 +1,77 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateTable(t *testing.T) {
+	table, err := NewRateTable(" de ", "", map[string]float64{"standard": 19, "reduced": 7}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "DE", table.Country)
+	assert.Equal(t, 19.0, table.Rates[product.TaxClassStandard])
+
+	_, err = NewRateTable("Germany", "", nil, true)
+	assert.ErrorIs(t, err, ErrInvalidCountry)
+
+	_, err = NewRateTable("DE", "", map[string]float64{"luxury": 30}, true)
+	assert.ErrorIs(t, err, product.ErrInvalidTaxClass)
+
+	_, err = NewRateTable("DE", "", map[string]float64{"standard": 120}, true)
+	assert.ErrorIs(t, err, ErrInvalidRate)
+
+	regional, err := NewRateTable("US", " ny", map[string]float64{"standard": 8.875}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "NY", regional.Region)
+}
+
+func TestRateFor(t *testing.T) {
+	table, _ := NewRateTable("DE", "", map[string]float64{"standard": 19}, true)
+
+	rate, err := table.RateFor(product.TaxClassStandard)
+	assert.NoError(t, err)
+	assert.Equal(t, 19.0, rate)
+
+	rate, err = table.RateFor(product.TaxClassExempt)
+	assert.NoError(t, err)
+	assert.Zero(t, rate)
+
+	_, err = table.RateFor(product.TaxClassReduced)
+	assert.ErrorIs(t, err, ErrRateNotDefined)
+}
+
+func TestBreakdown(t *testing.T) {
+	inclusive, _ := NewRateTable("DE", "", map[string]float64{"standard": 19, "reduced": 7}, true)
+	exclusive, _ := NewRateTable("US", "NY", map[string]float64{"standard": 8.875}, false)
+	service := NewPricingService()
+
+	testCases := []struct {
+		name      string
+		amount    float64
+		class     product.TaxClass
+		table     *RateTable
+		wantNet   float64
+		wantTax   float64
+		wantGross float64
+	}{
+		{name: "gross price", amount: 11.9, class: product.TaxClassStandard, table: inclusive, wantNet: 10, wantTax: 1.9, wantGross: 11.9},
+		{name: "gross price rounding", amount: 9.99, class: product.TaxClassStandard, table: inclusive, wantNet: 8.39, wantTax: 1.6, wantGross: 9.99},
+		{name: "reduced rate", amount: 2.49, class: product.TaxClassReduced, table: inclusive, wantNet: 2.33, wantTax: 0.16, wantGross: 2.49},
+		{name: "net price", amount: 10, class: product.TaxClassStandard, table: exclusive, wantNet: 10, wantTax: 0.89, wantGross: 10.89},
+		{name: "half cent rounds up", amount: 4, class: product.TaxClassStandard, table: exclusive, wantNet: 4, wantTax: 0.36, wantGross: 4.36},
+		{name: "exempt", amount: 5, class: product.TaxClassExempt, table: exclusive, wantNet: 5, wantTax: 0, wantGross: 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := service.Breakdown(tc.amount, tc.class, tc.table)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantNet, b.Net)
+			assert.Equal(t, tc.wantTax, b.Tax)
+			assert.Equal(t, tc.wantGross, b.Gross)
+			assert.Equal(t, tc.class, b.TaxClass)
+		})
+	}
+}
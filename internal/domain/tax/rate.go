@@ -0,0 +1,91 @@
+package tax
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrRateTableNotFound     = errors.New("tax rate table not found")
+	ErrDuplicateJurisdiction = errors.New("a tax rate table already exists for this jurisdiction")
+	ErrInvalidCountry        = errors.New("country must be an ISO 3166-1 alpha-2 code")
+	ErrInvalidRate           = errors.New("tax rate must be between 0 and 100 percent")
+	ErrRateNotDefined        = errors.New("no tax rate defined for tax class")
+)
+
+// RateTable holds the tax rates of a jurisdiction, a country or a region
+// within it, in percent per tax class. PricesIncludeTax states whether
+// catalog prices sold there are gross, as with VAT in most of Europe, or net,
+// as with US sales tax.
+type RateTable struct {
+	ID               primitive.ObjectID           `bson:"_id,omitempty" json:"id"`
+	Country          string                       `bson:"country" json:"country"`
+	Region           string                       `bson:"region" json:"region,omitempty"`
+	Rates            map[product.TaxClass]float64 `bson:"rates" json:"rates"`
+	PricesIncludeTax bool                         `bson:"prices_include_tax" json:"prices_include_tax"`
+	CreatedAt        time.Time                    `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time                    `bson:"updated_at" json:"updated_at"`
+}
+
+// NewRateTable creates the rate table for a country, or for a region of it
+// when region is not empty. Region names match store address regions
+// case-insensitively.
+func NewRateTable(country, region string, rates map[string]float64, pricesIncludeTax bool) (*RateTable, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return nil, ErrInvalidCountry
+	}
+
+	now := time.Now()
+	t := &RateTable{
+		ID:        primitive.NewObjectID(),
+		Country:   country,
+		Region:    NormalizeRegion(region),
+		CreatedAt: now,
+	}
+	if err := t.UpdateRates(rates, pricesIncludeTax); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UpdateRates replaces the rates of the table
+func (t *RateTable) UpdateRates(rates map[string]float64, pricesIncludeTax bool) error {
+	parsed := make(map[product.TaxClass]float64, len(rates))
+	for name, rate := range rates {
+		class, err := product.ParseTaxClass(name)
+		if err != nil {
+			return err
+		}
+		if rate < 0 || rate > 100 {
+			return ErrInvalidRate
+		}
+		parsed[class] = rate
+	}
+
+	t.Rates = parsed
+	t.PricesIncludeTax = pricesIncludeTax
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// RateFor returns the rate in percent for a tax class. Zero rated and exempt
+// products are untaxed unless the table says otherwise.
+func (t *RateTable) RateFor(class product.TaxClass) (float64, error) {
+	if rate, ok := t.Rates[class]; ok {
+		return rate, nil
+	}
+	if class == product.TaxClassZero || class == product.TaxClassExempt {
+		return 0, nil
+	}
+	return 0, ErrRateNotDefined
+}
+
+// NormalizeRegion returns the form region names are stored and looked up in
+func NormalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
@@ -0,0 +1,14 @@
+package tax
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, table *RateTable) error
+	GetByID(ctx context.Context, id string) (*RateTable, error)
+	Update(ctx context.Context, table *RateTable) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*RateTable, error)
+	// FindForJurisdiction returns the table of the region if one exists and
+	// that of the country otherwise.
+	FindForJurisdiction(ctx context.Context, country, region string) (*RateTable, error)
+}
@@ -1,15 +1,22 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"time"
 )
 
 type Config struct {
-	Server  ServerConfig
-	MongoDB MongoDBConfig
-	API     APIConfig
+	Server      ServerConfig
+	MongoDB     MongoDBConfig
+	API         APIConfig
+	Search      SearchConfig
+	Currency    CurrencyConfig
+	Cart        CartConfig
+	Stock       StockConfig
+	Import      ImportConfig
+	Idempotency IdempotencyConfig
 }
 
 type ServerConfig struct {
@@ -30,8 +37,50 @@ type APIConfig struct {
 	Token string
 }
 
+type SearchConfig struct {
+	IndexEnabled bool
+}
+
+type CurrencyConfig struct {
+	Base      string
+	RatesFile string
+	Rounding  string
+}
+
+type CartConfig struct {
+	TTL time.Duration
+}
+
+type StockConfig struct {
+	ReservationTTL      time.Duration
+	SweepInterval       time.Duration
+	ReorderScanInterval time.Duration
+}
+
+// MaxImportUploadBytes is the largest IMPORT_MAX_UPLOAD_BYTES accepted. The
+// worker holds a whole file in memory while it runs the job.
+const MaxImportUploadBytes = 64 << 20
+
+// ImportConfig controls catalog imports. The worker holds a job for Lease at
+// a time, renewing it after every batch of rows, so Lease must comfortably
+// exceed the time a batch takes.
+type ImportConfig struct {
+	PollInterval   time.Duration
+	Lease          time.Duration
+	MaxUploadBytes int64
+}
+
+// IdempotencyConfig controls Idempotency-Key handling. Stored responses are
+// replayed for TTL; a request that holds a key for longer than LockTimeout
+// without finishing is assumed lost and the key may be retried. LockTimeout
+// must exceed the server write timeout, by which every request has ended.
+type IdempotencyConfig struct {
+	TTL         time.Duration
+	LockTimeout time.Duration
+}
+
 func Load() (*Config, error) {
-	return &Config{
+	cfg := &Config{
 		Server: ServerConfig{
 			Host:              getEnv("SERVER_HOST", "localhost"),
 			Port:              getEnv("SERVER_PORT", "8091"),
@@ -47,7 +96,69 @@ func Load() (*Config, error) {
 		API: APIConfig{
 			Token: getEnv("API_TOKEN", ""),
 		},
-	}, nil
+		Search: SearchConfig{
+			IndexEnabled: getBoolEnv("SEARCH_INDEX_ENABLED", false),
+		},
+		Currency: CurrencyConfig{
+			Base:      getEnv("BASE_CURRENCY", "EUR"),
+			RatesFile: getEnv("EXCHANGE_RATES_FILE", ""),
+			Rounding:  getEnv("CURRENCY_ROUNDING", ""),
+		},
+		Cart: CartConfig{
+			TTL: getDurationEnv("CART_TTL", 72*time.Hour),
+		},
+		Stock: StockConfig{
+			ReservationTTL:      getDurationEnv("RESERVATION_TTL", 10*time.Minute),
+			SweepInterval:       getDurationEnv("RESERVATION_SWEEP_INTERVAL", 30*time.Second),
+			ReorderScanInterval: getDurationEnv("REORDER_SCAN_INTERVAL", 15*time.Minute),
+		},
+		Import: ImportConfig{
+			PollInterval:   getDurationEnv("IMPORT_POLL_INTERVAL", 5*time.Second),
+			Lease:          getDurationEnv("IMPORT_LEASE", 2*time.Minute),
+			MaxUploadBytes: int64(getIntEnv("IMPORT_MAX_UPLOAD_BYTES", 4<<20)),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         getDurationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+			LockTimeout: getDurationEnv("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute),
+		},
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validate rejects settings the application cannot run with, such as the
+// zero interval of a background job, which would make its ticker panic
+func (c *Config) validate() error {
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"RESERVATION_TTL", c.Stock.ReservationTTL},
+		{"RESERVATION_SWEEP_INTERVAL", c.Stock.SweepInterval},
+		{"REORDER_SCAN_INTERVAL", c.Stock.ReorderScanInterval},
+		{"IMPORT_POLL_INTERVAL", c.Import.PollInterval},
+		{"IMPORT_LEASE", c.Import.Lease},
+		{"IDEMPOTENCY_TTL", c.Idempotency.TTL},
+		{"IDEMPOTENCY_LOCK_TIMEOUT", c.Idempotency.LockTimeout},
+	} {
+		if setting.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %v", setting.name, setting.value)
+		}
+	}
+
+	if c.Import.MaxUploadBytes <= 0 || c.Import.MaxUploadBytes > MaxImportUploadBytes {
+		return fmt.Errorf("IMPORT_MAX_UPLOAD_BYTES must be between 1 and %d, got %d", MaxImportUploadBytes, c.Import.MaxUploadBytes)
+	}
+
+	// A request still running when its key's lock runs out would let a retry
+	// run it a second time
+	if c.Idempotency.LockTimeout <= c.Server.WriteTimeout {
+		return fmt.Errorf("IDEMPOTENCY_LOCK_TIMEOUT must be longer than WRITE_TIMEOUT (%v), got %v", c.Server.WriteTimeout, c.Idempotency.LockTimeout)
+	}
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
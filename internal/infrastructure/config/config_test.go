@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -15,15 +16,28 @@ func TestLoad(t *testing.T) {
 		{
 			name: "default values",
 			envVars: map[string]string{
-				"SERVER_PORT":         "",
-				"SERVER_HOST":         "",
-				"READ_TIMEOUT":        "",
-				"WRITE_TIMEOUT":       "",
-				"IDLE_TIMEOUT":        "",
-				"READ_HEADER_TIMEOUT": "",
-				"MONGO_URI":           "",
-				"MONGO_DATABASE":      "",
-				"API_TOKEN":           "",
+				"SERVER_PORT":                "",
+				"SERVER_HOST":                "",
+				"READ_TIMEOUT":               "",
+				"WRITE_TIMEOUT":              "",
+				"IDLE_TIMEOUT":               "",
+				"READ_HEADER_TIMEOUT":        "",
+				"MONGO_URI":                  "",
+				"MONGO_DATABASE":             "",
+				"API_TOKEN":                  "",
+				"SEARCH_INDEX_ENABLED":       "",
+				"BASE_CURRENCY":              "",
+				"EXCHANGE_RATES_FILE":        "",
+				"CURRENCY_ROUNDING":          "",
+				"CART_TTL":                   "",
+				"RESERVATION_TTL":            "",
+				"RESERVATION_SWEEP_INTERVAL": "",
+				"REORDER_SCAN_INTERVAL":      "",
+				"IMPORT_POLL_INTERVAL":       "",
+				"IMPORT_LEASE":               "",
+				"IMPORT_MAX_UPLOAD_BYTES":    "",
+				"IDEMPOTENCY_TTL":            "",
+				"IDEMPOTENCY_LOCK_TIMEOUT":   "",
 			},
 			expectedConfig: &Config{
 				Server: ServerConfig{
@@ -41,20 +55,56 @@ func TestLoad(t *testing.T) {
 				API: APIConfig{
 					Token: "",
 				},
+				Search: SearchConfig{
+					IndexEnabled: false,
+				},
+				Currency: CurrencyConfig{
+					Base: "EUR",
+				},
+				Cart: CartConfig{
+					TTL: 72 * time.Hour,
+				},
+				Stock: StockConfig{
+					ReservationTTL:      10 * time.Minute,
+					SweepInterval:       30 * time.Second,
+					ReorderScanInterval: 15 * time.Minute,
+				},
+				Import: ImportConfig{
+					PollInterval:   5 * time.Second,
+					Lease:          2 * time.Minute,
+					MaxUploadBytes: 4 << 20,
+				},
+				Idempotency: IdempotencyConfig{
+					TTL:         24 * time.Hour,
+					LockTimeout: time.Minute,
+				},
 			},
 		},
 		{
 			name: "custom values",
 			envVars: map[string]string{
-				"SERVER_PORT":         "9090",
-				"SERVER_HOST":         "0.0.0.0",
-				"READ_TIMEOUT":        "20s",
-				"WRITE_TIMEOUT":       "20s",
-				"IDLE_TIMEOUT":        "120s",
-				"READ_HEADER_TIMEOUT": "5s",
-				"MONGO_URI":           "mongodb://custom:27017",
-				"MONGO_DATABASE":      "custom_db",
-				"API_TOKEN":           "test_token",
+				"SERVER_PORT":                "9090",
+				"SERVER_HOST":                "0.0.0.0",
+				"READ_TIMEOUT":               "20s",
+				"WRITE_TIMEOUT":              "20s",
+				"IDLE_TIMEOUT":               "120s",
+				"READ_HEADER_TIMEOUT":        "5s",
+				"MONGO_URI":                  "mongodb://custom:27017",
+				"MONGO_DATABASE":             "custom_db",
+				"API_TOKEN":                  "test_token",
+				"SEARCH_INDEX_ENABLED":       "true",
+				"BASE_CURRENCY":              "USD",
+				"EXCHANGE_RATES_FILE":        "/etc/ddd/rates.csv",
+				"CURRENCY_ROUNDING":          "CHF=0.05",
+				"CART_TTL":                   "24h",
+				"RESERVATION_TTL":            "5m",
+				"RESERVATION_SWEEP_INTERVAL": "10s",
+				"REORDER_SCAN_INTERVAL":      "1h",
+				"IMPORT_POLL_INTERVAL":       "1s",
+				"IMPORT_LEASE":               "30s",
+				"IMPORT_MAX_UPLOAD_BYTES":    "1048576",
+				"IDEMPOTENCY_TTL":            "1h",
+				"IDEMPOTENCY_LOCK_TIMEOUT":   "30s",
 			},
 			expectedConfig: &Config{
 				Server: ServerConfig{
@@ -72,6 +122,31 @@ func TestLoad(t *testing.T) {
 				API: APIConfig{
 					Token: "test_token",
 				},
+				Search: SearchConfig{
+					IndexEnabled: true,
+				},
+				Currency: CurrencyConfig{
+					Base:      "USD",
+					RatesFile: "/etc/ddd/rates.csv",
+					Rounding:  "CHF=0.05",
+				},
+				Cart: CartConfig{
+					TTL: 24 * time.Hour,
+				},
+				Stock: StockConfig{
+					ReservationTTL:      5 * time.Minute,
+					SweepInterval:       10 * time.Second,
+					ReorderScanInterval: time.Hour,
+				},
+				Import: ImportConfig{
+					PollInterval:   time.Second,
+					Lease:          30 * time.Second,
+					MaxUploadBytes: 1 << 20,
+				},
+				Idempotency: IdempotencyConfig{
+					TTL:         time.Hour,
+					LockTimeout: 30 * time.Second,
+				},
 			},
 		},
 	}
@@ -118,6 +193,99 @@ func TestLoad(t *testing.T) {
 			if config.API.Token != tt.expectedConfig.API.Token {
 				t.Errorf("Expected API.Token %s, got %s", tt.expectedConfig.API.Token, config.API.Token)
 			}
+			if config.Search.IndexEnabled != tt.expectedConfig.Search.IndexEnabled {
+				t.Errorf("Expected Search.IndexEnabled %v, got %v", tt.expectedConfig.Search.IndexEnabled, config.Search.IndexEnabled)
+			}
+			if config.Currency != tt.expectedConfig.Currency {
+				t.Errorf("Expected Currency %+v, got %+v", tt.expectedConfig.Currency, config.Currency)
+			}
+			if config.Cart != tt.expectedConfig.Cart {
+				t.Errorf("Expected Cart %+v, got %+v", tt.expectedConfig.Cart, config.Cart)
+			}
+			if config.Stock != tt.expectedConfig.Stock {
+				t.Errorf("Expected Stock %+v, got %+v", tt.expectedConfig.Stock, config.Stock)
+			}
+			if config.Import != tt.expectedConfig.Import {
+				t.Errorf("Expected Import %+v, got %+v", tt.expectedConfig.Import, config.Import)
+			}
+			if config.Idempotency != tt.expectedConfig.Idempotency {
+				t.Errorf("Expected Idempotency %+v, got %+v", tt.expectedConfig.Idempotency, config.Idempotency)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		envVars map[string]string
+		wantErr string
+	}{
+		{
+			name:    "zero reservation TTL",
+			envVars: map[string]string{"RESERVATION_TTL": "0s"},
+			wantErr: "RESERVATION_TTL must be positive",
+		},
+		{
+			name:    "negative sweep interval",
+			envVars: map[string]string{"RESERVATION_SWEEP_INTERVAL": "-30s"},
+			wantErr: "RESERVATION_SWEEP_INTERVAL must be positive",
+		},
+		{
+			name:    "zero reorder scan interval",
+			envVars: map[string]string{"REORDER_SCAN_INTERVAL": "0s"},
+			wantErr: "REORDER_SCAN_INTERVAL must be positive",
+		},
+		{
+			name:    "zero import poll interval",
+			envVars: map[string]string{"IMPORT_POLL_INTERVAL": "0s"},
+			wantErr: "IMPORT_POLL_INTERVAL must be positive",
+		},
+		{
+			name:    "negative import lease",
+			envVars: map[string]string{"IMPORT_LEASE": "-1m"},
+			wantErr: "IMPORT_LEASE must be positive",
+		},
+		{
+			name:    "zero upload limit",
+			envVars: map[string]string{"IMPORT_MAX_UPLOAD_BYTES": "0"},
+			wantErr: "IMPORT_MAX_UPLOAD_BYTES must be between",
+		},
+		{
+			name:    "upload limit above the maximum",
+			envVars: map[string]string{"IMPORT_MAX_UPLOAD_BYTES": "134217728"},
+			wantErr: "IMPORT_MAX_UPLOAD_BYTES must be between",
+		},
+		{
+			name:    "zero idempotency TTL",
+			envVars: map[string]string{"IDEMPOTENCY_TTL": "0s"},
+			wantErr: "IDEMPOTENCY_TTL must be positive",
+		},
+		{
+			name:    "idempotency lock shorter than the write timeout",
+			envVars: map[string]string{"WRITE_TIMEOUT": "30s", "IDEMPOTENCY_LOCK_TIMEOUT": "20s"},
+			wantErr: "IDEMPOTENCY_LOCK_TIMEOUT must be longer than WRITE_TIMEOUT",
+		},
+		{
+			name:    "idempotency lock equal to the write timeout",
+			envVars: map[string]string{"WRITE_TIMEOUT": "1m", "IDEMPOTENCY_LOCK_TIMEOUT": ""},
+			wantErr: "IDEMPOTENCY_LOCK_TIMEOUT must be longer than WRITE_TIMEOUT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+
+			config, err := Load()
+			if err == nil {
+				t.Fatalf("Load() = %+v, want error", config)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
 		})
 	}
 }
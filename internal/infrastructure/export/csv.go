@@ -0,0 +1,59 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+}
+
+func newCSVWriter(w io.Writer, columns []string) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.w.Write(c.columns)
+}
+
+func (c *csvWriter) Write(values []interface{}) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			record[i] = cell(s)
+			continue
+		}
+		record[i] = text(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// cell returns a string value as a CSV field. Values that start with
+// a character spreadsheets read as the start of a formula are prefixed with
+// an apostrophe so that they are shown as text rather than evaluated.
+func cell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ndjsonWriter writes each row as a JSON object with its keys in column
+// order
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []string
+	scratch bytes.Buffer
+	encoder *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer, columns []string) *ndjsonWriter {
+	n := &ndjsonWriter{w: bufio.NewWriter(w), columns: columns}
+	n.encoder = json.NewEncoder(&n.scratch)
+	n.encoder.SetEscapeHTML(false)
+	return n
+}
+
+func (n *ndjsonWriter) Write(values []interface{}) error {
+	n.w.WriteByte('{')
+	for i, column := range n.columns {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+		if err := n.encode(column); err != nil {
+			return err
+		}
+		n.w.WriteByte(':')
+
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+		if t, ok := value.(time.Time); ok {
+			value = text(t)
+		}
+		if err := n.encode(value); err != nil {
+			return err
+		}
+	}
+	n.w.WriteByte('}')
+	return n.w.WriteByte('\n')
+}
+
+// encode writes a JSON value without the newline json.Encoder appends
+func (n *ndjsonWriter) encode(value interface{}) error {
+	n.scratch.Reset()
+	if err := n.encoder.Encode(value); err != nil {
+		return err
+	}
+	_, err := n.w.Write(bytes.TrimSuffix(n.scratch.Bytes(), []byte("\n")))
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
@@ -0,0 +1,89 @@
+// Package export writes tabular data as CSV, NDJSON or XLSX, one row at a
+// time, so that large exports can be streamed without being held in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidFormat = errors.New("export format must be csv, ndjson or xlsx")
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+// Formats lists every format, in order of preference
+var Formats = []Format{FormatCSV, FormatNDJSON, FormatXLSX}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", ErrInvalidFormat
+}
+
+// ContentType returns the media type of files in the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// Writer writes rows under a fixed set of columns. Values may be strings,
+// numbers, booleans, times or nil. Nothing is written to the underlying
+// writer before the first row or Close, and Close must be called to finish
+// the file. The CSV writer prefixes strings starting with =, +, - or @ with
+// an apostrophe so spreadsheets do not run them as formulas; XLSX cells hold
+// strings as inline text, which is never evaluated.
+type Writer interface {
+	Write(values []interface{}) error
+	Close() error
+}
+
+// NewWriter returns a writer for the format that writes to w
+func NewWriter(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns), nil
+	}
+	return nil, ErrInvalidFormat
+}
+
+// text formats a value for the text based formats. Times are written in
+// RFC 3339 and nil as an empty string.
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
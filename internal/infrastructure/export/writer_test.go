@@ -0,0 +1,111 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testColumns = []string{"name", "price", "active", "created_at", "note"}
+	testRow     = []interface{}{"Tea, <green>", 4.5, true, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), nil}
+)
+
+func write(t *testing.T, format Format, rows ...[]interface{}) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, testColumns)
+	assert.NoError(t, err)
+	for _, row := range rows {
+		assert.NoError(t, w.Write(row))
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	assert.Equal(t, "name,price,active,created_at,note\n", string(write(t, FormatCSV)))
+	assert.Equal(t,
+		"name,price,active,created_at,note\n\"Tea, <green>\",4.5,true,2026-10-01T12:00:00Z,\n",
+		string(write(t, FormatCSV, testRow)))
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	assert.Empty(t, write(t, FormatNDJSON))
+	assert.Equal(t,
+		`{"name":"Tea, <green>","price":4.5,"active":true,"created_at":"2026-10-01T12:00:00Z","note":null}`+"\n",
+		string(write(t, FormatNDJSON, testRow)))
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := write(t, FormatXLSX, testRow)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	parts := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		parts[f.Name] = string(content)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts, "xl/workbook.xml")
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"],
+		`<row><c t="inlineStr"><is><t xml:space="preserve">Tea, &lt;green&gt;</t></is></c><c><v>4.5</v></c><c t="b"><v>1</v></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], "</sheetData></worksheet>")
+}
+
+func TestFormulaEscaping(t *testing.T) {
+	row := []interface{}{"=HYPERLINK(\"http://example.com\")", -4.5, false, nil, "@SUM(A1)"}
+
+	assert.Equal(t,
+		"name,price,active,created_at,note\n\"'=HYPERLINK(\"\"http://example.com\"\")\",-4.5,false,,'@SUM(A1)\n",
+		string(write(t, FormatCSV, row)))
+
+	testCases := []struct {
+		value string
+		want  string
+	}{
+		{value: "=1+1", want: "'=1+1"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@A1", want: "'@A1"},
+		{value: "\t=1", want: "'\t=1"},
+		{value: "Tea = 1", want: "Tea = 1"},
+		{value: "", want: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.want, cell(tc.value))
+		})
+	}
+}
+
+func TestXLSXKeepsFormulaText(t *testing.T) {
+	data := write(t, FormatXLSX, []interface{}{"=1+1", -4.5, true, nil, nil})
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(sheet)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content),
+		`<row><c t="inlineStr"><is><t xml:space="preserve">=1+1</t></is></c><c><v>-4.5</v></c>`)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("xlsx")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatXLSX, format)
+
+	_, err = ParseFormat("xls")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
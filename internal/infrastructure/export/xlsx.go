@@ -0,0 +1,128 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// The fixed parts of a workbook with a single sheet
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter writes an Office Open XML workbook with one sheet. Strings are
+// stored inline rather than in a shared string table, which keeps the writer
+// streaming at the cost of a larger file.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []string
+	started bool
+}
+
+func newXLSXWriter(w io.Writer, columns []string) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w), columns: columns}
+}
+
+func (x *xlsxWriter) start() error {
+	if x.started {
+		return nil
+	}
+	x.started = true
+
+	for _, part := range xlsxParts {
+		f, err := x.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(f)
+	x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(x.columns))
+	for i, column := range x.columns {
+		header[i] = column
+	}
+	return x.writeRow(header)
+}
+
+func (x *xlsxWriter) Write(values []interface{}) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	return x.writeRow(values)
+}
+
+func (x *xlsxWriter) writeRow(values []interface{}) error {
+	x.sheet.WriteString("<row>")
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			x.sheet.WriteString("<c/>")
+		case float64:
+			x.sheet.WriteString("<c><v>" + strconv.FormatFloat(v, 'f', -1, 64) + "</v></c>")
+		case int:
+			x.sheet.WriteString("<c><v>" + strconv.Itoa(v) + "</v></c>")
+		case bool:
+			flag := "0"
+			if v {
+				flag = "1"
+			}
+			x.sheet.WriteString(`<c t="b"><v>` + flag + "</v></c>")
+		default:
+			x.writeString(text(v))
+		}
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+func (x *xlsxWriter) writeString(s string) {
+	x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(x.sheet, []byte(s))
+	x.sheet.WriteString("</t></is></c>")
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	x.sheet.WriteString("</sheetData></worksheet>")
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
@@ -42,6 +42,36 @@ var (
 		[]string{"operation"},
 	)
 
+	ReservationsExpiredTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "stock_reservations_expired_total",
+			Help: "Total number of stock reservations released after expiring",
+		},
+	)
+
+	StockBelowReorderPoint = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "stock_below_reorder_point",
+			Help: "Number of products at or below their reorder point, per store",
+		},
+		[]string{"store_id"},
+	)
+
+	ImportRowsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "import_rows_total",
+			Help: "Total number of import rows processed, by kind and outcome (imported, validated or rejected)",
+		},
+		[]string{"kind", "outcome"},
+	)
+
+	IdempotentReplaysTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "idempotent_replays_total",
+			Help: "Total number of responses replayed for repeated requests with the same Idempotency-Key",
+		},
+	)
+
 	MongoDBOperationsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "mongodb_operations_total",
@@ -65,6 +95,10 @@ func init() {
 	prometheus.MustRegister(HTTPRequestDuration)
 	prometheus.MustRegister(ProductOperationsTotal)
 	prometheus.MustRegister(ProductOperationDuration)
+	prometheus.MustRegister(ReservationsExpiredTotal)
+	prometheus.MustRegister(StockBelowReorderPoint)
+	prometheus.MustRegister(ImportRowsTotal)
+	prometheus.MustRegister(IdempotentReplaysTotal)
 	prometheus.MustRegister(MongoDBOperationsTotal)
 	prometheus.MustRegister(MongoDBOperationDuration)
 }
@@ -0,0 +1,93 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/cart"
+)
+
+type CartRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewCartRepository(client *mongo.Client, databaseName string) *CartRepository {
+	collection := client.Database(databaseName).Collection("carts")
+	return &CartRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the carts_expiry TTL index, which lets MongoDB delete
+// carts once expires_at has passed.
+func (r *CartRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("carts_expiry").SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (r *CartRepository) Create(ctx context.Context, c *cart.Cart) error {
+	_, err := r.collection.InsertOne(ctx, c)
+	return err
+}
+
+// GetByID returns an unexpired cart. MongoDB only removes expired documents
+// periodically, so expiry is checked here as well.
+func (r *CartRepository) GetByID(ctx context.Context, id string) (*cart.Cart, error) {
+	var c cart.Cart
+	filter := bson.M{"_id": id, "expires_at": bson.M{"$gt": time.Now()}}
+
+	err := r.collection.FindOne(ctx, filter).Decode(&c)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, cart.ErrCartNotFound
+		}
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *CartRepository) Update(ctx context.Context, c *cart.Cart) error {
+	update := bson.M{
+		"$set": bson.M{
+			"lines":      c.Lines,
+			"updated_at": c.UpdatedAt,
+			"expires_at": c.ExpiresAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": c.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return cart.ErrCartNotFound
+	}
+
+	return nil
+}
+
+func (r *CartRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return cart.ErrCartNotFound
+	}
+
+	return nil
+}
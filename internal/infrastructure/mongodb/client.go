@@ -23,3 +23,23 @@ func NewClient(uri string) (*mongo.Client, error) {
 
 	return client, nil
 }
+
+// withTransaction runs fn in a transaction, which needs MongoDB to run as a
+// replica set or sharded cluster. fn may be retried on transient errors. When
+// ctx already carries a session, fn joins its transaction.
+func withTransaction(ctx context.Context, client *mongo.Client, fn func(sc mongo.SessionContext) error) error {
+	if session := mongo.SessionFromContext(ctx); session != nil {
+		return fn(mongo.NewSessionContext(ctx, session))
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
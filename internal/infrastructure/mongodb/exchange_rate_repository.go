@@ -0,0 +1,100 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/currency"
+)
+
+type ExchangeRateRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewExchangeRateRepository(client *mongo.Client, databaseName string) *ExchangeRateRepository {
+	collection := client.Database(databaseName).Collection("exchange_rates")
+	return &ExchangeRateRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the unique index on currency and effective date,
+// which keeps one rate per currency and date and finds the rate in effect at
+// a given time.
+func (r *ExchangeRateRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "currency", Value: 1}, {Key: "effective_from", Value: -1}},
+		Options: options.Index().SetName("exchange_rates_currency_date").SetUnique(true),
+	})
+	return err
+}
+
+func (r *ExchangeRateRepository) Save(ctx context.Context, rates []*currency.Rate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(rates))
+	for _, rate := range rates {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"currency": rate.Currency, "effective_from": rate.EffectiveFrom}).
+			SetUpdate(bson.M{
+				"$set":         bson.M{"rate": rate.Rate},
+				"$setOnInsert": bson.M{"_id": rate.ID, "created_at": rate.CreatedAt},
+			}).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (r *ExchangeRateRepository) List(ctx context.Context, code string) ([]*currency.Rate, error) {
+	var rates []*currency.Rate
+
+	query := bson.M{}
+	if code != "" {
+		query["currency"] = code
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "currency", Value: 1}, {Key: "effective_from", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &rates); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *ExchangeRateRepository) FindEffective(ctx context.Context, code string, at time.Time) (*currency.Rate, error) {
+	var rate currency.Rate
+
+	query := bson.M{
+		"currency":       code,
+		"effective_from": bson.M{"$lte": at},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "effective_from", Value: -1}})
+
+	err := r.collection.FindOne(ctx, query, opts).Decode(&rate)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, currency.ErrRateNotFound
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
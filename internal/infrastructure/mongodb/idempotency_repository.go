@@ -0,0 +1,120 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/idempotency"
+)
+
+type IdempotencyRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewIdempotencyRepository(client *mongo.Client, databaseName string) *IdempotencyRepository {
+	collection := client.Database(databaseName).Collection("idempotency_keys")
+	return &IdempotencyRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the idempotency_keys_expiry TTL index, which lets
+// MongoDB remove records once expires_at has passed.
+func (r *IdempotencyRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("idempotency_keys_expiry").SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Create inserts the record, replacing an expired record for the key that the
+// TTL monitor has not removed yet. A live record makes the upsert collide on
+// _id.
+func (r *IdempotencyRepository) Create(ctx context.Context, record *idempotency.Record) error {
+	filter := bson.M{"_id": record.Key, "expires_at": bson.M{"$lte": time.Now()}}
+	_, err := r.collection.ReplaceOne(ctx, filter, record, options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return idempotency.ErrKeyExists
+	}
+	return err
+}
+
+// GetByKey returns the record for a key. Records past their expiry that the
+// TTL monitor has not removed yet are treated as gone.
+func (r *IdempotencyRepository) GetByKey(ctx context.Context, key string) (*idempotency.Record, error) {
+	var record idempotency.Record
+	filter := bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}
+	err := r.collection.FindOne(ctx, filter).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, idempotency.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (r *IdempotencyRepository) TakeOver(ctx context.Context, record *idempotency.Record, lockedUntil time.Time) error {
+	filter := bson.M{"_id": record.Key, "completed": false, "locked_until": lockedUntil}
+	result, err := r.collection.ReplaceOne(ctx, filter, record)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return idempotency.ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, record *idempotency.Record) error {
+	update := bson.M{
+		"$set": bson.M{
+			"completed":    true,
+			"status":       record.Status,
+			"content_type": record.ContentType,
+			"body":         record.Body,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, ownedBy(record), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return idempotency.ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, record *idempotency.Record) error {
+	result, err := r.collection.DeleteOne(ctx, ownedBy(record))
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return idempotency.ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+// ownedBy matches the record only while it is still locked by the request
+// that created it, and has not been taken over by a retry
+func ownedBy(record *idempotency.Record) bson.M {
+	return bson.M{"_id": record.Key, "completed": false, "locked_until": record.LockedUntil}
+}
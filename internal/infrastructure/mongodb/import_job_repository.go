@@ -0,0 +1,236 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/importjob"
+)
+
+// ImportJobRepository stores jobs in the import_jobs collection and their
+// uploaded files in the import_payloads GridFS bucket, under the job's ID, so
+// that a file is not bound by the document size limit.
+type ImportJobRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewImportJobRepository(client *mongo.Client, databaseName string) *ImportJobRepository {
+	collection := client.Database(databaseName).Collection("import_jobs")
+	return &ImportJobRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+func (r *ImportJobRepository) payloads() (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(r.client.Database(r.databaseName), options.GridFSBucket().SetName("import_payloads"))
+}
+
+// EnsureIndexes creates the index on status and creation time, which lets the
+// worker claim the oldest queued job, the index on status and lease expiry
+// used to find running jobs whose worker has gone, and the creation time
+// index used by the newest-first job listing.
+func (r *ImportJobRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("import_jobs_status_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "lease_until", Value: 1}},
+			Options: options.Index().SetName("import_jobs_status_lease"),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("import_jobs_created"),
+		},
+	})
+	return err
+}
+
+// Create uploads the file before inserting the job, so that a worker never
+// claims a job whose file is missing
+func (r *ImportJobRepository) Create(ctx context.Context, job *importjob.Job) error {
+	bucket, err := r.payloads()
+	if err != nil {
+		return err
+	}
+	if err := bucket.UploadFromStreamWithID(job.ID, job.ID.Hex(), bytes.NewReader(job.Payload)); err != nil {
+		return err
+	}
+
+	if _, err := r.collection.InsertOne(ctx, job); err != nil {
+		if deleteErr := bucket.Delete(job.ID); deleteErr != nil {
+			return errors.Join(err, deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByID loads a job with its rejections but without the uploaded file
+func (r *ImportJobRepository) GetByID(ctx context.Context, id string) (*importjob.Job, error) {
+	var job importjob.Job
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, importjob.ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// List returns jobs newest first, without their files and rejections
+func (r *ImportJobRepository) List(ctx context.Context, page, limit int) ([]*importjob.Job, int, error) {
+	var jobs []*importjob.Job
+
+	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * limit)
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.M{"rejections": 0})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, int(total), nil
+}
+
+// ClaimNext claims a job and loads its file. Jobs queued by earlier versions
+// kept the file in the job document, where it is still read from.
+func (r *ImportJobRepository) ClaimNext(ctx context.Context, lease time.Duration) (*importjob.Job, error) {
+	now := time.Now()
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": importjob.StatusQueued},
+		bson.M{"status": importjob.StatusRunning, "lease_until": bson.M{"$not": bson.M{"$gte": now}}},
+	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":      importjob.StatusRunning,
+			"started_at":  bson.M{"$ifNull": bson.A{"$started_at", now}},
+			"claimed_at":  now,
+			"lease_until": now.Add(lease),
+			"updated_at":  now,
+		}}},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var doc struct {
+		importjob.Job `bson:",inline"`
+		Payload       []byte `bson:"payload,omitempty"`
+	}
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	job := &doc.Job
+	job.Payload = doc.Payload
+	if job.Payload == nil {
+		bucket, err := r.payloads()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if _, err := bucket.DownloadToStream(job.ID, &buf); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, err
+		}
+		job.Payload = buf.Bytes()
+	}
+
+	return job, nil
+}
+
+func (r *ImportJobRepository) SaveProgress(ctx context.Context, job *importjob.Job) error {
+	update := bson.M{
+		"$set": bson.M{
+			"processed_rows": job.ProcessedRows,
+			"imported_rows":  job.ImportedRows,
+			"rejected_rows":  job.RejectedRows,
+			"rejections":     job.Rejections,
+			"lease_until":    job.LeaseUntil,
+			"updated_at":     job.UpdatedAt,
+		},
+	}
+
+	return r.update(ctx, job, update)
+}
+
+func (r *ImportJobRepository) Finish(ctx context.Context, job *importjob.Job) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":         job.Status,
+			"processed_rows": job.ProcessedRows,
+			"imported_rows":  job.ImportedRows,
+			"rejected_rows":  job.RejectedRows,
+			"rejections":     job.Rejections,
+			"error":          job.Error,
+			"finished_at":    job.FinishedAt,
+			"updated_at":     job.UpdatedAt,
+		},
+		"$unset": bson.M{"payload": "", "lease_until": ""},
+	}
+
+	if err := r.update(ctx, job, update); err != nil {
+		return err
+	}
+
+	bucket, err := r.payloads()
+	if err != nil {
+		return err
+	}
+	if err := bucket.Delete(job.ID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return err
+	}
+	return nil
+}
+
+// update changes a job only while it is still claimed by the caller
+func (r *ImportJobRepository) update(ctx context.Context, job *importjob.Job, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": job.ID, "claimed_at": job.ClaimedAt}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return importjob.ErrLeaseLost
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+)
+
+type InventoryRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewInventoryRepository(client *mongo.Client, databaseName string) *InventoryRepository {
+	collection := client.Database(databaseName).Collection("stock")
+	return &InventoryRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the unique store and product index, which keeps a
+// single stock item per product and store, and a partial index over items
+// with a reorder point for the low-stock scan.
+func (r *InventoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "store_id", Value: 1}, {Key: "product_id", Value: 1}},
+			Options: options.Index().SetName("stock_store_product").SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "reorder_point", Value: 1}},
+			Options: options.Index().
+				SetName("stock_reorder_point").
+				SetPartialFilterExpression(bson.M{"reorder_point": bson.M{"$gt": 0}}),
+		},
+	})
+	return err
+}
+
+func (r *InventoryRepository) Get(ctx context.Context, storeID, productID primitive.ObjectID) (*inventory.StockItem, error) {
+	var item inventory.StockItem
+	err := r.collection.FindOne(ctx, bson.M{"store_id": storeID, "product_id": productID}).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, inventory.ErrStockNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *InventoryRepository) ListByStore(ctx context.Context, storeID primitive.ObjectID, page, limit int) ([]*inventory.StockItem, int, error) {
+	var items []*inventory.StockItem
+
+	query := bson.M{"store_id": storeID}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * limit)
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "product_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, 0, err
+	}
+
+	return items, int(total), nil
+}
+
+func (r *InventoryRepository) SetOnHand(ctx context.Context, storeID, productID primitive.ObjectID, onHand int) (*inventory.StockItem, error) {
+	// The reserved guard makes an existing item with more units reserved
+	// than onHand fall through to an insert, which the unique index rejects.
+	filter := bson.M{
+		"store_id":   storeID,
+		"product_id": productID,
+		"reserved":   bson.M{"$lte": onHand},
+	}
+	update := bson.M{
+		"$set":         bson.M{"on_hand": onHand, "updated_at": time.Now()},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "reserved": 0},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var item inventory.StockItem
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&item)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, inventory.ErrStockBelowReserved
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *InventoryRepository) SetReorderRule(ctx context.Context, storeID, productID primitive.ObjectID, point, quantity int) (*inventory.StockItem, error) {
+	filter := bson.M{"store_id": storeID, "product_id": productID}
+	update := bson.M{
+		"$set": bson.M{
+			"reorder_point":    point,
+			"reorder_quantity": quantity,
+			"updated_at":       time.Now(),
+		},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "on_hand": 0, "reserved": 0},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var item inventory.StockItem
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *InventoryRepository) ListBelowReorderPoint(ctx context.Context) ([]*inventory.StockItem, error) {
+	var items []*inventory.StockItem
+
+	filter := bson.M{
+		"reorder_point": bson.M{"$gt": 0},
+		"$expr": bson.M{
+			"$lte": bson.A{bson.M{"$subtract": bson.A{"$on_hand", "$reserved"}}, "$reorder_point"},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "store_id", Value: 1}, {Key: "product_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *InventoryRepository) Receive(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	filter := bson.M{"store_id": storeID, "product_id": productID}
+	update := bson.M{
+		"$inc":         bson.M{"on_hand": quantity},
+		"$set":         bson.M{"updated_at": time.Now()},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "reserved": 0},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *InventoryRepository) Reserve(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	filter := bson.M{
+		"store_id":   storeID,
+		"product_id": productID,
+		"$expr": bson.M{
+			"$gte": bson.A{bson.M{"$subtract": bson.A{"$on_hand", "$reserved"}}, quantity},
+		},
+	}
+	return r.adjust(ctx, filter, bson.M{"reserved": quantity}, inventory.ErrInsufficientStock)
+}
+
+func (r *InventoryRepository) Release(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	filter := bson.M{
+		"store_id":   storeID,
+		"product_id": productID,
+		"reserved":   bson.M{"$gte": quantity},
+	}
+	return r.adjust(ctx, filter, bson.M{"reserved": -quantity}, inventory.ErrStockNotFound)
+}
+
+func (r *InventoryRepository) Commit(ctx context.Context, storeID, productID primitive.ObjectID, quantity int) error {
+	filter := bson.M{
+		"store_id":   storeID,
+		"product_id": productID,
+		"reserved":   bson.M{"$gte": quantity},
+		"on_hand":    bson.M{"$gte": quantity},
+	}
+	return r.adjust(ctx, filter, bson.M{"on_hand": -quantity, "reserved": -quantity}, inventory.ErrStockNotFound)
+}
+
+// adjust applies inc to the item matching filter, returning notMatched if
+// the guard in the filter does not hold.
+func (r *InventoryRepository) adjust(ctx context.Context, filter, inc bson.M, notMatched error) error {
+	update := bson.M{
+		"$inc": inc,
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return notMatched
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/order"
+)
+
+type OrderRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewOrderRepository(client *mongo.Client, databaseName string) *OrderRepository {
+	collection := client.Database(databaseName).Collection("orders")
+	return &OrderRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the indexes behind the order listings, newest first
+// by store and by status.
+func (r *OrderRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "store_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("orders_store_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("orders_status_created"),
+		},
+	})
+	return err
+}
+
+func (r *OrderRepository) Create(ctx context.Context, o *order.Order) error {
+	result, err := r.collection.InsertOne(ctx, o)
+	if err != nil {
+		return err
+	}
+
+	o.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *OrderRepository) GetByID(ctx context.Context, id string) (*order.Order, error) {
+	var o order.Order
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&o)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, order.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (r *OrderRepository) UpdateStatus(ctx context.Context, o *order.Order, previous order.Status) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       o.Status,
+			"paid_at":      o.PaidAt,
+			"fulfilled_at": o.FulfilledAt,
+			"cancelled_at": o.CancelledAt,
+			"updated_at":   o.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": o.ID, "status": previous}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return order.ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+func (r *OrderRepository) List(ctx context.Context, filter order.ListFilter, page, limit int) ([]*order.Order, int, error) {
+	var orders []*order.Order
+
+	query := bson.M{}
+	if filter.StoreID != nil {
+		query["store_id"] = *filter.StoreID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * limit)
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, 0, err
+	}
+
+	return orders, int(total), nil
+}
@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/review"
 )
 
 type ProductRepository struct {
@@ -27,6 +29,30 @@ func NewProductRepository(client *mongo.Client, databaseName string) *ProductRep
 	}
 }
 
+// EnsureIndexes creates the weighted text index used by search and the index
+// on bundle component IDs used to find the bundles containing a product.
+func (r *ProductRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "name", Value: "text"},
+				{Key: "description", Value: "text"},
+			},
+			Options: options.Index().
+				SetName("products_text").
+				SetWeights(bson.D{
+					{Key: "name", Value: 3},
+					{Key: "description", Value: 1},
+				}),
+		},
+		{
+			Keys:    bson.D{{Key: "bundle.components.product_id", Value: 1}},
+			Options: options.Index().SetName("products_bundle_components"),
+		},
+	})
+	return err
+}
+
 func (r *ProductRepository) Create(ctx context.Context, p *product.Product) error {
 	result, err := r.collection.InsertOne(ctx, p)
 	if err != nil {
@@ -55,22 +81,53 @@ func (r *ProductRepository) GetByID(ctx context.Context, id string) (*product.Pr
 	return &p, nil
 }
 
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*product.Product, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			// No product can have a malformed ID
+			continue
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	products := make([]*product.Product, 0, len(ids))
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *ProductRepository) ListBundlesContaining(ctx context.Context, ids []primitive.ObjectID) ([]*product.Product, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"bundle.components.product_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bundles := make([]*product.Product, 0)
+	if err = cursor.All(ctx, &bundles); err != nil {
+		return nil, err
+	}
+
+	return bundles, nil
+}
+
 func (r *ProductRepository) Update(ctx context.Context, p *product.Product) error {
 	objectID, err := primitive.ObjectIDFromHex(p.ID.Hex())
 	if err != nil {
 		return err
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"name":        p.Name,
-			"description": p.Description,
-			"price":       p.Price,
-			"updated_at":  time.Now(),
-		},
-	}
-
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, productUpdate(p))
 	if err != nil {
 		return err
 	}
@@ -82,6 +139,75 @@ func (r *ProductRepository) Update(ctx context.Context, p *product.Product) erro
 	return nil
 }
 
+// productUpdate sets every product field a product edit can change. The
+// rating is left out, see RefreshRating.
+func productUpdate(p *product.Product) bson.M {
+	return bson.M{
+		"$set": bson.M{
+			"name":           p.Name,
+			"description":    p.Description,
+			"price":          p.Price,
+			"category":       p.Category,
+			"default_locale": p.DefaultLocale,
+			"translations":   p.Translations,
+			"tax_class":      p.TaxClass,
+			"bundle":         p.Bundle,
+			"updated_at":     time.Now(),
+		},
+	}
+}
+
+// RefreshRating recomputes the rating summary maintained by the review
+// context from the product's approved reviews and merges it into the
+// product, in a single aggregation on the server. It is kept out of Update so
+// that product edits never overwrite it. The average is rounded half up to
+// one decimal place, as in product.NewRatingSummary.
+func (r *ProductRepository) RefreshRating(ctx context.Context, productID primitive.ObjectID) error {
+	count := bson.M{"$ifNull": bson.A{"$approved.count", 0}}
+	average := bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{count, 0}},
+		bson.M{"$divide": bson.A{
+			bson.M{"$floor": bson.M{"$add": bson.A{
+				bson.M{"$multiply": bson.A{bson.M{"$divide": bson.A{"$approved.total", "$approved.count"}}, 10}},
+				0.5,
+			}}},
+			10,
+		}},
+		0.0,
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": productID}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "reviews",
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"product_id": productID, "status": review.StatusApproved}},
+				bson.M{"$group": bson.M{
+					"_id":   nil,
+					"total": bson.M{"$sum": "$rating"},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+			"as": "approved",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$approved", "preserveNullAndEmptyArrays": true}}},
+		{{Key: "$project", Value: bson.M{"rating": bson.M{"average": average, "count": count}}}},
+		// A product deleted in the meantime is not recreated
+		{{Key: "$merge", Value: bson.M{
+			"into":           r.collection.Name(),
+			"on":             "_id",
+			"whenMatched":    "merge",
+			"whenNotMatched": "discard",
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
+
 func (r *ProductRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -117,3 +243,233 @@ func (r *ProductRepository) List(ctx context.Context) ([]*product.Product, error
 
 	return products, nil
 }
+
+func (r *ProductRepository) Each(ctx context.Context, fn func(*product.Product) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var p product.Product
+		if err := cursor.Decode(&p); err != nil {
+			return err
+		}
+		if err := fn(&p); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (r *ProductRepository) Search(ctx context.Context, query string, page, limit int) ([]*product.SearchHit, int, error) {
+	filter := bson.M{"$text": bson.M{"$search": query}}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		product.Product `bson:",inline"`
+		Score           float64 `bson:"score"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]*product.SearchHit, len(docs))
+	for i := range docs {
+		hits[i] = &product.SearchHit{
+			Product: &docs[i].Product,
+			Score:   docs[i].Score,
+		}
+	}
+
+	return hits, int(total), nil
+}
+
+// duplicateKeyCode is the server error code of an insert that clashes with an
+// existing _id or unique index entry
+const duplicateKeyCode = 11000
+
+// BulkWrite looks up the products targeted by updates and deletes first, so
+// that those whose product does not exist fail with ErrProductNotFound rather
+// than matching nothing unnoticed, and sends the other writes in one
+// unordered batch. A product deleted between the lookup and the batch is not
+// reported.
+func (r *ProductRepository) BulkWrite(ctx context.Context, writes []*product.BulkWrite) ([]error, error) {
+	errs := make([]error, len(writes))
+	missing, err := r.missingTargets(ctx, writes)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range missing {
+		errs[i] = product.ErrProductNotFound
+	}
+
+	// positions holds the index in writes of each model sent
+	models := make([]mongo.WriteModel, 0, len(writes))
+	positions := make([]int, 0, len(writes))
+	for i, model := range bulkModels(writes) {
+		if errs[i] == nil {
+			models = append(models, model)
+			positions = append(positions, i)
+		}
+	}
+	if len(models) == 0 {
+		return errs, nil
+	}
+
+	_, err = r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			errs[positions[writeErr.Index]] = bulkWriteError(writeErr)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// bulkWriteError turns the server error of one write into the error reported
+// for it
+func bulkWriteError(writeErr mongo.BulkWriteError) error {
+	if writeErr.HasErrorCode(duplicateKeyCode) {
+		return product.ErrProductExists
+	}
+	return errors.New(writeErr.Message)
+}
+
+// errBulkTargetsMissing aborts an atomic bulk write whose updates or deletes
+// target products that do not exist
+var errBulkTargetsMissing = errors.New("bulk write targets missing products")
+
+// BulkWriteAtomic runs the writes in a transaction, which needs MongoDB to
+// run as a replica set or sharded cluster. The products targeted by updates
+// and deletes are looked up inside the transaction first, so a missing one
+// aborts the whole write instead of matching nothing. Called within a unit of
+// work, the writes join its transaction.
+func (r *ProductRepository) BulkWriteAtomic(ctx context.Context, writes []*product.BulkWrite) ([]error, error) {
+	errs := make([]error, len(writes))
+	if len(writes) == 0 {
+		return errs, nil
+	}
+
+	models := bulkModels(writes)
+	var missing []int
+	err := withTransaction(ctx, r.client, func(sc mongo.SessionContext) error {
+		var err error
+		missing, err = r.missingTargets(sc, writes)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return errBulkTargetsMissing
+		}
+		_, err = r.collection.BulkWrite(sc, models, options.BulkWrite().SetOrdered(true))
+		return err
+	})
+
+	if errors.Is(err, errBulkTargetsMissing) {
+		for i := range errs {
+			errs[i] = product.ErrBulkAborted
+		}
+		for _, i := range missing {
+			errs[i] = product.ErrProductNotFound
+		}
+		return errs, nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+		for i := range errs {
+			errs[i] = product.ErrBulkAborted
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			errs[writeErr.Index] = bulkWriteError(writeErr)
+		}
+		return errs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// missingTargets returns the positions of the updates and deletes whose
+// product does not exist
+func (r *ProductRepository) missingTargets(ctx context.Context, writes []*product.BulkWrite) ([]int, error) {
+	ids := make([]primitive.ObjectID, 0, len(writes))
+	for _, w := range writes {
+		if w.Action != product.BulkCreate {
+			ids = append(ids, w.ProductID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	exists := make(map[primitive.ObjectID]bool, len(found))
+	for _, f := range found {
+		exists[f.ID] = true
+	}
+
+	var missing []int
+	for i, w := range writes {
+		if w.Action != product.BulkCreate && !exists[w.ProductID] {
+			missing = append(missing, i)
+		}
+	}
+	return missing, nil
+}
+
+func bulkModels(writes []*product.BulkWrite) []mongo.WriteModel {
+	models := make([]mongo.WriteModel, len(writes))
+	for i, w := range writes {
+		switch w.Action {
+		case product.BulkCreate:
+			models[i] = mongo.NewInsertOneModel().SetDocument(w.Product)
+		case product.BulkUpdate:
+			models[i] = mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": w.ProductID}).
+				SetUpdate(productUpdate(w.Product))
+		case product.BulkDelete:
+			models[i] = mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": w.ProductID})
+		}
+	}
+	return models
+}
@@ -0,0 +1,161 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/promotion"
+)
+
+type PromotionRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewPromotionRepository(client *mongo.Client, databaseName string) *PromotionRepository {
+	collection := client.Database(databaseName).Collection("promotions")
+	return &PromotionRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the index on the promotion window used to find the
+// promotions active at a given time.
+func (r *PromotionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "starts_at", Value: 1}, {Key: "ends_at", Value: 1}},
+		Options: options.Index().SetName("promotions_window"),
+	})
+	return err
+}
+
+func (r *PromotionRepository) Create(ctx context.Context, p *promotion.Promotion) error {
+	result, err := r.collection.InsertOne(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	p.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PromotionRepository) GetByID(ctx context.Context, id string) (*promotion.Promotion, error) {
+	var p promotion.Promotion
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&p)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, promotion.ErrPromotionNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r *PromotionRepository) Update(ctx context.Context, p *promotion.Promotion) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       p.Name,
+			"rule":       p.Rule,
+			"scope":      p.Scope,
+			"starts_at":  p.StartsAt,
+			"ends_at":    p.EndsAt,
+			"priority":   p.Priority,
+			"stackable":  p.Stackable,
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": p.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return promotion.ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return promotion.ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) List(ctx context.Context, page, limit int) ([]*promotion.Promotion, int, error) {
+	var promotions []*promotion.Promotion
+
+	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * limit)
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &promotions); err != nil {
+		return nil, 0, err
+	}
+
+	return promotions, int(total), nil
+}
+
+func (r *PromotionRepository) ListActive(ctx context.Context, at time.Time) ([]*promotion.Promotion, error) {
+	var promotions []*promotion.Promotion
+
+	query := bson.M{
+		"starts_at": bson.M{"$lte": at},
+		"$or": bson.A{
+			bson.M{"ends_at": nil},
+			bson.M{"ends_at": bson.M{"$gt": at}},
+		},
+	}
+
+	cursor, err := r.collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &promotions); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
@@ -0,0 +1,161 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/purchaseorder"
+)
+
+type PurchaseOrderRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewPurchaseOrderRepository(client *mongo.Client, databaseName string) *PurchaseOrderRepository {
+	collection := client.Database(databaseName).Collection("purchase_orders")
+	return &PurchaseOrderRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the indexes behind the purchase order listings,
+// newest first by store and by supplier, and the status index used to find
+// open orders.
+func (r *PurchaseOrderRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "store_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("purchase_orders_store_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "supplier_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("purchase_orders_supplier_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}},
+			Options: options.Index().SetName("purchase_orders_status"),
+		},
+	})
+	return err
+}
+
+func (r *PurchaseOrderRepository) Create(ctx context.Context, po *purchaseorder.PurchaseOrder) error {
+	result, err := r.collection.InsertOne(ctx, po)
+	if err != nil {
+		return err
+	}
+
+	po.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PurchaseOrderRepository) GetByID(ctx context.Context, id string) (*purchaseorder.PurchaseOrder, error) {
+	var po purchaseorder.PurchaseOrder
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&po)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, purchaseorder.ErrPurchaseOrderNotFound
+		}
+		return nil, err
+	}
+
+	return &po, nil
+}
+
+func (r *PurchaseOrderRepository) Update(ctx context.Context, po *purchaseorder.PurchaseOrder) error {
+	update := bson.M{
+		"$set": bson.M{
+			"lines":       po.Lines,
+			"total":       po.Total,
+			"status":      po.Status,
+			"sent_at":     po.SentAt,
+			"expected_at": po.ExpectedAt,
+			"received_at": po.ReceivedAt,
+			"updated_at":  po.UpdatedAt,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": po.ID, "version": po.Version}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return purchaseorder.ErrConcurrentUpdate
+	}
+
+	po.Version++
+	return nil
+}
+
+func (r *PurchaseOrderRepository) List(ctx context.Context, filter purchaseorder.ListFilter, page, limit int) ([]*purchaseorder.PurchaseOrder, int, error) {
+	var orders []*purchaseorder.PurchaseOrder
+
+	query := bson.M{}
+	if filter.StoreID != nil {
+		query["store_id"] = *filter.StoreID
+	}
+	if filter.SupplierID != nil {
+		query["supplier_id"] = *filter.SupplierID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * limit)
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, 0, err
+	}
+
+	return orders, int(total), nil
+}
+
+func (r *PurchaseOrderRepository) ListOpen(ctx context.Context) ([]*purchaseorder.PurchaseOrder, error) {
+	var orders []*purchaseorder.PurchaseOrder
+
+	filter := bson.M{
+		"status": bson.M{"$in": bson.A{purchaseorder.StatusSent, purchaseorder.StatusPartiallyReceived}},
+	}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
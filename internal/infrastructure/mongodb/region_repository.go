@@ -0,0 +1,221 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/region"
+)
+
+type RegionRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewRegionRepository(client *mongo.Client, databaseName string) *RegionRepository {
+	collection := client.Database(databaseName).Collection("regions")
+	return &RegionRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the parent index used to find child regions and the
+// multikey ancestors index used to select a region's whole subtree.
+func (r *RegionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "parent_id", Value: 1}},
+			Options: options.Index().SetName("regions_parent"),
+		},
+		{
+			Keys:    bson.D{{Key: "ancestors", Value: 1}},
+			Options: options.Index().SetName("regions_ancestors"),
+		},
+	})
+	return err
+}
+
+func (r *RegionRepository) Create(ctx context.Context, reg *region.Region) error {
+	result, err := r.collection.InsertOne(ctx, reg)
+	if err != nil {
+		return err
+	}
+
+	reg.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *RegionRepository) GetByID(ctx context.Context, id string) (*region.Region, error) {
+	var reg region.Region
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&reg)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, region.ErrRegionNotFound
+		}
+		return nil, err
+	}
+
+	return &reg, nil
+}
+
+func (r *RegionRepository) Update(ctx context.Context, reg *region.Region) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       reg.Name,
+			"parent_id":  reg.ParentID,
+			"ancestors":  reg.Ancestors,
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": reg.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return region.ErrRegionNotFound
+	}
+
+	return nil
+}
+
+func (r *RegionRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return region.ErrRegionNotFound
+	}
+
+	return nil
+}
+
+func (r *RegionRepository) List(ctx context.Context) ([]*region.Region, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *RegionRepository) ListDescendants(ctx context.Context, id string) ([]*region.Region, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.find(ctx, bson.M{"ancestors": objectID})
+}
+
+func (r *RegionRepository) CountChildren(ctx context.Context, id string) (int, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"parent_id": objectID})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// Move runs in a transaction, which needs MongoDB to run as a replica set or
+// sharded cluster. The moved region and the new parent are both written, so
+// that two concurrent moves involving each other conflict instead of
+// creating a cycle.
+func (r *RegionRepository) Move(ctx context.Context, moved *region.Region, previousAncestors []primitive.ObjectID, parent *region.Region) error {
+	now := time.Now()
+	return withTransaction(ctx, r.client, func(sc mongo.SessionContext) error {
+		result, err := r.collection.UpdateOne(sc,
+			bson.M{"_id": moved.ID, "ancestors": path(previousAncestors)},
+			bson.M{"$set": bson.M{
+				"parent_id":  moved.ParentID,
+				"ancestors":  moved.Ancestors,
+				"updated_at": now,
+			}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return region.ErrRegionChanged
+		}
+
+		if parent != nil {
+			result, err := r.collection.UpdateOne(sc,
+				bson.M{"_id": parent.ID, "ancestors": path(parent.Ancestors)},
+				bson.M{"$set": bson.M{"updated_at": now}},
+			)
+			if err != nil {
+				return err
+			}
+			if result.MatchedCount == 0 {
+				return region.ErrRegionChanged
+			}
+		}
+
+		// Replace everything above the moved region in each descendant's
+		// path with the moved region's new ancestors
+		_, err = r.collection.UpdateMany(sc,
+			bson.M{"ancestors": moved.ID},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"ancestors": bson.M{"$concatArrays": bson.A{
+					moved.Ancestors,
+					bson.M{"$slice": bson.A{
+						"$ancestors",
+						bson.M{"$indexOfArray": bson.A{"$ancestors", moved.ID}},
+						bson.M{"$size": "$ancestors"},
+					}},
+				}},
+				"updated_at": now,
+			}}}},
+		)
+		return err
+	})
+}
+
+// path returns ancestors as stored, where a root region has an empty array
+// rather than null
+func path(ancestors []primitive.ObjectID) []primitive.ObjectID {
+	if ancestors == nil {
+		return []primitive.ObjectID{}
+	}
+	return ancestors
+}
+
+func (r *RegionRepository) find(ctx context.Context, filter bson.M) ([]*region.Region, error) {
+	var regions []*region.Region
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &regions); err != nil {
+		return nil, err
+	}
+
+	return regions, nil
+}
@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/reorder"
+)
+
+type ReorderRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewReorderRepository(client *mongo.Client, databaseName string) *ReorderRepository {
+	collection := client.Database(databaseName).Collection("reorder_suggestions")
+	return &ReorderRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the index behind the newest-first suggestion listing
+// per store, and the generation time index used to prune old suggestions.
+func (r *ReorderRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "store_id", Value: 1}, {Key: "generated_at", Value: -1}},
+			Options: options.Index().SetName("reorder_suggestions_store_generated"),
+		},
+		{
+			Keys:    bson.D{{Key: "generated_at", Value: 1}},
+			Options: options.Index().SetName("reorder_suggestions_generated"),
+		},
+	})
+	return err
+}
+
+// Replace inserts the new suggestions before removing the old ones, so that
+// readers never see a store without suggestions in between
+func (r *ReorderRepository) Replace(ctx context.Context, suggestions []*reorder.Suggestion, generatedAt time.Time) error {
+	if len(suggestions) > 0 {
+		docs := make([]interface{}, 0, len(suggestions))
+		for _, s := range suggestions {
+			if s.ID.IsZero() {
+				s.ID = primitive.NewObjectID()
+			}
+			docs = append(docs, s)
+		}
+		if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"generated_at": bson.M{"$lt": generatedAt}})
+	return err
+}
+
+func (r *ReorderRepository) ListByStore(ctx context.Context, storeID primitive.ObjectID) ([]*reorder.Suggestion, error) {
+	var suggestions []*reorder.Suggestion
+
+	opts := options.Find().SetSort(bson.D{{Key: "generated_at", Value: -1}, {Key: "supplier_name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"store_id": storeID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &suggestions); err != nil {
+		return nil, err
+	}
+
+	// Keep only the latest scan in case an earlier one has not been
+	// removed yet
+	latest := make([]*reorder.Suggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		if !s.GeneratedAt.Equal(suggestions[0].GeneratedAt) {
+			break
+		}
+		latest = append(latest, s)
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,110 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/inventory"
+)
+
+type ReservationRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewReservationRepository(client *mongo.Client, databaseName string) *ReservationRepository {
+	collection := client.Database(databaseName).Collection("reservations")
+	return &ReservationRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the index on status and expiry used by the sweeper to
+// find active reservations that have expired.
+func (r *ReservationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("reservations_status_expiry"),
+	})
+	return err
+}
+
+func (r *ReservationRepository) Create(ctx context.Context, reservation *inventory.Reservation) error {
+	result, err := r.collection.InsertOne(ctx, reservation)
+	if err != nil {
+		return err
+	}
+
+	reservation.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ReservationRepository) GetByID(ctx context.Context, id string) (*inventory.Reservation, error) {
+	var reservation inventory.Reservation
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&reservation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, inventory.ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+func (r *ReservationRepository) UpdateStatus(ctx context.Context, reservation *inventory.Reservation, previous inventory.ReservationStatus) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":     reservation.Status,
+			"updated_at": reservation.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": reservation.ID, "status": previous}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return inventory.ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+func (r *ReservationRepository) ListExpired(ctx context.Context, t time.Time, limit int) ([]*inventory.Reservation, error) {
+	var reservations []*inventory.Reservation
+
+	filter := bson.M{
+		"status":     inventory.ReservationActive,
+		"expires_at": bson.M{"$lte": t},
+	}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "expires_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &reservations); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
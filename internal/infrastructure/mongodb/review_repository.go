@@ -0,0 +1,148 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/review"
+)
+
+type ReviewRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewReviewRepository(client *mongo.Client, databaseName string) *ReviewRepository {
+	collection := client.Database(databaseName).Collection("reviews")
+	return &ReviewRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the index behind a product's reviews by status,
+// newest first, which also serves the rating summary, and the index behind
+// review listings across products filtered by status, such as the
+// moderation queue.
+func (r *ReviewRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("reviews_product_status_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("reviews_status_created"),
+		},
+	})
+	return err
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, rv *review.Review) error {
+	result, err := r.collection.InsertOne(ctx, rv)
+	if err != nil {
+		return err
+	}
+
+	rv.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ReviewRepository) GetByID(ctx context.Context, id string) (*review.Review, error) {
+	var rv review.Review
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&rv)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, review.ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return &rv, nil
+}
+
+func (r *ReviewRepository) UpdateStatus(ctx context.Context, rv *review.Review, previous review.Status) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":           rv.Status,
+			"rejection_reason": rv.RejectionReason,
+			"moderated_at":     rv.ModeratedAt,
+			"updated_at":       rv.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": rv.ID, "status": previous}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return review.ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+func (r *ReviewRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return review.ErrReviewNotFound
+	}
+
+	return nil
+}
+
+func (r *ReviewRepository) List(ctx context.Context, filter review.ListFilter, page, limit int) ([]*review.Review, int, error) {
+	var reviews []*review.Review
+
+	query := bson.M{}
+	if filter.ProductID != nil {
+		query["product_id"] = *filter.ProductID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * limit)
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &reviews); err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, int(total), nil
+}
@@ -2,6 +2,8 @@ package mongodb
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -9,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/stasshander/ddd/internal/domain/product"
 	"github.com/stasshander/ddd/internal/domain/store"
 )
 
@@ -27,6 +30,40 @@ func NewStoreRepository(client *mongo.Client, databaseName string) *StoreReposit
 	}
 }
 
+// EnsureIndexes creates the 2dsphere index for nearby queries and the indexes
+// behind the region, status, country and city, and product filters of the
+// store listings.
+func (r *StoreRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "location", Value: "2dsphere"}},
+			Options: options.Index().SetName("stores_location"),
+		},
+		{
+			Keys:    bson.D{{Key: "region_id", Value: 1}},
+			Options: options.Index().SetName("stores_region"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}},
+			Options: options.Index().SetName("stores_status"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "postal_address.country", Value: 1},
+				{Key: "postal_address.city", Value: 1},
+			},
+			Options: options.Index().SetName("stores_country_city"),
+		},
+		{
+			// Multikey index over the product IDs, serving "which stores
+			// carry this product" in the order stores are listed
+			Keys:    bson.D{{Key: "products", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("stores_products"),
+		},
+	})
+	return err
+}
+
 func (r *StoreRepository) Create(ctx context.Context, s *store.Store) error {
 	result, err := r.collection.InsertOne(ctx, s)
 	if err != nil {
@@ -63,10 +100,12 @@ func (r *StoreRepository) Update(ctx context.Context, s *store.Store) error {
 
 	update := bson.M{
 		"$set": bson.M{
-			"name":       s.Name,
-			"address":    s.Address,
-			"products":   s.Products,
-			"updated_at": time.Now(),
+			"name":           s.Name,
+			"address":        s.Address,
+			"postal_address": s.PostalAddress,
+			"location":       s.Location,
+			"opening_hours":  s.OpeningHours,
+			"updated_at":     time.Now(),
 		},
 	}
 
@@ -82,6 +121,71 @@ func (r *StoreRepository) Update(ctx context.Context, s *store.Store) error {
 	return nil
 }
 
+func (r *StoreRepository) UpdateStatus(ctx context.Context, id string, from, to store.Status) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "status": statusQuery(from)}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return r.unmatched(ctx, objectID, store.ErrStatusChanged)
+	}
+
+	return nil
+}
+
+func (r *StoreRepository) AssignRegion(ctx context.Context, id string, regionID *primitive.ObjectID) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"region_id": regionID, "updated_at": time.Now()}}
+	if regionID == nil {
+		update = bson.M{"$unset": bson.M{"region_id": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return store.ErrStoreNotFound
+	}
+
+	return nil
+}
+
+// unmatched explains a conditional update that matched no store: the store
+// is gone, or it exists and failed the condition, reported as conflict.
+func (r *StoreRepository) unmatched(ctx context.Context, id primitive.ObjectID, conflict error) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return store.ErrStoreNotFound
+	}
+	return conflict
+}
+
+// statusQuery matches stores in status. Stores persisted before the status
+// lifecycle have no status, and earlier updates stored it as an empty
+// string; both are treated as open.
+func statusQuery(status store.Status) interface{} {
+	if status == store.StatusOpen {
+		return bson.M{"$in": bson.A{store.StatusOpen, "", nil}}
+	}
+	return status
+}
+
 func (r *StoreRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -100,11 +204,13 @@ func (r *StoreRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *StoreRepository) List(ctx context.Context, page, limit int) ([]*store.Store, int, error) {
+func (r *StoreRepository) List(ctx context.Context, filter store.ListFilter, page, limit int) ([]*store.Store, int, error) {
 	var stores []*store.Store
 
+	query := listQuery(filter)
+
 	// Calculate total count
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, query)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -117,7 +223,7 @@ func (r *StoreRepository) List(ctx context.Context, page, limit int) ([]*store.S
 		SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	// Execute query
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, query, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -131,6 +237,102 @@ func (r *StoreRepository) List(ctx context.Context, page, limit int) ([]*store.S
 	return stores, int(total), nil
 }
 
+func listQuery(filter store.ListFilter) bson.M {
+	query := bson.M{}
+	if filter.Country != "" {
+		query["postal_address.country"] = strings.ToUpper(filter.Country)
+	}
+	if filter.City != "" {
+		query["postal_address.city"] = primitive.Regex{
+			Pattern: "^" + regexp.QuoteMeta(filter.City) + "$",
+			Options: "i",
+		}
+	}
+	if filter.Status != "" {
+		query["status"] = statusQuery(filter.Status)
+	} else if filter.OpenAt != nil {
+		query["status"] = statusQuery(store.StatusOpen)
+	}
+	if filter.OpenAt != nil {
+		query["opening_hours"] = bson.M{"$ne": nil}
+	}
+	if filter.RegionIDs != nil {
+		query["region_id"] = bson.M{"$in": filter.RegionIDs}
+	}
+	if filter.ProductID != nil {
+		query["products"] = *filter.ProductID
+	}
+	return query
+}
+
+func (r *StoreRepository) Each(ctx context.Context, filter store.ListFilter, fn func(*store.Store) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, listQuery(filter), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var s store.Store
+		if err := cursor.Decode(&s); err != nil {
+			return err
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (r *StoreRepository) Summarize(ctx context.Context, filter store.ListFilter) (*store.Summary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: listQuery(filter)}},
+		{{Key: "$facet", Value: bson.M{
+			"stores": bson.A{
+				bson.M{"$count": "count"},
+			},
+			"products": bson.A{
+				bson.M{"$unwind": "$products"},
+				bson.M{"$group": bson.M{"_id": "$products"}},
+				bson.M{"$count": "count"},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Stores []struct {
+			Count int `bson:"count"`
+		} `bson:"stores"`
+		Products []struct {
+			Count int `bson:"count"`
+		} `bson:"products"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	summary := &store.Summary{}
+	if len(results) > 0 {
+		if len(results[0].Stores) > 0 {
+			summary.StoreCount = results[0].Stores[0].Count
+		}
+		if len(results[0].Products) > 0 {
+			summary.DistinctProductCount = results[0].Products[0].Count
+		}
+	}
+
+	return summary, nil
+}
+
 func (r *StoreRepository) AddProduct(ctx context.Context, storeID string, productID string) error {
 	storeObjectID, err := primitive.ObjectIDFromHex(storeID)
 	if err != nil {
@@ -142,12 +344,17 @@ func (r *StoreRepository) AddProduct(ctx context.Context, storeID string, produc
 		return err
 	}
 
-	_, err = r.collection.UpdateOne(
-		ctx,
-		bson.M{"_id": storeObjectID},
-		bson.M{"$addToSet": bson.M{"product_ids": productObjectID}},
-	)
-	return err
+	filter := bson.M{"_id": storeObjectID, "status": bson.M{"$ne": store.StatusClosed}}
+	result, err := r.collection.UpdateOne(ctx, filter, productsUpdate("$addToSet", productObjectID))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return r.unmatched(ctx, storeObjectID, store.ErrStoreClosed)
+	}
+
+	return nil
 }
 
 func (r *StoreRepository) RemoveProduct(ctx context.Context, storeID string, productID string) error {
@@ -161,10 +368,167 @@ func (r *StoreRepository) RemoveProduct(ctx context.Context, storeID string, pro
 		return err
 	}
 
-	_, err = r.collection.UpdateOne(
-		ctx,
-		bson.M{"_id": storeObjectID},
-		bson.M{"$pull": bson.M{"product_ids": productObjectID}},
-	)
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": storeObjectID}, productsUpdate("$pull", productObjectID))
 	return err
 }
+
+// productsUpdate applies op to the products array, the field the store
+// listings and the product filter read
+func productsUpdate(op string, productID primitive.ObjectID) bson.M {
+	return bson.M{
+		op:     bson.M{"products": productID},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+}
+
+// MigrateProductIDs moves product IDs that earlier versions wrote to
+// product_ids into products, where every read looks for them, and returns
+// the number of stores changed. Stores without product_ids are left alone,
+// so it is safe to run at every startup.
+func (r *StoreRepository) MigrateProductIDs(ctx context.Context) (int64, error) {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"products": bson.M{"$setUnion": bson.A{
+				bson.M{"$ifNull": bson.A{"$products", bson.A{}}},
+				bson.M{"$ifNull": bson.A{"$product_ids", bson.A{}}},
+			}},
+		}}},
+		{{Key: "$unset", Value: "product_ids"}},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, bson.M{"product_ids": bson.M{"$exists": true}}, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *StoreRepository) FindNearby(ctx context.Context, query *store.NearbyQuery, page, limit int) ([]*store.NearbyStore, int, error) {
+	nearbyFilter := query.Filter
+	nearbyFilter.OpenAt = nil
+	filter := listQuery(nearbyFilter)
+	if query.ProductID != nil {
+		filter["products"] = *query.ProductID
+	}
+
+	// $geoNear cannot be combined with a count, so the total is taken from an
+	// equivalent $geoWithin query.
+	countFilter := bson.M{
+		"location": bson.M{
+			"$geoWithin": bson.M{
+				"$centerSphere": bson.A{query.Origin.Coordinates, query.RadiusRadians()},
+			},
+		},
+	}
+	for k, v := range filter {
+		countFilter[k] = v
+	}
+
+	total, err := r.collection.CountDocuments(ctx, countFilter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          query.Origin,
+			"distanceField": "distance",
+			"maxDistance":   query.RadiusKm * 1000,
+			"spherical":     true,
+			"query":         filter,
+		}}},
+		{{Key: "$skip", Value: int64((page - 1) * limit)}},
+		{{Key: "$limit", Value: int64(limit)}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		store.Store `bson:",inline"`
+		Distance    float64 `bson:"distance"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	stores := make([]*store.NearbyStore, len(docs))
+	for i := range docs {
+		stores[i] = &store.NearbyStore{
+			Store:      &docs[i].Store,
+			DistanceKm: docs[i].Distance / 1000,
+		}
+	}
+
+	return stores, int(total), nil
+}
+
+// GetWithProducts loads a store and then a page of its products with a
+// query on the product IDs, which is served by the _id index of the products
+// collection.
+func (r *StoreRepository) GetWithProducts(ctx context.Context, id string, expansion store.ProductExpansion) (*store.Store, *store.ProductPage, error) {
+	st, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := &store.ProductPage{Products: make([]*product.Product, 0)}
+	if len(st.Products) == 0 {
+		return st, page, nil
+	}
+
+	products := r.client.Database(r.databaseName).Collection("products")
+	filter := bson.M{"_id": bson.M{"$in": st.Products}}
+
+	total, err := products.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	page.Total = int(total)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64((expansion.Page - 1) * expansion.Limit)).
+		SetLimit(int64(expansion.Limit))
+	if projection := productProjection(expansion.Fields); projection != nil {
+		opts.SetProjection(projection)
+	}
+
+	cursor, err := products.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &page.Products); err != nil {
+		return nil, nil, err
+	}
+
+	return st, page, nil
+}
+
+// productProjection projects products onto the selected fields, or returns
+// nil to keep every field. Translations and the default locale are kept
+// alongside a selected name or description so they can still be localized.
+func productProjection(fields []string) bson.M {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	projection := bson.M{"_id": 1}
+	for _, field := range fields {
+		switch field {
+		case "id":
+		case "name", "description":
+			projection[field] = 1
+			projection["translations"] = 1
+			projection["default_locale"] = 1
+		default:
+			projection[field] = 1
+		}
+	}
+	return projection
+}
@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// applyArrayUpdate applies the $addToSet or $pull part of an update to a
+// decoded document the way MongoDB would
+func applyArrayUpdate(doc bson.M, update bson.M) {
+	for op, fields := range update {
+		for field, value := range fields.(bson.M) {
+			values, _ := doc[field].(bson.A)
+			switch op {
+			case "$addToSet":
+				doc[field] = append(values, value)
+			case "$pull":
+				kept := bson.A{}
+				for _, v := range values {
+					if v != value {
+						kept = append(kept, v)
+					}
+				}
+				doc[field] = kept
+			}
+		}
+	}
+}
+
+// matches reports whether doc satisfies a query of equality conditions,
+// treating array fields as matching any of their elements
+func matches(doc bson.M, query bson.M) bool {
+	for field, want := range query {
+		values, ok := doc[field].(bson.A)
+		if !ok {
+			return false
+		}
+		found := false
+		for _, v := range values {
+			if v == want {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAddedProductAppearsInProductFilter(t *testing.T) {
+	productID := primitive.NewObjectID()
+	query := listQuery(store.ListFilter{ProductID: &productID})
+
+	raw, err := bson.Marshal(&store.Store{ID: primitive.NewObjectID(), Name: "Main Street"})
+	assert.NoError(t, err)
+	var doc bson.M
+	assert.NoError(t, bson.Unmarshal(raw, &doc))
+	assert.False(t, matches(doc, query))
+
+	applyArrayUpdate(doc, productsUpdate("$addToSet", productID))
+	assert.True(t, matches(doc, query))
+
+	raw, err = bson.Marshal(doc)
+	assert.NoError(t, err)
+	var st store.Store
+	assert.NoError(t, bson.Unmarshal(raw, &st))
+	assert.True(t, st.HasProduct(productID))
+
+	applyArrayUpdate(doc, productsUpdate("$pull", productID))
+	assert.False(t, matches(doc, query))
+}
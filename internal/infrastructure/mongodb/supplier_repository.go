@@ -0,0 +1,156 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/supplier"
+)
+
+type SupplierRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewSupplierRepository(client *mongo.Client, databaseName string) *SupplierRepository {
+	collection := client.Database(databaseName).Collection("suppliers")
+	return &SupplierRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the name index behind the supplier listing and the
+// index on supplied product IDs used to find a product's suppliers.
+func (r *SupplierRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetName("suppliers_name"),
+		},
+		{
+			Keys:    bson.D{{Key: "products.product_id", Value: 1}},
+			Options: options.Index().SetName("suppliers_products"),
+		},
+	})
+	return err
+}
+
+func (r *SupplierRepository) Create(ctx context.Context, s *supplier.Supplier) error {
+	result, err := r.collection.InsertOne(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	s.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SupplierRepository) GetByID(ctx context.Context, id string) (*supplier.Supplier, error) {
+	var s supplier.Supplier
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&s)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, supplier.ErrSupplierNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *SupplierRepository) Update(ctx context.Context, s *supplier.Supplier) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":           s.Name,
+			"contact":        s.Contact,
+			"lead_time_days": s.LeadTimeDays,
+			"products":       s.Products,
+			"updated_at":     s.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": s.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return supplier.ErrSupplierNotFound
+	}
+
+	return nil
+}
+
+func (r *SupplierRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return supplier.ErrSupplierNotFound
+	}
+
+	return nil
+}
+
+func (r *SupplierRepository) List(ctx context.Context, page, limit int) ([]*supplier.Supplier, int, error) {
+	var suppliers []*supplier.Supplier
+
+	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := int64((page - 1) * limit)
+	opts := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &suppliers); err != nil {
+		return nil, 0, err
+	}
+
+	return suppliers, int(total), nil
+}
+
+func (r *SupplierRepository) ListByProduct(ctx context.Context, productID primitive.ObjectID) ([]*supplier.Supplier, error) {
+	var suppliers []*supplier.Supplier
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"products.product_id": productID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &suppliers); err != nil {
+		return nil, err
+	}
+
+	return suppliers, nil
+}
@@ -0,0 +1,148 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/stasshander/ddd/internal/domain/tax"
+)
+
+type TaxRepository struct {
+	client       *mongo.Client
+	databaseName string
+	collection   *mongo.Collection
+}
+
+func NewTaxRepository(client *mongo.Client, databaseName string) *TaxRepository {
+	collection := client.Database(databaseName).Collection("tax_rates")
+	return &TaxRepository{
+		client:       client,
+		databaseName: databaseName,
+		collection:   collection,
+	}
+}
+
+// EnsureIndexes creates the unique jurisdiction index, which keeps a single
+// rate table per country and region and looks it up for a store.
+func (r *TaxRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "country", Value: 1}, {Key: "region", Value: 1}},
+		Options: options.Index().SetName("tax_rates_jurisdiction").SetUnique(true),
+	})
+	return err
+}
+
+func (r *TaxRepository) Create(ctx context.Context, t *tax.RateTable) error {
+	result, err := r.collection.InsertOne(ctx, t)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return tax.ErrDuplicateJurisdiction
+		}
+		return err
+	}
+
+	t.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *TaxRepository) GetByID(ctx context.Context, id string) (*tax.RateTable, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.findOne(ctx, bson.M{"_id": objectID}, nil)
+}
+
+func (r *TaxRepository) Update(ctx context.Context, t *tax.RateTable) error {
+	update := bson.M{
+		"$set": bson.M{
+			"rates":              t.Rates,
+			"prices_include_tax": t.PricesIncludeTax,
+			"updated_at":         time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": t.ID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return tax.ErrRateTableNotFound
+	}
+
+	return nil
+}
+
+func (r *TaxRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return tax.ErrRateTableNotFound
+	}
+
+	return nil
+}
+
+func (r *TaxRepository) List(ctx context.Context) ([]*tax.RateTable, error) {
+	var tables []*tax.RateTable
+
+	opts := options.Find().SetSort(bson.D{{Key: "country", Value: 1}, {Key: "region", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &tables); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+func (r *TaxRepository) FindForJurisdiction(ctx context.Context, country, region string) (*tax.RateTable, error) {
+	query := bson.M{
+		"country": country,
+		"region":  bson.M{"$in": bson.A{tax.NormalizeRegion(region), ""}},
+	}
+
+	// The country-wide table has an empty region and so sorts last
+	opts := options.FindOne().SetSort(bson.D{{Key: "region", Value: -1}})
+
+	return r.findOne(ctx, query, opts)
+}
+
+func (r *TaxRepository) findOne(ctx context.Context, query bson.M, opts *options.FindOneOptions) (*tax.RateTable, error) {
+	var t tax.RateTable
+
+	findOpts := []*options.FindOneOptions{}
+	if opts != nil {
+		findOpts = append(findOpts, opts)
+	}
+
+	err := r.collection.FindOne(ctx, query, findOpts...).Decode(&t)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, tax.ErrRateTableNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
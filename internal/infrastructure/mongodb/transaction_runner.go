@@ -0,0 +1,25 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransactionRunner runs units of work in MongoDB transactions, which need
+// MongoDB to run as a replica set or sharded cluster. Repositories take part
+// in a transaction when they are called with the context passed to the unit
+// of work.
+type TransactionRunner struct {
+	client *mongo.Client
+}
+
+func NewTransactionRunner(client *mongo.Client) *TransactionRunner {
+	return &TransactionRunner{client: client}
+}
+
+func (r *TransactionRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTransaction(ctx, r.client, func(sc mongo.SessionContext) error {
+		return fn(sc)
+	})
+}
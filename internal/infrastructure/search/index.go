@@ -0,0 +1,361 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+)
+
+const (
+	nameWeight        = 3.0
+	descriptionWeight = 1.0
+
+	// Matches that are not exact contribute a reduced score so that exact
+	// hits always rank above prefix hits, which rank above typo corrections.
+	prefixPenalty = 0.8
+	fuzzyPenalty  = 0.5
+
+	// Terms shorter than this are never fuzzy matched, since almost any
+	// short word is within one edit of another.
+	minFuzzyTermLength = 4
+)
+
+// Index is an in-process inverted index over the product catalog. It supports
+// prefix and typo tolerant matching and computes facet counts by price band
+// and category. Index is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]float64
+	vocab    []string
+	dirty    bool
+}
+
+type document struct {
+	product *product.Product
+	terms   map[string]float64
+}
+
+type match struct {
+	doc   *document
+	score float64
+	// fuzzy holds the indexed terms the document was matched on by edit
+	// distance
+	fuzzy []string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// Rebuild replaces the contents of the index with the given products
+func (i *Index) Rebuild(products []*product.Product) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.docs = make(map[string]*document, len(products))
+	i.postings = make(map[string]map[string]float64)
+	for _, p := range products {
+		i.put(p)
+	}
+	i.dirty = true
+}
+
+// Put adds a product to the index or replaces its previous version
+func (i *Index) Put(p *product.Product) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(p.ID.Hex())
+	i.put(p)
+	i.dirty = true
+}
+
+// Remove drops a product from the index
+func (i *Index) Remove(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+	i.dirty = true
+}
+
+// Len returns the number of indexed products
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.docs)
+}
+
+// ProductChanged keeps the index current with writes made through the
+// product application service.
+func (i *Index) ProductChanged(ctx context.Context, change product.Change) {
+	switch change.Type {
+	case product.ChangeCreated, product.ChangeUpdated:
+		i.Put(change.Product)
+	case product.ChangeDeleted:
+		i.Remove(change.ProductID)
+	}
+}
+
+func (i *Index) Search(ctx context.Context, query string, page, limit int) ([]*product.SearchHit, int, error) {
+	hits, total, _, err := i.FacetedSearch(ctx, product.SearchOptions{
+		Query: query,
+		Fuzzy: true,
+		Page:  page,
+		Limit: limit,
+	})
+	return hits, total, err
+}
+
+func (i *Index) FacetedSearch(ctx context.Context, opts product.SearchOptions) ([]*product.SearchHit, int, *product.SearchFacets, error) {
+	i.rLockCurrent()
+	defer i.mu.RUnlock()
+
+	matches := i.match(product.SearchTerms(opts.Query), opts.Fuzzy)
+
+	bandCounts := make(map[string]int)
+	categoryCounts := make(map[string]int)
+	var hits []match
+	for _, m := range matches {
+		band := product.PriceBandOf(m.doc.product.Price)
+		category := m.doc.product.Category
+		inBand := opts.PriceBand == "" || band == opts.PriceBand
+		inCategory := opts.Category == "" || category == opts.Category
+
+		// Each facet is counted with every other filter applied, so the
+		// counts show what selecting a different value would return.
+		if inCategory {
+			bandCounts[band]++
+		}
+		if inBand && category != "" {
+			categoryCounts[category]++
+		}
+		if inBand && inCategory {
+			hits = append(hits, m)
+		}
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].score != hits[b].score {
+			return hits[a].score > hits[b].score
+		}
+		if hits[a].doc.product.Name != hits[b].doc.product.Name {
+			return hits[a].doc.product.Name < hits[b].doc.product.Name
+		}
+		return hits[a].doc.product.ID.Hex() < hits[b].doc.product.ID.Hex()
+	})
+
+	total := len(hits)
+	skip := (opts.Page - 1) * opts.Limit
+	if skip < 0 {
+		skip = 0
+	}
+	end := skip + opts.Limit
+	if skip > total {
+		skip = total
+	}
+	if end > total || opts.Limit <= 0 {
+		end = total
+	}
+
+	page := make([]*product.SearchHit, 0, end-skip)
+	for _, m := range hits[skip:end] {
+		p := *m.doc.product
+		page = append(page, &product.SearchHit{
+			Product:      &p,
+			Score:        m.score,
+			MatchedTerms: m.fuzzy,
+		})
+	}
+
+	return page, total, buildFacets(bandCounts, categoryCounts), nil
+}
+
+// match returns every document containing all query terms, each term matched
+// exactly, by prefix or, when fuzzy is set, within a small edit distance.
+func (i *Index) match(terms []string, fuzzy bool) []match {
+	if len(terms) == 0 {
+		matches := make([]match, 0, len(i.docs))
+		for _, doc := range i.docs {
+			matches = append(matches, match{doc: doc})
+		}
+		return matches
+	}
+
+	var scores map[string]float64
+	fuzzyTerms := make(map[string][]string)
+	for _, term := range terms {
+		termScores := make(map[string]float64)
+		for candidate, weight := range i.expand(term, fuzzy) {
+			for id, tf := range i.postings[candidate] {
+				if s := weight * tf; s > termScores[id] {
+					termScores[id] = s
+				}
+				if weight == fuzzyPenalty {
+					fuzzyTerms[id] = append(fuzzyTerms[id], candidate)
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id := range scores {
+			s, ok := termScores[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += s
+		}
+	}
+
+	matches := make([]match, 0, len(scores))
+	for id, score := range scores {
+		sort.Strings(fuzzyTerms[id])
+		matches = append(matches, match{doc: i.docs[id], score: score, fuzzy: fuzzyTerms[id]})
+	}
+	return matches
+}
+
+// expand returns the indexed terms a query term matches, weighted by how
+// closely they match.
+func (i *Index) expand(term string, fuzzy bool) map[string]float64 {
+	candidates := make(map[string]float64)
+	if _, ok := i.postings[term]; ok {
+		candidates[term] = 1
+	}
+
+	start := sort.SearchStrings(i.vocab, term)
+	for _, t := range i.vocab[start:] {
+		if !strings.HasPrefix(t, term) {
+			break
+		}
+		if t != term {
+			candidates[t] = prefixPenalty
+		}
+	}
+
+	if !fuzzy || len([]rune(term)) < minFuzzyTermLength {
+		return candidates
+	}
+
+	maxEdits := maxEditsFor(term)
+	for _, t := range i.vocab {
+		if _, ok := candidates[t]; ok {
+			continue
+		}
+		if abs(len([]rune(t))-len([]rune(term))) > maxEdits {
+			continue
+		}
+		if levenshtein(term, t) <= maxEdits {
+			candidates[t] = fuzzyPenalty
+		}
+	}
+	return candidates
+}
+
+func (i *Index) put(p *product.Product) {
+	stored := *p
+	doc := &document{
+		product: &stored,
+		terms:   make(map[string]float64),
+	}
+	for _, t := range tokens(p.Name) {
+		doc.terms[t] += nameWeight
+	}
+	for _, t := range tokens(p.Description) {
+		doc.terms[t] += descriptionWeight
+	}
+
+	id := p.ID.Hex()
+	i.docs[id] = doc
+	for t, tf := range doc.terms {
+		if i.postings[t] == nil {
+			i.postings[t] = make(map[string]float64)
+		}
+		i.postings[t][id] = tf
+	}
+}
+
+func (i *Index) remove(id string) {
+	doc, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for t := range doc.terms {
+		delete(i.postings[t], id)
+		if len(i.postings[t]) == 0 {
+			delete(i.postings, t)
+		}
+	}
+	delete(i.docs, id)
+}
+
+// rLockCurrent read-locks the index with an up to date vocabulary. The write
+// lock is only taken when the vocabulary has to be rebuilt, so searches on a
+// clean index run concurrently.
+func (i *Index) rLockCurrent() {
+	i.mu.RLock()
+	for i.dirty {
+		i.mu.RUnlock()
+		i.mu.Lock()
+		if i.dirty {
+			i.rebuildVocab()
+		}
+		i.mu.Unlock()
+		i.mu.RLock()
+	}
+}
+
+func (i *Index) rebuildVocab() {
+	i.vocab = make([]string, 0, len(i.postings))
+	for t := range i.postings {
+		i.vocab = append(i.vocab, t)
+	}
+	sort.Strings(i.vocab)
+	i.dirty = false
+}
+
+func buildFacets(bandCounts, categoryCounts map[string]int) *product.SearchFacets {
+	facets := &product.SearchFacets{
+		PriceBands: make([]product.FacetCount, 0, len(product.PriceBands)),
+		Categories: make([]product.FacetCount, 0, len(categoryCounts)),
+	}
+	for _, band := range product.PriceBands {
+		facets.PriceBands = append(facets.PriceBands, product.FacetCount{
+			Value: band.Label,
+			Count: bandCounts[band.Label],
+		})
+	}
+	for category, count := range categoryCounts {
+		facets.Categories = append(facets.Categories, product.FacetCount{
+			Value: category,
+			Count: count,
+		})
+	}
+	sort.Slice(facets.Categories, func(a, b int) bool {
+		if facets.Categories[a].Count != facets.Categories[b].Count {
+			return facets.Categories[a].Count > facets.Categories[b].Count
+		}
+		return facets.Categories[a].Value < facets.Categories[b].Value
+	})
+	return facets
+}
+
+// tokens returns every term in text, keeping duplicates so that term
+// frequency contributes to the score.
+func tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
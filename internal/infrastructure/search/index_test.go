@@ -0,0 +1,198 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProduct(t *testing.T, name, description string, price float64, category string) *product.Product {
+	p, err := product.NewProduct(name, description, price)
+	assert.NoError(t, err)
+	if category != "" {
+		assert.NoError(t, p.UpdateCategory(category))
+	}
+	return p
+}
+
+func newTestIndex(t *testing.T) *Index {
+	index := NewIndex()
+	index.Rebuild([]*product.Product{
+		newTestProduct(t, "Espresso Machine", "Stainless steel espresso maker", 349.0, "Appliances"),
+		newTestProduct(t, "Espresso Beans", "Dark roast beans", 14.5, "Coffee"),
+		newTestProduct(t, "Chocolate Bar", "Milk chocolate", 2.5, "Sweets"),
+		newTestProduct(t, "Coffee Grinder", "Burr grinder for espresso", 89.0, "Appliances"),
+	})
+	return index
+}
+
+func hitNames(hits []*product.SearchHit) []string {
+	names := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		names = append(names, hit.Product.Name)
+	}
+	return names
+}
+
+func TestIndexSearch(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      product.SearchOptions
+		wantNames []string
+	}{
+		{
+			name:      "ranked by weighted term frequency",
+			opts:      product.SearchOptions{Query: "espresso"},
+			wantNames: []string{"Espresso Machine", "Espresso Beans", "Coffee Grinder"},
+		},
+		{
+			name:      "prefix match",
+			opts:      product.SearchOptions{Query: "choc"},
+			wantNames: []string{"Chocolate Bar"},
+		},
+		{
+			name:      "typo ignored without fuzzy",
+			opts:      product.SearchOptions{Query: "grindr"},
+			wantNames: []string{},
+		},
+		{
+			name:      "typo tolerated with fuzzy",
+			opts:      product.SearchOptions{Query: "grindr", Fuzzy: true},
+			wantNames: []string{"Coffee Grinder"},
+		},
+		{
+			name:      "all terms must match",
+			opts:      product.SearchOptions{Query: "espresso beans"},
+			wantNames: []string{"Espresso Beans"},
+		},
+		{
+			name:      "category filter",
+			opts:      product.SearchOptions{Query: "espresso", Category: "Appliances"},
+			wantNames: []string{"Espresso Machine", "Coffee Grinder"},
+		},
+		{
+			name:      "empty query browses by price band",
+			opts:      product.SearchOptions{PriceBand: "0-10"},
+			wantNames: []string{"Chocolate Bar"},
+		},
+	}
+
+	index := newTestIndex(t)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.Page = 1
+			tc.opts.Limit = 10
+			hits, total, _, err := index.FacetedSearch(context.Background(), tc.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tc.wantNames), total)
+			assert.Equal(t, tc.wantNames, hitNames(hits))
+		})
+	}
+}
+
+func TestIndexReportsFuzzyMatches(t *testing.T) {
+	index := newTestIndex(t)
+
+	hits, _, _, err := index.FacetedSearch(context.Background(), product.SearchOptions{
+		Query: "grindr espres",
+		Fuzzy: true,
+		Page:  1,
+		Limit: 10,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, []string{"grinder"}, hits[0].MatchedTerms)
+	}
+}
+
+func TestIndexFacets(t *testing.T) {
+	index := newTestIndex(t)
+
+	_, total, facets, err := index.FacetedSearch(context.Background(), product.SearchOptions{
+		Query:    "espresso",
+		Category: "Appliances",
+		Page:     1,
+		Limit:    10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	// Category counts ignore the category filter itself
+	assert.Equal(t, []product.FacetCount{
+		{Value: "Appliances", Count: 2},
+		{Value: "Coffee", Count: 1},
+	}, facets.Categories)
+
+	bands := make(map[string]int)
+	for _, band := range facets.PriceBands {
+		bands[band.Value] = band.Count
+	}
+	assert.Equal(t, 1, bands["50-100"])
+	assert.Equal(t, 1, bands["100-500"])
+	assert.Equal(t, 0, bands["10-50"])
+}
+
+func TestIndexPagination(t *testing.T) {
+	index := newTestIndex(t)
+
+	hits, total, err := index.Search(context.Background(), "espresso", 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{"Coffee Grinder"}, hitNames(hits))
+}
+
+func TestIndexProductChanged(t *testing.T) {
+	index := NewIndex()
+	p := newTestProduct(t, "Green Tea", "Loose leaf", 6.0, "")
+
+	index.ProductChanged(context.Background(), product.Change{Type: product.ChangeCreated, ProductID: p.ID.Hex(), Product: p})
+	hits, _, err := index.Search(context.Background(), "tea", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	assert.NoError(t, p.UpdateDescription("Matcha powder"))
+	index.ProductChanged(context.Background(), product.Change{Type: product.ChangeUpdated, ProductID: p.ID.Hex(), Product: p})
+	hits, _, err = index.Search(context.Background(), "matcha", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+	hits, _, err = index.Search(context.Background(), "leaf", 1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	index.ProductChanged(context.Background(), product.Change{Type: product.ChangeDeleted, ProductID: p.ID.Hex()})
+	assert.Equal(t, 0, index.Len())
+}
+
+func TestIndexConcurrentSearchAndWrite(t *testing.T) {
+	index := newTestIndex(t)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(2)
+		go func(n int) {
+			defer wg.Done()
+			index.Put(newTestProduct(t, fmt.Sprintf("Espresso Cup %d", n), "Porcelain", 9.0, "Kitchen"))
+		}(n)
+		go func() {
+			defer wg.Done()
+			_, _, err := index.Search(context.Background(), "espresso", 1, 10)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	_, total, err := index.Search(context.Background(), "cup", 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, total)
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("coffee", "coffee"))
+	assert.Equal(t, 1, levenshtein("coffe", "coffee"))
+	assert.Equal(t, 2, levenshtein("cofee", "toffee"))
+	assert.Equal(t, 3, levenshtein("", "tea"))
+}
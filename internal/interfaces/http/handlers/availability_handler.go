@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stasshander/ddd/internal/application/product"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	domainregion "github.com/stasshander/ddd/internal/domain/region"
+	domainstore "github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AvailabilityHandler struct {
+	availability *product.AvailabilityService
+}
+
+func NewAvailabilityHandler(availability *product.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		availability: availability,
+	}
+}
+
+// availabilityErrorStatus maps product-to-store lookup errors to HTTP status codes
+func availabilityErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainproduct.ErrProductNotFound), errors.Is(err, domainregion.ErrRegionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainstore.ErrInvalidLocation), errors.Is(err, domainstore.ErrInvalidRadius),
+		errors.Is(err, primitive.ErrInvalidHex):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ProductStores lists the stores carrying a product. country, city, status
+// and region_id narrow the stores; lat, lng and radius_km, given together,
+// keep only stores within the radius, nearest first.
+func (h *AvailabilityHandler) ProductStores(c *gin.Context) {
+	filter := domainstore.ListFilter{
+		Country: c.Query("country"),
+		City:    c.Query("city"),
+	}
+	if raw := c.Query("status"); raw != "" {
+		status, err := domainstore.ParseStatus(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+		filter.Status = status
+	}
+
+	var near *domainstore.NearbyQuery
+	if c.Query("lat") != "" || c.Query("lng") != "" || c.Query("radius_km") != "" {
+		lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+		radius, errRadius := strconv.ParseFloat(c.Query("radius_km"), 64)
+		if errLat != nil || errLng != nil || errRadius != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "lat, lng and radius_km must be given together as numbers"))
+			return
+		}
+
+		query, err := domainstore.NewNearbyQuery(lat, lng, radius, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+		near = query
+	}
+
+	pagination := paginationFromQuery(c)
+
+	stores, total, err := h.availability.StoresCarrying(c.Request.Context(), c.Param("id"), filter, c.Query("region_id"), near, pagination.Page, pagination.PageSize)
+	if err != nil {
+		status := availabilityErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(stores, pagination, total))
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stasshander/ddd/internal/application/product"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	domainstore "github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BundleHandler struct {
+	service      *product.Service
+	availability *product.AvailabilityService
+}
+
+func NewBundleHandler(service *product.Service, availability *product.AvailabilityService) *BundleHandler {
+	return &BundleHandler{
+		service:      service,
+		availability: availability,
+	}
+}
+
+// bundleErrorStatus maps bundle related domain errors to HTTP status codes
+func bundleErrorStatus(err error) int {
+	for _, notFound := range []error{
+		domainproduct.ErrProductNotFound,
+		domainstore.ErrStoreNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	if errors.Is(err, domainproduct.ErrCyclicBundle) {
+		return http.StatusConflict
+	}
+
+	for _, invalid := range []error{
+		domainproduct.ErrInvalidName,
+		domainproduct.ErrInvalidDescription,
+		domainproduct.ErrInvalidPrice,
+		domainproduct.ErrNotABundle,
+		domainproduct.ErrEmptyBundle,
+		domainproduct.ErrInvalidComponentQuantity,
+		domainproduct.ErrDuplicateComponent,
+		domainproduct.ErrComponentNotFound,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+type BundleComponentRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required"`
+}
+
+// CreateBundleRequest creates a bundle. Without a price, the bundle price is
+// derived from its components.
+type CreateBundleRequest struct {
+	Name        string                   `json:"name" binding:"required"`
+	Description string                   `json:"description" binding:"required"`
+	Components  []BundleComponentRequest `json:"components" binding:"required,dive"`
+	Price       *float64                 `json:"price"`
+}
+
+type UpdateBundleComponentsRequest struct {
+	Components []BundleComponentRequest `json:"components" binding:"required,dive"`
+}
+
+// SetBundlePriceRequest overrides a bundle price; a null price reverts to the
+// price derived from the components.
+type SetBundlePriceRequest struct {
+	Price *float64 `json:"price"`
+}
+
+func toBundleComponents(requests []BundleComponentRequest) ([]domainproduct.BundleComponent, error) {
+	components := make([]domainproduct.BundleComponent, 0, len(requests))
+	for _, r := range requests {
+		id, err := primitive.ObjectIDFromHex(r.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, domainproduct.BundleComponent{
+			ProductID: id,
+			Quantity:  r.Quantity,
+		})
+	}
+	return components, nil
+}
+
+func (h *BundleHandler) CreateBundle(c *gin.Context) {
+	var req CreateBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	components, err := toBundleComponents(req.Components)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		return
+	}
+
+	bundle, err := h.service.CreateBundle(c.Request.Context(), req.Name, req.Description, components, req.Price)
+	if err != nil {
+		status := bundleErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(bundle))
+}
+
+func (h *BundleHandler) UpdateBundleComponents(c *gin.Context) {
+	id := c.Param("id")
+	var req UpdateBundleComponentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	components, err := toBundleComponents(req.Components)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+		return
+	}
+
+	if err := h.service.UpdateBundleComponents(c.Request.Context(), id, components); err != nil {
+		status := bundleErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	h.respondWithProduct(c, id)
+}
+
+func (h *BundleHandler) SetBundlePrice(c *gin.Context) {
+	id := c.Param("id")
+	var req SetBundlePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	if err := h.service.SetBundlePrice(c.Request.Context(), id, req.Price); err != nil {
+		status := bundleErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	h.respondWithProduct(c, id)
+}
+
+// BundleAvailability reports whether the store given by store_id carries
+// every component of the bundle.
+func (h *BundleHandler) BundleAvailability(c *gin.Context) {
+	storeID := c.Query("store_id")
+	if storeID == "" {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "store_id is required"))
+		return
+	}
+
+	availability, err := h.availability.BundleAvailability(c.Request.Context(), c.Param("id"), storeID)
+	if err != nil {
+		status := bundleErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(availability))
+}
+
+func (h *BundleHandler) respondWithProduct(c *gin.Context, id string) {
+	p, err := h.service.GetProduct(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(p))
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appcart "github.com/stasshander/ddd/internal/application/cart"
+	domaincart "github.com/stasshander/ddd/internal/domain/cart"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CartHandler struct {
+	service *appcart.Service
+}
+
+func NewCartHandler(service *appcart.Service) *CartHandler {
+	return &CartHandler{
+		service: service,
+	}
+}
+
+// cartErrorStatus maps cart errors to HTTP status codes. Errors from placing
+// the order at checkout fall through to orderErrorStatus.
+func cartErrorStatus(err error) int {
+	for _, notFound := range []error{
+		domaincart.ErrCartNotFound,
+		domaincart.ErrLineNotFound,
+		store.ErrStoreNotFound,
+		product.ErrProductNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	for _, conflict := range []error{
+		domaincart.ErrEmptyCart,
+		domaincart.ErrUnavailableItems,
+		domaincart.ErrPricesChanged,
+	} {
+		if errors.Is(err, conflict) {
+			return http.StatusConflict
+		}
+	}
+
+	for _, invalid := range []error{
+		domaincart.ErrInvalidQuantity,
+		domaincart.ErrProductNotInStore,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return orderErrorStatus(err)
+}
+
+type CreateCartRequest struct {
+	StoreID string `json:"store_id" binding:"required"`
+}
+
+type SetCartLineRequest struct {
+	Quantity int `json:"quantity" binding:"required"`
+}
+
+func (h *CartHandler) CreateCart(c *gin.Context) {
+	var req CreateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	cart, err := h.service.CreateCart(c.Request.Context(), req.StoreID)
+	if err != nil {
+		status := cartErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(cart))
+}
+
+func (h *CartHandler) GetCart(c *gin.Context) {
+	cart, err := h.service.GetCart(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := cartErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(cart))
+}
+
+func (h *CartHandler) SetLine(c *gin.Context) {
+	var req SetCartLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	cart, err := h.service.SetLine(c.Request.Context(), c.Param("id"), c.Param("productId"), req.Quantity)
+	if err != nil {
+		status := cartErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(cart))
+}
+
+func (h *CartHandler) RemoveLine(c *gin.Context) {
+	cart, err := h.service.RemoveLine(c.Request.Context(), c.Param("id"), c.Param("productId"))
+	if err != nil {
+		status := cartErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(cart))
+}
+
+func (h *CartHandler) Checkout(c *gin.Context) {
+	order, err := h.service.Checkout(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := cartErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(order))
+}
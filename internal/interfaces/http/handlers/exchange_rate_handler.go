@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appcurrency "github.com/stasshander/ddd/internal/application/currency"
+	domaincurrency "github.com/stasshander/ddd/internal/domain/currency"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+)
+
+type ExchangeRateHandler struct {
+	service *appcurrency.Service
+}
+
+func NewExchangeRateHandler(service *appcurrency.Service) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		service: service,
+	}
+}
+
+// exchangeRateErrorStatus maps currency domain errors to HTTP status codes
+func exchangeRateErrorStatus(err error) int {
+	for _, invalid := range []error{
+		domaincurrency.ErrInvalidCurrency,
+		domaincurrency.ErrInvalidRate,
+		domaincurrency.ErrInvalidEffectiveDate,
+		appcurrency.ErrInvalidCSV,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+type ExchangeRateRequest struct {
+	Currency      string  `json:"currency" binding:"required"`
+	Rate          float64 `json:"rate" binding:"required"`
+	EffectiveFrom string  `json:"effective_from" binding:"required"`
+}
+
+type SaveExchangeRatesRequest struct {
+	Rates []ExchangeRateRequest `json:"rates" binding:"required,dive"`
+}
+
+// ImportSummary reports how many rates an import stored
+type ImportSummary struct {
+	Imported int `json:"imported"`
+}
+
+func (h *ExchangeRateHandler) SaveRates(c *gin.Context) {
+	var req SaveExchangeRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	rates := make([]*domaincurrency.Rate, 0, len(req.Rates))
+	for _, r := range req.Rates {
+		rate, err := domaincurrency.NewRate(r.Currency, r.Rate, r.EffectiveFrom)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := h.service.SaveRates(c.Request.Context(), rates); err != nil {
+		status := exchangeRateErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(ImportSummary{Imported: len(rates)}))
+}
+
+// ImportRates loads exchange rates from a CSV request body
+func (h *ExchangeRateHandler) ImportRates(c *gin.Context) {
+	imported, err := h.service.ImportCSV(c.Request.Context(), c.Request.Body)
+	if err != nil {
+		status := exchangeRateErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(ImportSummary{Imported: imported}))
+}
+
+func (h *ExchangeRateHandler) ListRates(c *gin.Context) {
+	rates, err := h.service.ListRates(c.Request.Context(), c.Query("currency"))
+	if err != nil {
+		status := exchangeRateErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(rates))
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appcurrency "github.com/stasshander/ddd/internal/application/currency"
+	appexport "github.com/stasshander/ddd/internal/application/export"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	domainstore "github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/infrastructure/export"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"golang.org/x/text/language"
+)
+
+var (
+	productExportColumns = []string{
+		"id", "name", "description", "price", "currency", "category", "tax_class", "default_locale",
+		"is_bundle", "rating_average", "rating_count", "created_at", "updated_at",
+	}
+	storeExportColumns = []string{
+		"id", "name", "status", "address", "city", "postal_code", "country",
+		"latitude", "longitude", "region_id", "product_count", "created_at", "updated_at",
+	}
+	assortmentExportColumns = []string{"store_id", "store_name", "product_id"}
+)
+
+type ExportHandler struct {
+	service    *appexport.Service
+	currencies *appcurrency.Service
+}
+
+func NewExportHandler(service *appexport.Service, currencies *appcurrency.Service) *ExportHandler {
+	return &ExportHandler{
+		service:    service,
+		currencies: currencies,
+	}
+}
+
+// ExportProducts streams every product, with names and descriptions in the
+// locale best matching Accept-Language and prices in the currency query
+// parameter, or the base currency, at the rate in effect today
+func (h *ExportHandler) ExportProducts(c *gin.Context) {
+	preferred, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if err != nil {
+		preferred = nil
+	}
+
+	convert, currency, err := h.currencies.PriceConverter(c.Request.Context(), c.DefaultQuery("currency", h.currencies.BaseCurrency()), time.Now())
+	if err != nil {
+		status := conversionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	h.stream(c, "products", productExportColumns, func(write func([]interface{}) error) error {
+		return h.service.EachProduct(c.Request.Context(), func(p *domainproduct.Product) error {
+			localized, _ := p.Localize(preferred)
+			localized.Price = convert(localized.Price)
+			return write(productExportRow(localized, currency))
+		})
+	})
+}
+
+// ExportStores streams the stores matching the store listing filters. With
+// view=assortments it writes one store_id, store_name, product_id row per
+// product a store carries instead, the format assortment imports accept.
+func (h *ExportHandler) ExportStores(c *gin.Context) {
+	filter, err := storeFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	switch c.Query("view") {
+	case "":
+		h.stream(c, "stores", storeExportColumns, func(write func([]interface{}) error) error {
+			return h.service.EachStore(c.Request.Context(), filter, func(st *domainstore.Store) error {
+				return write(storeExportRow(st))
+			})
+		})
+	case "assortments":
+		h.stream(c, "assortments", assortmentExportColumns, func(write func([]interface{}) error) error {
+			return h.service.EachStore(c.Request.Context(), filter, func(st *domainstore.Store) error {
+				for _, id := range st.Products {
+					if err := write([]interface{}{st.ID.Hex(), st.Name, id.Hex()}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+	default:
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "view must be assortments"))
+	}
+}
+
+// exportFormat picks the format from the format query parameter, or else
+// from the Accept header, defaulting to CSV
+func exportFormat(c *gin.Context) (export.Format, error) {
+	if raw := c.Query("format"); raw != "" {
+		return export.ParseFormat(raw)
+	}
+
+	offered := make([]string, len(export.Formats))
+	for i, f := range export.Formats {
+		offered[i] = f.ContentType()
+	}
+	accepted := c.NegotiateFormat(offered...)
+	for _, f := range export.Formats {
+		if f.ContentType() == accepted {
+			return f, nil
+		}
+	}
+	return export.FormatCSV, nil
+}
+
+// stream writes the rows produced by each as a file download. Errors before
+// the first row are answered with a JSON error; once rows have been sent the
+// response can only be cut short, so later errors are logged.
+func (h *ExportHandler) stream(c *gin.Context, name string, columns []string, each func(write func([]interface{}) error) error) {
+	format, err := exportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	// Exports can take longer than the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	filename := name + "-" + time.Now().UTC().Format("20060102") + "." + string(format)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, columns)
+	if err == nil {
+		err = each(writer.Write)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	log.Printf("Export of %s interrupted: %v", name, err)
+}
+
+func productExportRow(p *domainproduct.Product, currency string) []interface{} {
+	return []interface{}{
+		p.ID.Hex(), p.Name, p.Description, p.Price, currency, p.Category, string(p.TaxClass), p.PrimaryLocale(),
+		p.IsBundle(), p.Rating.Average, p.Rating.Count, p.CreatedAt, p.UpdatedAt,
+	}
+}
+
+func storeExportRow(st *domainstore.Store) []interface{} {
+	var city, postalCode, country interface{}
+	if st.PostalAddress != nil {
+		city, postalCode, country = st.PostalAddress.City, st.PostalAddress.PostalCode, st.PostalAddress.Country
+	}
+	var latitude, longitude interface{}
+	if st.Location != nil {
+		latitude, longitude = st.Location.Latitude(), st.Location.Longitude()
+	}
+	var regionID interface{}
+	if st.RegionID != nil {
+		regionID = st.RegionID.Hex()
+	}
+
+	return []interface{}{
+		st.ID.Hex(), st.Name, string(st.CurrentStatus()), st.Address, city, postalCode, country,
+		latitude, longitude, regionID, len(st.Products), st.CreatedAt, st.UpdatedAt,
+	}
+}
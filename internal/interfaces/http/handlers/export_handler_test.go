@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appcurrency "github.com/stasshander/ddd/internal/application/currency"
+	appexport "github.com/stasshander/ddd/internal/application/export"
+	domaincurrency "github.com/stasshander/ddd/internal/domain/currency"
+	"github.com/stasshander/ddd/internal/domain/product"
+	domainstore "github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// catalogProducts walks a fixed list of products
+type catalogProducts struct {
+	product.Repository
+	products []*product.Product
+}
+
+func (c *catalogProducts) Each(ctx context.Context, fn func(*product.Product) error) error {
+	for _, p := range c.products {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catalogStores walks a fixed list of stores
+type catalogStores struct {
+	domainstore.Repository
+	stores []*domainstore.Store
+}
+
+func (c *catalogStores) Each(ctx context.Context, filter domainstore.ListFilter, fn func(*domainstore.Store) error) error {
+	for _, st := range c.stores {
+		if err := fn(st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fixedRates serves one rate per currency regardless of the date
+type fixedRates struct {
+	domaincurrency.Repository
+	rates map[string]*domaincurrency.Rate
+}
+
+func (f *fixedRates) FindEffective(ctx context.Context, code string, at time.Time) (*domaincurrency.Rate, error) {
+	rate, ok := f.rates[code]
+	if !ok {
+		return nil, domaincurrency.ErrRateNotFound
+	}
+	return rate, nil
+}
+
+func setupExportTest(t *testing.T) (*gin.Engine, []*product.Product) {
+	gin.SetMode(gin.TestMode)
+
+	var products []*product.Product
+	for _, name := range []string{"Tea", "=HYPERLINK(\"http://example.com\")"} {
+		p, err := product.NewProduct(name, "Loose leaf", 10)
+		assert.NoError(t, err)
+		p.ID = primitive.NewObjectID()
+		products = append(products, p)
+	}
+
+	st, err := domainstore.NewStore("Main Street", "1 Main Street")
+	assert.NoError(t, err)
+	st.ID = primitive.NewObjectID()
+	st.Products = []primitive.ObjectID{products[0].ID}
+
+	chf, err := domaincurrency.NewRate("CHF", 0.94, "2026-01-01")
+	assert.NoError(t, err)
+	currencies := appcurrency.NewService(
+		&fixedRates{rates: map[string]*domaincurrency.Rate{"CHF": chf}},
+		domaincurrency.NewConverter("EUR", nil),
+	)
+
+	service := appexport.NewService(&catalogProducts{products: products}, &catalogStores{stores: []*domainstore.Store{st}})
+	handler := NewExportHandler(service, currencies)
+
+	router := gin.New()
+	router.GET("/api/exports/products", handler.ExportProducts)
+	router.GET("/api/exports/stores", handler.ExportStores)
+	return router, products
+}
+
+// exportRecords decodes an export of any format into its header and rows
+func exportRecords(t *testing.T, contentType string, body []byte) [][]string {
+	switch contentType {
+	case "text/csv":
+		records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		assert.NoError(t, err)
+		return records
+
+	case "application/x-ndjson":
+		var records [][]string
+		for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+			var row map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(line), &row))
+			records = append(records, []string{row["name"].(string), row["currency"].(string)})
+		}
+		return records
+
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		assert.NoError(t, err)
+		sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+		assert.NoError(t, err)
+		content, err := io.ReadAll(sheet)
+		assert.NoError(t, err)
+		return [][]string{{string(content)}}
+	}
+	t.Fatalf("unexpected content type %q", contentType)
+	return nil
+}
+
+func TestExportProductsFormats(t *testing.T) {
+	testCases := []struct {
+		name            string
+		query           string
+		accept          string
+		wantContentType string
+		wantExtension   string
+	}{
+		{name: "default", wantContentType: "text/csv", wantExtension: ".csv"},
+		{name: "csv parameter", query: "?format=csv", accept: "application/x-ndjson", wantContentType: "text/csv", wantExtension: ".csv"},
+		{name: "ndjson parameter", query: "?format=ndjson", wantContentType: "application/x-ndjson", wantExtension: ".ndjson"},
+		{name: "ndjson accept", accept: "application/x-ndjson", wantContentType: "application/x-ndjson", wantExtension: ".ndjson"},
+		{name: "xlsx parameter", query: "?format=xlsx", wantContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", wantExtension: ".xlsx"},
+		{name: "xlsx accept", accept: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", wantContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", wantExtension: ".xlsx"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, products := setupExportTest(t)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/exports/products"+tc.query, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.wantContentType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Header().Get("Content-Disposition"), `attachment; filename="products-`)
+			assert.Contains(t, w.Header().Get("Content-Disposition"), tc.wantExtension+`"`)
+
+			records := exportRecords(t, tc.wantContentType, w.Body.Bytes())
+			switch tc.wantContentType {
+			case "text/csv":
+				if assert.Len(t, records, 3) {
+					assert.Equal(t, productExportColumns, records[0])
+					assert.Equal(t, []string{products[0].ID.Hex(), "Tea", "Loose leaf", "10", "EUR"}, records[1][:5])
+					assert.Equal(t, `'=HYPERLINK("http://example.com")`, records[2][1])
+				}
+			case "application/x-ndjson":
+				assert.Equal(t, [][]string{{"Tea", "EUR"}, {`=HYPERLINK("http://example.com")`, "EUR"}}, records)
+			default:
+				assert.Contains(t, records[0][0], `<t xml:space="preserve">Tea</t>`)
+				assert.Contains(t, records[0][0], `<t xml:space="preserve">=HYPERLINK(&#34;http://example.com&#34;)</t>`)
+			}
+		})
+	}
+}
+
+func TestExportProductsCurrency(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantPrice    string
+		wantCurrency string
+	}{
+		{name: "base currency", query: "", wantStatus: http.StatusOK, wantPrice: "10", wantCurrency: "EUR"},
+		{name: "converted", query: "?currency=chf", wantStatus: http.StatusOK, wantPrice: "9.4", wantCurrency: "CHF"},
+		{name: "explicit base currency", query: "?currency=EUR", wantStatus: http.StatusOK, wantPrice: "10", wantCurrency: "EUR"},
+		{name: "no rate", query: "?currency=USD", wantStatus: http.StatusBadRequest},
+		{name: "invalid code", query: "?currency=francs", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, _ := setupExportTest(t)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/exports/products"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus != http.StatusOK {
+				assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+				assert.Empty(t, w.Header().Get("Content-Disposition"))
+				return
+			}
+
+			records := exportRecords(t, "text/csv", w.Body.Bytes())
+			if assert.Len(t, records, 3) {
+				assert.Equal(t, []string{tc.wantPrice, tc.wantCurrency}, records[1][3:5])
+			}
+		})
+	}
+}
+
+func TestExportStoreAssortments(t *testing.T) {
+	router, products := setupExportTest(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/exports/stores?view=assortments&format=ndjson", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	var row map[string]string
+	assert.NoError(t, json.Unmarshal(bytes.TrimSpace(w.Body.Bytes()), &row))
+	assert.Equal(t, "Main Street", row["store_name"])
+	assert.Equal(t, products[0].ID.Hex(), row["product_id"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/exports/stores?format=xls", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
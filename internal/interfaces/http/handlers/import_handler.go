@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	appimport "github.com/stasshander/ddd/internal/application/importjob"
+	"github.com/stasshander/ddd/internal/domain/importjob"
+	"github.com/stasshander/ddd/internal/infrastructure/export"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ImportHandler struct {
+	service        *appimport.Service
+	maxUploadBytes int64
+}
+
+func NewImportHandler(service *appimport.Service, maxUploadBytes int64) *ImportHandler {
+	return &ImportHandler{
+		service:        service,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// importErrorStatus maps import domain errors to HTTP status codes
+func importErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, importjob.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, importjob.ErrInvalidKind), errors.Is(err, importjob.ErrInvalidFormat),
+		errors.Is(err, importjob.ErrEmptyImport), errors.Is(err, importjob.ErrMissingColumns),
+		errors.Is(err, primitive.ErrInvalidHex):
+		return http.StatusBadRequest
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateImport queues an import of the uploaded file, given either as the
+// request body or as the file field of a multipart form. kind is products or
+// assortments; format is csv or ndjson and defaults from the file name or
+// content type. With dry_run=true rows are only validated.
+func (h *ImportHandler) CreateImport(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadBytes)
+
+	payload, name, contentType, err := h.readUpload(c)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = detectFormat(name, contentType)
+	}
+
+	job, err := h.service.Submit(c.Request.Context(), c.Query("kind"), format, c.Query("dry_run") == "true", payload)
+	if err != nil {
+		status := importErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.NewSimpleResponse(job))
+}
+
+// readUpload returns the uploaded file with its name, if any, and content type
+func (h *ImportHandler) readUpload(c *gin.Context) ([]byte, string, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		payload, err := io.ReadAll(c.Request.Body)
+		return payload, "", mediaType, err
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", "", err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer file.Close()
+
+	payload, err := io.ReadAll(file)
+	if err != nil {
+		return nil, "", "", err
+	}
+	fileType, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+	return payload, header.Filename, fileType, nil
+}
+
+// detectFormat guesses the import format from a file name or content type,
+// returning an empty format if neither gives it away
+func detectFormat(name, contentType string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return string(importjob.FormatCSV)
+	case ".ndjson", ".jsonl":
+		return string(importjob.FormatNDJSON)
+	}
+
+	switch contentType {
+	case "text/csv":
+		return string(importjob.FormatCSV)
+	case "application/x-ndjson", "application/jsonl", "application/json-lines":
+		return string(importjob.FormatNDJSON)
+	}
+	return ""
+}
+
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	job, err := h.service.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := importErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(job))
+}
+
+func (h *ImportHandler) ListImports(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+
+	jobs, total, err := h.service.ListJobs(c.Request.Context(), pagination.Page, pagination.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(jobs, pagination, total))
+}
+
+// ImportErrors downloads the rejected rows of a job as CSV, with the line
+// number, the reason and the original line
+func (h *ImportHandler) ImportErrors(c *gin.Context) {
+	job, err := h.service.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := importErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	var buf bytes.Buffer
+	w, _ := export.NewWriter(export.FormatCSV, &buf, []string{"row", "error", "raw"})
+	for _, r := range job.Rejections {
+		_ = w.Write([]interface{}{r.Row, r.Error, r.Raw})
+	}
+	_ = w.Close()
+
+	c.Header("Content-Disposition", `attachment; filename="import-`+job.ID.Hex()+`-errors.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appinventory "github.com/stasshander/ddd/internal/application/inventory"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InventoryHandler struct {
+	service *appinventory.Service
+}
+
+func NewInventoryHandler(service *appinventory.Service) *InventoryHandler {
+	return &InventoryHandler{
+		service: service,
+	}
+}
+
+// inventoryErrorStatus maps stock errors to HTTP status codes
+func inventoryErrorStatus(err error) int {
+	for _, notFound := range []error{
+		inventory.ErrStockNotFound,
+		inventory.ErrReservationNotFound,
+		store.ErrStoreNotFound,
+		product.ErrProductNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	for _, conflict := range []error{
+		inventory.ErrStockBelowReserved,
+		inventory.ErrInsufficientStock,
+		inventory.ErrReservationExpired,
+		inventory.ErrReservationNotActive,
+		inventory.ErrConcurrentUpdate,
+	} {
+		if errors.Is(err, conflict) {
+			return http.StatusConflict
+		}
+	}
+
+	for _, invalid := range []error{
+		inventory.ErrInvalidStockLevel,
+		inventory.ErrInvalidReorderRule,
+		inventory.ErrInvalidQuantity,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+type SetStockRequest struct {
+	OnHand *int `json:"on_hand" binding:"required"`
+}
+
+func (h *InventoryHandler) SetStock(c *gin.Context) {
+	var req SetStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	item, err := h.service.SetStock(c.Request.Context(), c.Param("id"), c.Param("productId"), *req.OnHand)
+	if err != nil {
+		status := inventoryErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(item))
+}
+
+type ReorderRuleRequest struct {
+	ReorderPoint    int `json:"reorder_point"`
+	ReorderQuantity int `json:"reorder_quantity"`
+}
+
+// SetReorderRule sets the reorder point and quantity of a product in a
+// store. A reorder point of 0 stops the product from being suggested.
+func (h *InventoryHandler) SetReorderRule(c *gin.Context) {
+	var req ReorderRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	item, err := h.service.SetReorderRule(c.Request.Context(), c.Param("id"), c.Param("productId"), req.ReorderPoint, req.ReorderQuantity)
+	if err != nil {
+		status := inventoryErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(item))
+}
+
+func (h *InventoryHandler) ListStock(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+
+	items, total, err := h.service.ListStock(c.Request.Context(), c.Param("id"), pagination.Page, pagination.PageSize)
+	if err != nil {
+		status := inventoryErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(items, pagination, total))
+}
+
+type ReserveRequest struct {
+	StoreID   string `json:"store_id" binding:"required"`
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required"`
+}
+
+func (h *InventoryHandler) Reserve(c *gin.Context) {
+	var req ReserveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	reservation, err := h.service.Reserve(c.Request.Context(), req.StoreID, req.ProductID, req.Quantity)
+	if err != nil {
+		status := inventoryErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(reservation))
+}
+
+func (h *InventoryHandler) GetReservation(c *gin.Context) {
+	reservation, err := h.service.GetReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := inventoryErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(reservation))
+}
+
+func (h *InventoryHandler) ConfirmReservation(c *gin.Context) {
+	reservation, err := h.service.ConfirmReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := inventoryErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(reservation))
+}
+
+func (h *InventoryHandler) CancelReservation(c *gin.Context) {
+	reservation, err := h.service.CancelReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := inventoryErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(reservation))
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apporder "github.com/stasshander/ddd/internal/application/order"
+	"github.com/stasshander/ddd/internal/domain/inventory"
+	domainorder "github.com/stasshander/ddd/internal/domain/order"
+	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OrderHandler struct {
+	service *apporder.Service
+}
+
+func NewOrderHandler(service *apporder.Service) *OrderHandler {
+	return &OrderHandler{
+		service: service,
+	}
+}
+
+// orderErrorStatus maps order and stock errors to HTTP status codes
+func orderErrorStatus(err error) int {
+	for _, notFound := range []error{
+		domainorder.ErrOrderNotFound,
+		store.ErrStoreNotFound,
+		product.ErrProductNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	for _, conflict := range []error{
+		domainorder.ErrInvalidStatusTransition,
+		domainorder.ErrConcurrentUpdate,
+		domainorder.ErrStoreNotOpen,
+		inventory.ErrInsufficientStock,
+		inventory.ErrStockNotFound,
+	} {
+		if errors.Is(err, conflict) {
+			return http.StatusConflict
+		}
+	}
+
+	for _, invalid := range []error{
+		domainorder.ErrEmptyOrder,
+		domainorder.ErrInvalidQuantity,
+		domainorder.ErrDuplicateLine,
+		domainorder.ErrProductNotInStore,
+		domainorder.ErrInvalidStatus,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+type OrderLineRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required"`
+}
+
+type PlaceOrderRequest struct {
+	StoreID string             `json:"store_id" binding:"required"`
+	Lines   []OrderLineRequest `json:"lines" binding:"required"`
+}
+
+func (h *OrderHandler) PlaceOrder(c *gin.Context) {
+	var req PlaceOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	items := make([]apporder.Item, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		items = append(items, apporder.Item{ProductID: line.ProductID, Quantity: line.Quantity})
+	}
+
+	order, err := h.service.PlaceOrder(c.Request.Context(), req.StoreID, items)
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(order))
+}
+
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	order, err := h.service.GetOrder(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(order))
+}
+
+// ListOrders lists orders, optionally filtered by ?store_id= and ?status=
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	var filter domainorder.ListFilter
+	if storeID := c.Query("store_id"); storeID != "" {
+		id, err := primitive.ObjectIDFromHex(storeID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid store ID"))
+			return
+		}
+		filter.StoreID = &id
+	}
+	if status := c.Query("status"); status != "" {
+		parsed, err := domainorder.ParseStatus(status)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+		filter.Status = parsed
+	}
+
+	pagination := paginationFromQuery(c)
+
+	orders, total, err := h.service.ListOrders(c.Request.Context(), filter, pagination.Page, pagination.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(orders, pagination, total))
+}
+
+func (h *OrderHandler) PayOrder(c *gin.Context) {
+	h.transition(c, h.service.PayOrder)
+}
+
+func (h *OrderHandler) FulfilOrder(c *gin.Context) {
+	h.transition(c, h.service.FulfilOrder)
+}
+
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	h.transition(c, h.service.CancelOrder)
+}
+
+func (h *OrderHandler) transition(c *gin.Context, change func(ctx context.Context, id string) (*domainorder.Order, error)) {
+	order, err := change(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(order))
+}
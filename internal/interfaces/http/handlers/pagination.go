@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+)
+
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// paginationFromQuery reads the page and limit query parameters, falling back
+// to defaults for missing or invalid values.
+func paginationFromQuery(c *gin.Context) *response.Pagination {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = defaultPage
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	return &response.Pagination{
+		Page:     page,
+		PageSize: limit,
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stasshander/ddd/internal/application/pricing"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	domainpromotion "github.com/stasshander/ddd/internal/domain/promotion"
+	domainstore "github.com/stasshander/ddd/internal/domain/store"
+	domaintax "github.com/stasshander/ddd/internal/domain/tax"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PricingHandler struct {
+	service *pricing.Service
+}
+
+func NewPricingHandler(service *pricing.Service) *PricingHandler {
+	return &PricingHandler{
+		service: service,
+	}
+}
+
+// pricingErrorStatus maps errors from price quotes to HTTP status codes
+func pricingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainproduct.ErrProductNotFound), errors.Is(err, domainstore.ErrStoreNotFound),
+		errors.Is(err, domainstore.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainpromotion.ErrInvalidQuantity), errors.Is(err, primitive.ErrInvalidHex):
+		return http.StatusBadRequest
+	case errors.Is(err, domaintax.ErrRateNotDefined):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// QuotePrice returns the price of a product in a store after promotions and
+// tax. quantity defaults to 1 and at, an RFC 3339 timestamp, to now.
+func (h *PricingHandler) QuotePrice(c *gin.Context) {
+	quantity := 1
+	if raw := c.Query("quantity"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "quantity must be an integer"))
+			return
+		}
+		quantity = parsed
+	}
+
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "at must be an RFC 3339 timestamp"))
+			return
+		}
+		at = parsed
+	}
+
+	quote, err := h.service.QuotePrice(c.Request.Context(), c.Param("id"), c.Param("productId"), quantity, at)
+	if err != nil {
+		status := pricingErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(quote))
+}
@@ -1,23 +1,83 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	appcurrency "github.com/stasshander/ddd/internal/application/currency"
 	"github.com/stasshander/ddd/internal/application/product"
+	domaincurrency "github.com/stasshander/ddd/internal/domain/currency"
 	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/text/language"
 )
 
 type ProductHandler struct {
-	service *product.Service
+	service    *product.Service
+	currencies *appcurrency.Service
 }
 
-func NewProductHandler(service *product.Service) *ProductHandler {
+func NewProductHandler(service *product.Service, currencies *appcurrency.Service) *ProductHandler {
 	return &ProductHandler{
-		service: service,
+		service:    service,
+		currencies: currencies,
 	}
 }
 
+// localize returns copies of products with names and descriptions in the
+// locale best matching the Accept-Language header, along with the locale
+// chosen for each product. A malformed header is treated as absent.
+func localize(c *gin.Context, products []*domainproduct.Product) ([]*domainproduct.Product, []string) {
+	preferred, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if err != nil {
+		preferred = nil
+	}
+
+	localized := make([]*domainproduct.Product, 0, len(products))
+	locales := make([]string, 0, len(products))
+	for _, p := range products {
+		l, locale := p.Localize(preferred)
+		localized = append(localized, l)
+		locales = append(locales, locale)
+	}
+	return localized, locales
+}
+
+// conversionErrorStatus maps price conversion errors to HTTP status codes
+func conversionErrorStatus(err error) int {
+	if errors.Is(err, domaincurrency.ErrInvalidCurrency) || errors.Is(err, domaincurrency.ErrRateNotFound) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// convertPrices converts product prices into the currency requested with the
+// currency query parameter. It returns the products unchanged and an empty
+// code if no currency was requested, and writes an error response and
+// returns false if the conversion fails.
+func (h *ProductHandler) convertPrices(c *gin.Context, products []*domainproduct.Product) ([]*domainproduct.Product, string, bool) {
+	code := c.Query("currency")
+	if code == "" {
+		return products, "", true
+	}
+
+	converted, err := h.currencies.ConvertProducts(c.Request.Context(), products, code, time.Now())
+	if err != nil {
+		status := conversionErrorStatus(err)
+		c.JSON(status, gin.H{
+			"success": false,
+			"code":    status,
+			"message": err.Error(),
+		})
+		return nil, "", false
+	}
+
+	code, _ = domaincurrency.ParseCode(code)
+	return converted, code, true
+}
+
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req struct {
 		Name        string  `json:"name" binding:"required"`
@@ -70,10 +130,196 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	localized, locales := localize(c, []*domainproduct.Product{product})
+	converted, currency, ok := h.convertPrices(c, localized)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Language", locales[0])
+	c.Header("Vary", "Accept-Language")
+
+	body := gin.H{
 		"success": true,
 		"code":    http.StatusOK,
-		"data":    product,
+		"data":    converted[0],
+	}
+	if currency != "" {
+		body["currency"] = currency
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// BatchGetProducts returns the products with the requested IDs, in request
+// order, along with the IDs of products that do not exist.
+func (h *ProductHandler) BatchGetProducts(c *gin.Context) {
+	var req struct {
+		IDs []string `json:"ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	products, missing, err := h.service.GetProducts(c.Request.Context(), req.IDs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domainproduct.ErrTooManyIDs) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	localized, _ := localize(c, products)
+	converted, currency, ok := h.convertPrices(c, localized)
+	if !ok {
+		return
+	}
+
+	c.Header("Vary", "Accept-Language")
+
+	body := gin.H{
+		"success": true,
+		"code":    http.StatusOK,
+		"data": gin.H{
+			"products": converted,
+			"missing":  missing,
+		},
+	}
+	if currency != "" {
+		body["currency"] = currency
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+type BulkOperationRequest struct {
+	Action      string   `json:"action"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       *float64 `json:"price"`
+	Category    string   `json:"category"`
+	TaxClass    string   `json:"tax_class"`
+}
+
+// BulkItemResult reports the outcome of one bulk operation with the HTTP
+// status it would have had as a single request
+type BulkItemResult struct {
+	Index  int                    `json:"index"`
+	Action string                 `json:"action"`
+	ID     string                 `json:"id,omitempty"`
+	Status int                    `json:"status"`
+	Error  string                 `json:"error,omitempty"`
+	Data   *domainproduct.Product `json:"data,omitempty"`
+}
+
+// bulkItemStatus maps the outcome of a bulk operation to an HTTP status code
+func bulkItemStatus(action string, err error) int {
+	switch {
+	case err == nil && action == string(domainproduct.BulkCreate):
+		return http.StatusCreated
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, domainproduct.ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainproduct.ErrDuplicateTarget), errors.Is(err, domainproduct.ErrProductInBundle),
+		errors.Is(err, domainproduct.ErrProductExists):
+		return http.StatusConflict
+	case errors.Is(err, domainproduct.ErrBulkAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, domainproduct.ErrInvalidBulkAction), errors.Is(err, domainproduct.ErrEmptyUpdate),
+		errors.Is(err, domainproduct.ErrInvalidName), errors.Is(err, domainproduct.ErrInvalidDescription),
+		errors.Is(err, domainproduct.ErrInvalidPrice), errors.Is(err, domainproduct.ErrInvalidCategory),
+		errors.Is(err, domainproduct.ErrInvalidTaxClass), errors.Is(err, primitive.ErrInvalidHex):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// BulkProducts applies up to 1000 create, update and delete operations and
+// reports each one's outcome. The response is 200 when every operation
+// succeeded and 207 otherwise. With atomic set, a single failure leaves
+// every operation unapplied.
+func (h *ProductHandler) BulkProducts(c *gin.Context) {
+	var req struct {
+		Atomic     bool                   `json:"atomic"`
+		Operations []BulkOperationRequest `json:"operations" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	operations := make([]product.BulkOperation, len(req.Operations))
+	for i, op := range req.Operations {
+		operations[i] = product.BulkOperation{
+			Action:      op.Action,
+			ID:          op.ID,
+			Name:        op.Name,
+			Description: op.Description,
+			Price:       op.Price,
+			Category:    op.Category,
+			TaxClass:    op.TaxClass,
+		}
+	}
+
+	results, err := h.service.Bulk(c.Request.Context(), operations, req.Atomic)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domainproduct.ErrEmptyBulk) || errors.Is(err, domainproduct.ErrBulkTooLarge) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	items := make([]BulkItemResult, len(results))
+	succeeded := 0
+	for i, r := range results {
+		items[i] = BulkItemResult{
+			Index:  i,
+			Action: string(r.Action),
+			ID:     r.ID,
+			Status: bulkItemStatus(string(r.Action), r.Err),
+			Data:   r.Product,
+		}
+		if r.Err != nil {
+			items[i].Error = r.Err.Error()
+		} else {
+			succeeded++
+		}
+	}
+
+	status := http.StatusOK
+	if succeeded < len(results) {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"success": succeeded == len(results),
+		"code":    status,
+		"data":    items,
+		"summary": gin.H{
+			"total":     len(results),
+			"succeeded": succeeded,
+			"failed":    len(results) - succeeded,
+		},
 	})
 }
 
@@ -173,6 +419,110 @@ func (h *ProductHandler) UpdateProductDescription(c *gin.Context) {
 	})
 }
 
+func (h *ProductHandler) UpdateProductCategory(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Category string `json:"category" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.UpdateProductCategory(c.Request.Context(), id, req.Category); err != nil {
+		if err == domainproduct.ErrProductNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"code":    http.StatusNotFound,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"code":    http.StatusInternalServerError,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	product, err := h.service.GetProduct(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"code":    http.StatusInternalServerError,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"code":    http.StatusOK,
+		"data":    product,
+	})
+}
+
+func (h *ProductHandler) UpdateProductTaxClass(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		TaxClass string `json:"tax_class" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.UpdateProductTaxClass(c.Request.Context(), id, req.TaxClass); err != nil {
+		if err == domainproduct.ErrProductNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"code":    http.StatusNotFound,
+				"message": err.Error(),
+			})
+			return
+		}
+		if err == domainproduct.ErrInvalidTaxClass {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"code":    http.StatusInternalServerError,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	product, err := h.service.GetProduct(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"code":    http.StatusInternalServerError,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"code":    http.StatusOK,
+		"data":    product,
+	})
+}
+
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 	err := h.service.DeleteProduct(c.Request.Context(), id)
@@ -185,6 +535,14 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 			})
 			return
 		}
+		if err == domainproduct.ErrProductInBundle {
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"code":    http.StatusConflict,
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"code":    http.StatusInternalServerError,
@@ -211,14 +569,113 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	localized, _ := localize(c, products)
+	converted, currency, ok := h.convertPrices(c, localized)
+	if !ok {
+		return
+	}
+
+	c.Header("Vary", "Accept-Language")
+
+	body := gin.H{
 		"success": true,
 		"code":    http.StatusOK,
-		"data":    products,
+		"data":    converted,
 		"page_info": gin.H{
 			"page":        1,
 			"page_size":   len(products),
 			"total_count": len(products),
 		},
+	}
+	if currency != "" {
+		body["currency"] = currency
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// translationErrorStatus maps product localization errors to HTTP status codes
+func translationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainproduct.ErrProductNotFound), errors.Is(err, domainproduct.ErrTranslationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainproduct.ErrDefaultLocaleRequired):
+		return http.StatusConflict
+	case errors.Is(err, domainproduct.ErrInvalidLocale), errors.Is(err, domainproduct.ErrInvalidName),
+		errors.Is(err, domainproduct.ErrInvalidDescription):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ProductHandler) UpsertTranslation(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	err := h.service.UpsertTranslation(c.Request.Context(), id, c.Param("locale"), req.Name, req.Description)
+	h.respondAfterLocalization(c, id, err)
+}
+
+func (h *ProductHandler) RemoveTranslation(c *gin.Context) {
+	id := c.Param("id")
+	err := h.service.RemoveTranslation(c.Request.Context(), id, c.Param("locale"))
+	h.respondAfterLocalization(c, id, err)
+}
+
+func (h *ProductHandler) SetDefaultLocale(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Locale string `json:"locale" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	err := h.service.SetDefaultLocale(c.Request.Context(), id, req.Locale)
+	h.respondAfterLocalization(c, id, err)
+}
+
+// respondAfterLocalization writes the error of a translation change, or the
+// updated product with all of its translations.
+func (h *ProductHandler) respondAfterLocalization(c *gin.Context, id string, err error) {
+	if err != nil {
+		status := translationErrorStatus(err)
+		c.JSON(status, gin.H{
+			"success": false,
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	product, err := h.service.GetProduct(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"code":    http.StatusInternalServerError,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"code":    http.StatusOK,
+		"data":    product,
 	})
 }
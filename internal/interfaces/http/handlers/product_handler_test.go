@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	appcurrency "github.com/stasshander/ddd/internal/application/currency"
+	"github.com/stasshander/ddd/internal/application/product"
+	domaincurrency "github.com/stasshander/ddd/internal/domain/currency"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/product/producttest"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// directRunner runs units of work without a transaction
+type directRunner struct{}
+
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func setupProductTest(t *testing.T) (*gin.Engine, *producttest.Repository, []*domainproduct.Product) {
+	gin.SetMode(gin.TestMode)
+
+	repo := producttest.NewRepository()
+	service := product.NewService(repo, directRunner{})
+	var products []*domainproduct.Product
+	for _, name := range []string{"Tea", "Coffee", "Cocoa"} {
+		p, err := service.CreateProduct(context.Background(), name, "Hot drink", 4.5)
+		assert.NoError(t, err)
+		products = append(products, p)
+	}
+
+	chf, err := domaincurrency.NewRate("CHF", 0.94, "2026-01-01")
+	assert.NoError(t, err)
+	currencies := appcurrency.NewService(
+		&fixedRates{rates: map[string]*domaincurrency.Rate{"CHF": chf}},
+		domaincurrency.NewConverter("EUR", nil),
+	)
+
+	handler := NewProductHandler(service, currencies)
+	router := gin.New()
+	router.GET("/api/products/:id", handler.GetProduct)
+	router.POST("/api/products/batch-get", handler.BatchGetProducts)
+	router.POST("/api/products/bulk", handler.BulkProducts)
+	return router, repo, products
+}
+
+func postJSON(router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	raw, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetProductLanguageHeaders(t *testing.T) {
+	router, _, products := setupProductTest(t)
+
+	testCases := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantHeaders bool
+	}{
+		{name: "found", path: products[0].ID.Hex(), wantStatus: http.StatusOK, wantHeaders: true},
+		{name: "converted", path: products[0].ID.Hex() + "?currency=CHF", wantStatus: http.StatusOK, wantHeaders: true},
+		{name: "no rate", path: products[0].ID.Hex() + "?currency=USD", wantStatus: http.StatusBadRequest},
+		{name: "not found", path: primitive.NewObjectID().Hex(), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/products/"+tc.path, nil)
+			req.Header.Set("Accept-Language", "de-CH, en;q=0.5")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantHeaders {
+				assert.NotEmpty(t, w.Header().Get("Content-Language"))
+				assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+			} else {
+				assert.Empty(t, w.Header().Get("Content-Language"))
+				assert.Empty(t, w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestBatchGetProducts(t *testing.T) {
+	router, _, products := setupProductTest(t)
+	unknown := primitive.NewObjectID().Hex()
+
+	tooMany := make([]string, product.MaxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = primitive.NewObjectID().Hex()
+	}
+
+	testCases := []struct {
+		name        string
+		ids         []string
+		wantStatus  int
+		wantNames   []string
+		wantMissing []string
+	}{
+		{
+			name:        "request order",
+			ids:         []string{products[2].ID.Hex(), products[0].ID.Hex(), products[1].ID.Hex()},
+			wantStatus:  http.StatusOK,
+			wantNames:   []string{"Cocoa", "Tea", "Coffee"},
+			wantMissing: []string{},
+		},
+		{
+			name:        "missing and repeated",
+			ids:         []string{unknown, products[1].ID.Hex(), products[1].ID.Hex()},
+			wantStatus:  http.StatusOK,
+			wantNames:   []string{"Coffee"},
+			wantMissing: []string{unknown},
+		},
+		{
+			name:        "malformed ID",
+			ids:         []string{"tea", products[0].ID.Hex()},
+			wantStatus:  http.StatusOK,
+			wantNames:   []string{"Tea"},
+			wantMissing: []string{"tea"},
+		},
+		{
+			name:       "too many IDs",
+			ids:        tooMany,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no IDs",
+			ids:        []string{},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := postJSON(router, "/api/products/batch-get", gin.H{"ids": tc.ids})
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data struct {
+					Products []domainproduct.Product `json:"products"`
+					Missing  []string                `json:"missing"`
+				} `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			names := make([]string, 0, len(body.Data.Products))
+			for _, p := range body.Data.Products {
+				names = append(names, p.Name)
+			}
+			assert.Equal(t, tc.wantNames, names)
+			assert.Equal(t, tc.wantMissing, body.Data.Missing)
+		})
+	}
+}
+
+func TestBulkProducts(t *testing.T) {
+	unknown := primitive.NewObjectID().Hex()
+
+	tooMany := make([]gin.H, domainproduct.MaxBulkSize+1)
+	for i := range tooMany {
+		tooMany[i] = gin.H{"action": "delete", "id": primitive.NewObjectID().Hex()}
+	}
+
+	testCases := []struct {
+		name          string
+		atomic        bool
+		operations    func(products []*domainproduct.Product) []gin.H
+		wantStatus    int
+		wantItems     []int
+		wantSummary   map[string]int
+		wantRemaining int
+	}{
+		{
+			name: "all succeed",
+			operations: func(products []*domainproduct.Product) []gin.H {
+				return []gin.H{
+					{"action": "create", "name": "Chai", "description": "Spiced tea", "price": 3.5},
+					{"action": "update", "id": products[0].ID.Hex(), "price": 5},
+					{"action": "delete", "id": products[1].ID.Hex()},
+				}
+			},
+			wantStatus:    http.StatusOK,
+			wantItems:     []int{http.StatusCreated, http.StatusOK, http.StatusOK},
+			wantSummary:   map[string]int{"total": 3, "succeeded": 3, "failed": 0},
+			wantRemaining: 3,
+		},
+		{
+			name: "some fail",
+			operations: func(products []*domainproduct.Product) []gin.H {
+				return []gin.H{
+					{"action": "create", "name": "Chai", "description": "Spiced tea", "price": 3.5},
+					{"action": "update", "id": unknown, "price": 5},
+					{"action": "delete", "id": products[1].ID.Hex()},
+					{"action": "update", "id": products[1].ID.Hex(), "price": 5},
+					{"action": "create", "name": "", "description": "Nameless", "price": 1},
+					{"action": "rename", "id": products[2].ID.Hex()},
+				}
+			},
+			wantStatus: http.StatusMultiStatus,
+			wantItems: []int{
+				http.StatusCreated, http.StatusNotFound, http.StatusOK,
+				http.StatusConflict, http.StatusBadRequest, http.StatusBadRequest,
+			},
+			wantSummary:   map[string]int{"total": 6, "succeeded": 2, "failed": 4},
+			wantRemaining: 3,
+		},
+		{
+			name:   "atomic with a failure",
+			atomic: true,
+			operations: func(products []*domainproduct.Product) []gin.H {
+				return []gin.H{
+					{"action": "create", "name": "Chai", "description": "Spiced tea", "price": 3.5},
+					{"action": "delete", "id": products[0].ID.Hex()},
+					{"action": "delete", "id": unknown},
+				}
+			},
+			wantStatus:    http.StatusMultiStatus,
+			wantItems:     []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusNotFound},
+			wantSummary:   map[string]int{"total": 3, "succeeded": 0, "failed": 3},
+			wantRemaining: 3,
+		},
+		{
+			name: "empty",
+			operations: func(products []*domainproduct.Product) []gin.H {
+				return []gin.H{}
+			},
+			wantStatus:    http.StatusBadRequest,
+			wantRemaining: 3,
+		},
+		{
+			name: "too many operations",
+			operations: func(products []*domainproduct.Product) []gin.H {
+				return tooMany
+			},
+			wantStatus:    http.StatusBadRequest,
+			wantRemaining: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, repo, products := setupProductTest(t)
+
+			w := postJSON(router, "/api/products/bulk", gin.H{"atomic": tc.atomic, "operations": tc.operations(products)})
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Len(t, repo.Products, tc.wantRemaining)
+			if tc.wantStatus == http.StatusBadRequest {
+				return
+			}
+
+			var body struct {
+				Success bool             `json:"success"`
+				Data    []BulkItemResult `json:"data"`
+				Summary map[string]int   `json:"summary"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			statuses := make([]int, 0, len(body.Data))
+			for i, item := range body.Data {
+				assert.Equal(t, i, item.Index)
+				assert.Equal(t, item.Status >= http.StatusBadRequest, item.Error != "", "item %d", i)
+				statuses = append(statuses, item.Status)
+			}
+			assert.Equal(t, tc.wantItems, statuses)
+			assert.Equal(t, tc.wantSummary, body.Summary)
+			assert.Equal(t, tc.wantStatus == http.StatusOK, body.Success)
+		})
+	}
+}
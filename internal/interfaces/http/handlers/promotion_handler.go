@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apppromotion "github.com/stasshander/ddd/internal/application/promotion"
+	domainpromotion "github.com/stasshander/ddd/internal/domain/promotion"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PromotionHandler struct {
+	service *apppromotion.Service
+}
+
+func NewPromotionHandler(service *apppromotion.Service) *PromotionHandler {
+	return &PromotionHandler{
+		service: service,
+	}
+}
+
+// promotionErrorStatus maps promotion domain errors to HTTP status codes
+func promotionErrorStatus(err error) int {
+	if errors.Is(err, domainpromotion.ErrPromotionNotFound) {
+		return http.StatusNotFound
+	}
+
+	for _, invalid := range []error{
+		domainpromotion.ErrInvalidName,
+		domainpromotion.ErrInvalidType,
+		domainpromotion.ErrInvalidPercent,
+		domainpromotion.ErrInvalidAmount,
+		domainpromotion.ErrInvalidBuyGet,
+		domainpromotion.ErrInvalidWindow,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// PromotionRequest defines a promotion. starts_at defaults to now and a
+// missing ends_at keeps the promotion running indefinitely.
+type PromotionRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Type        string     `json:"type" binding:"required"`
+	Percent     float64    `json:"percent"`
+	Amount      float64    `json:"amount"`
+	BuyQuantity int        `json:"buy_quantity"`
+	GetQuantity int        `json:"get_quantity"`
+	StoreIDs    []string   `json:"store_ids"`
+	ProductIDs  []string   `json:"product_ids"`
+	Categories  []string   `json:"categories"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Priority    int        `json:"priority"`
+	Stackable   bool       `json:"stackable"`
+}
+
+func (r *PromotionRequest) toDefinition() (apppromotion.Definition, error) {
+	storeIDs, err := parseObjectIDs(r.StoreIDs)
+	if err != nil {
+		return apppromotion.Definition{}, err
+	}
+	productIDs, err := parseObjectIDs(r.ProductIDs)
+	if err != nil {
+		return apppromotion.Definition{}, err
+	}
+
+	startsAt := time.Now()
+	if r.StartsAt != nil {
+		startsAt = *r.StartsAt
+	}
+
+	return apppromotion.Definition{
+		Name: r.Name,
+		Rule: domainpromotion.Rule{
+			Type:        domainpromotion.Type(r.Type),
+			Percent:     r.Percent,
+			Amount:      r.Amount,
+			BuyQuantity: r.BuyQuantity,
+			GetQuantity: r.GetQuantity,
+		},
+		Scope: domainpromotion.Scope{
+			StoreIDs:   storeIDs,
+			ProductIDs: productIDs,
+			Categories: r.Categories,
+		},
+		StartsAt:  startsAt,
+		EndsAt:    r.EndsAt,
+		Priority:  r.Priority,
+		Stackable: r.Stackable,
+	}, nil
+}
+
+func parseObjectIDs(hexes []string) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, 0, len(hexes))
+	for _, h := range hexes {
+		id, err := primitive.ObjectIDFromHex(h)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	def, err := req.toDefinition()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid store or product ID"))
+		return
+	}
+
+	promotion, err := h.service.CreatePromotion(c.Request.Context(), def)
+	if err != nil {
+		status := promotionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(promotion))
+}
+
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	promotion, err := h.service.GetPromotion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := promotionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(promotion))
+}
+
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	def, err := req.toDefinition()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid store or product ID"))
+		return
+	}
+
+	promotion, err := h.service.UpdatePromotion(c.Request.Context(), c.Param("id"), def)
+	if err != nil {
+		status := promotionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(promotion))
+}
+
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	if err := h.service.DeletePromotion(c.Request.Context(), c.Param("id")); err != nil {
+		status := promotionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse[any](nil))
+}
+
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+
+	promotions, total, err := h.service.ListPromotions(c.Request.Context(), pagination.Page, pagination.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(promotions, pagination, total))
+}
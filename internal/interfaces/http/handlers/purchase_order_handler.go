@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apppurchaseorder "github.com/stasshander/ddd/internal/application/purchaseorder"
+	"github.com/stasshander/ddd/internal/domain/purchaseorder"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/domain/supplier"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PurchaseOrderHandler struct {
+	service *apppurchaseorder.Service
+}
+
+func NewPurchaseOrderHandler(service *apppurchaseorder.Service) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		service: service,
+	}
+}
+
+// purchaseOrderErrorStatus maps purchase order errors to HTTP status codes
+func purchaseOrderErrorStatus(err error) int {
+	for _, notFound := range []error{
+		purchaseorder.ErrPurchaseOrderNotFound,
+		purchaseorder.ErrLineNotFound,
+		store.ErrStoreNotFound,
+		supplier.ErrSupplierNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	for _, conflict := range []error{
+		purchaseorder.ErrNotDraft,
+		purchaseorder.ErrInvalidStatusTransition,
+		purchaseorder.ErrConcurrentUpdate,
+	} {
+		if errors.Is(err, conflict) {
+			return http.StatusConflict
+		}
+	}
+
+	for _, invalid := range []error{
+		purchaseorder.ErrEmptyPurchaseOrder,
+		purchaseorder.ErrInvalidQuantity,
+		purchaseorder.ErrOverReceipt,
+		purchaseorder.ErrInvalidStatus,
+		supplier.ErrProductNotSupplied,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+type PurchaseOrderLineRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required"`
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID string                     `json:"supplier_id" binding:"required"`
+	Lines      []PurchaseOrderLineRequest `json:"lines"`
+}
+
+type PurchaseOrderQuantityRequest struct {
+	Quantity int `json:"quantity" binding:"required"`
+}
+
+type ReceivePurchaseOrderRequest struct {
+	Lines []PurchaseOrderLineRequest `json:"lines" binding:"required"`
+}
+
+func toPurchaseOrderItems(lines []PurchaseOrderLineRequest) []apppurchaseorder.Item {
+	items := make([]apppurchaseorder.Item, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, apppurchaseorder.Item{ProductID: line.ProductID, Quantity: line.Quantity})
+	}
+	return items
+}
+
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	var req CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	po, err := h.service.CreatePurchaseOrder(c.Request.Context(), c.Param("id"), req.SupplierID, toPurchaseOrderItems(req.Lines))
+	if err != nil {
+		status := purchaseOrderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(po))
+}
+
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context) {
+	po, err := h.service.GetPurchaseOrder(c.Request.Context(), c.Param("id"), c.Param("orderId"))
+	if err != nil {
+		status := purchaseOrderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(po))
+}
+
+// ListPurchaseOrders lists a store's purchase orders, optionally filtered by
+// ?status=
+func (h *PurchaseOrderHandler) ListPurchaseOrders(c *gin.Context) {
+	var status purchaseorder.Status
+	if s := c.Query("status"); s != "" {
+		parsed, err := purchaseorder.ParseStatus(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+		status = parsed
+	}
+
+	pagination := paginationFromQuery(c)
+
+	orders, total, err := h.service.ListPurchaseOrders(c.Request.Context(), c.Param("id"), status, pagination.Page, pagination.PageSize)
+	if err != nil {
+		code := purchaseOrderErrorStatus(err)
+		c.JSON(code, response.NewErrorResponse(code, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(orders, pagination, total))
+}
+
+func (h *PurchaseOrderHandler) SetLine(c *gin.Context) {
+	var req PurchaseOrderQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	po, err := h.service.SetLine(c.Request.Context(), c.Param("id"), c.Param("orderId"), c.Param("productId"), req.Quantity)
+	if err != nil {
+		status := purchaseOrderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(po))
+}
+
+func (h *PurchaseOrderHandler) RemoveLine(c *gin.Context) {
+	po, err := h.service.RemoveLine(c.Request.Context(), c.Param("id"), c.Param("orderId"), c.Param("productId"))
+	if err != nil {
+		status := purchaseOrderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(po))
+}
+
+func (h *PurchaseOrderHandler) SendPurchaseOrder(c *gin.Context) {
+	po, err := h.service.Send(c.Request.Context(), c.Param("id"), c.Param("orderId"))
+	if err != nil {
+		status := purchaseOrderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(po))
+}
+
+func (h *PurchaseOrderHandler) ReceivePurchaseOrder(c *gin.Context) {
+	var req ReceivePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	po, err := h.service.Receive(c.Request.Context(), c.Param("id"), c.Param("orderId"), toPurchaseOrderItems(req.Lines))
+	if err != nil {
+		status := purchaseOrderErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(po))
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appregion "github.com/stasshander/ddd/internal/application/region"
+	domainregion "github.com/stasshander/ddd/internal/domain/region"
+	domainstore "github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RegionHandler struct {
+	service *appregion.Service
+}
+
+func NewRegionHandler(service *appregion.Service) *RegionHandler {
+	return &RegionHandler{
+		service: service,
+	}
+}
+
+// regionErrorStatus maps region and store domain errors to HTTP status codes
+func regionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainregion.ErrRegionNotFound), errors.Is(err, domainstore.ErrStoreNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainregion.ErrRegionHasChildren), errors.Is(err, domainregion.ErrRegionHasStores),
+		errors.Is(err, domainregion.ErrRegionChanged):
+		return http.StatusConflict
+	case errors.Is(err, domainregion.ErrInvalidRegionName), errors.Is(err, domainregion.ErrInvalidParent),
+		errors.Is(err, primitive.ErrInvalidHex):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+type CreateRegionRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID string `json:"parent_id"`
+}
+
+func (h *RegionHandler) CreateRegion(c *gin.Context) {
+	var req CreateRegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	region, err := h.service.CreateRegion(c.Request.Context(), req.Name, req.ParentID)
+	if err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(region))
+}
+
+func (h *RegionHandler) GetRegion(c *gin.Context) {
+	region, err := h.service.GetRegion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(region))
+}
+
+func (h *RegionHandler) ListRegions(c *gin.Context) {
+	regions, err := h.service.ListRegions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(regions))
+}
+
+func (h *RegionHandler) RegionTree(c *gin.Context) {
+	tree, err := h.service.RegionTree(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(tree))
+}
+
+type RenameRegionRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (h *RegionHandler) RenameRegion(c *gin.Context) {
+	id := c.Param("id")
+	var req RenameRegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	if err := h.service.RenameRegion(c.Request.Context(), id, req.Name); err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	h.respondWithRegion(c, id)
+}
+
+// MoveRegionRequest re-parents a region; an empty parent_id makes it a root
+type MoveRegionRequest struct {
+	ParentID string `json:"parent_id"`
+}
+
+func (h *RegionHandler) MoveRegion(c *gin.Context) {
+	id := c.Param("id")
+	var req MoveRegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	if err := h.service.MoveRegion(c.Request.Context(), id, req.ParentID); err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	h.respondWithRegion(c, id)
+}
+
+func (h *RegionHandler) DeleteRegion(c *gin.Context) {
+	if err := h.service.DeleteRegion(c.Request.Context(), c.Param("id")); err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse[any](nil))
+}
+
+// ListRegionStores lists the stores in a region and all regions below it
+func (h *RegionHandler) ListRegionStores(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+
+	stores, total, err := h.service.ListRegionStores(c.Request.Context(), c.Param("id"), pagination.Page, pagination.PageSize)
+	if err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(stores, pagination, total))
+}
+
+func (h *RegionHandler) RegionStats(c *gin.Context) {
+	stats, err := h.service.RegionStats(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(stats))
+}
+
+// AssignStoreRegionRequest places a store in a region; an empty region_id
+// removes it from its current region.
+type AssignStoreRegionRequest struct {
+	RegionID string `json:"region_id"`
+}
+
+func (h *RegionHandler) AssignStore(c *gin.Context) {
+	var req AssignStoreRegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	if err := h.service.AssignStore(c.Request.Context(), c.Param("id"), req.RegionID); err != nil {
+		status := regionErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse[any](nil))
+}
+
+func (h *RegionHandler) respondWithRegion(c *gin.Context, id string) {
+	region, err := h.service.GetRegion(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(region))
+}
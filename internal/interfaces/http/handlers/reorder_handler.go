@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appreorder "github.com/stasshander/ddd/internal/application/reorder"
+	"github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReorderHandler struct {
+	service *appreorder.Service
+}
+
+func NewReorderHandler(service *appreorder.Service) *ReorderHandler {
+	return &ReorderHandler{
+		service: service,
+	}
+}
+
+// ReorderSuggestions returns what the store should reorder, grouped by
+// supplier, as of the latest stock scan
+func (h *ReorderHandler) ReorderSuggestions(c *gin.Context) {
+	suggestions, err := h.service.StoreSuggestions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, store.ErrStoreNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, primitive.ErrInvalidHex) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(suggestions))
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appreview "github.com/stasshander/ddd/internal/application/review"
+	"github.com/stasshander/ddd/internal/domain/product"
+	domainreview "github.com/stasshander/ddd/internal/domain/review"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReviewHandler struct {
+	service *appreview.Service
+}
+
+func NewReviewHandler(service *appreview.Service) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+	}
+}
+
+// reviewErrorStatus maps review domain errors to HTTP status codes
+func reviewErrorStatus(err error) int {
+	for _, notFound := range []error{
+		domainreview.ErrReviewNotFound,
+		product.ErrProductNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	for _, conflict := range []error{
+		domainreview.ErrInvalidStatusTransition,
+		domainreview.ErrConcurrentUpdate,
+	} {
+		if errors.Is(err, conflict) {
+			return http.StatusConflict
+		}
+	}
+
+	for _, invalid := range []error{
+		domainreview.ErrInvalidRating,
+		domainreview.ErrInvalidAuthor,
+		domainreview.ErrTextTooLong,
+		domainreview.ErrInvalidStatus,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+type SubmitReviewRequest struct {
+	Author string `json:"author" binding:"required"`
+	Rating int    `json:"rating" binding:"required"`
+	Text   string `json:"text"`
+}
+
+type RejectReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+// reviewStatusFromQuery reads ?status=, falling back to def when it is missing.
+// "all" lists reviews in every state.
+func reviewStatusFromQuery(c *gin.Context, def domainreview.Status) (domainreview.Status, error) {
+	s := c.Query("status")
+	switch s {
+	case "":
+		return def, nil
+	case "all":
+		return "", nil
+	}
+	return domainreview.ParseStatus(s)
+}
+
+func (h *ReviewHandler) SubmitReview(c *gin.Context) {
+	var req SubmitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	review, err := h.service.SubmitReview(c.Request.Context(), c.Param("id"), req.Author, req.Rating, req.Text)
+	if err != nil {
+		status := reviewErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(review))
+}
+
+// ListProductReviews lists a product's approved reviews, newest first.
+// Moderators can pass ?status= to see pending, rejected or all reviews.
+func (h *ReviewHandler) ListProductReviews(c *gin.Context) {
+	status, err := reviewStatusFromQuery(c, domainreview.StatusApproved)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	pagination := paginationFromQuery(c)
+
+	reviews, total, err := h.service.ListProductReviews(c.Request.Context(), c.Param("id"), status, pagination.Page, pagination.PageSize)
+	if err != nil {
+		code := reviewErrorStatus(err)
+		c.JSON(code, response.NewErrorResponse(code, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(reviews, pagination, total))
+}
+
+// ListReviews lists reviews across all products, pending ones by default
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	status, err := reviewStatusFromQuery(c, domainreview.StatusPending)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	pagination := paginationFromQuery(c)
+
+	reviews, total, err := h.service.ListReviews(c.Request.Context(), status, pagination.Page, pagination.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(reviews, pagination, total))
+}
+
+func (h *ReviewHandler) GetReview(c *gin.Context) {
+	review, err := h.service.GetReview(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := reviewErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(review))
+}
+
+func (h *ReviewHandler) ApproveReview(c *gin.Context) {
+	review, err := h.service.ApproveReview(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := reviewErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(review))
+}
+
+func (h *ReviewHandler) RejectReview(c *gin.Context) {
+	var req RejectReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	review, err := h.service.RejectReview(c.Request.Context(), c.Param("id"), req.Reason)
+	if err != nil {
+		status := reviewErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(review))
+}
+
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+	if err := h.service.DeleteReview(c.Request.Context(), c.Param("id")); err != nil {
+		status := reviewErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse[any](nil))
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	appreview "github.com/stasshander/ddd/internal/application/review"
+	"github.com/stasshander/ddd/internal/domain/product"
+	domainreview "github.com/stasshander/ddd/internal/domain/review"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryReviews keeps reviews in a slice, oldest first
+type memoryReviews struct {
+	reviews []*domainreview.Review
+}
+
+func (m *memoryReviews) Create(ctx context.Context, rv *domainreview.Review) error {
+	m.reviews = append(m.reviews, rv)
+	return nil
+}
+
+func (m *memoryReviews) GetByID(ctx context.Context, id string) (*domainreview.Review, error) {
+	for _, rv := range m.reviews {
+		if rv.ID.Hex() == id {
+			stored := *rv
+			return &stored, nil
+		}
+	}
+	return nil, domainreview.ErrReviewNotFound
+}
+
+func (m *memoryReviews) UpdateStatus(ctx context.Context, rv *domainreview.Review, previous domainreview.Status) error {
+	for i, stored := range m.reviews {
+		if stored.ID == rv.ID && stored.Status == previous {
+			m.reviews[i] = rv
+			return nil
+		}
+	}
+	return domainreview.ErrConcurrentUpdate
+}
+
+func (m *memoryReviews) Delete(ctx context.Context, id string) error {
+	for i, rv := range m.reviews {
+		if rv.ID.Hex() == id {
+			m.reviews = append(m.reviews[:i], m.reviews[i+1:]...)
+			return nil
+		}
+	}
+	return domainreview.ErrReviewNotFound
+}
+
+func (m *memoryReviews) List(ctx context.Context, filter domainreview.ListFilter, page, limit int) ([]*domainreview.Review, int, error) {
+	var found []*domainreview.Review
+	for i := len(m.reviews) - 1; i >= 0; i-- {
+		rv := m.reviews[i]
+		if filter.ProductID != nil && rv.ProductID != *filter.ProductID {
+			continue
+		}
+		if filter.Status != "" && rv.Status != filter.Status {
+			continue
+		}
+		found = append(found, rv)
+	}
+	start := min((page-1)*limit, len(found))
+	end := min(start+limit, len(found))
+	return found[start:end], len(found), nil
+}
+
+// summarize aggregates the approved reviews of a product
+func (m *memoryReviews) summarize(productID primitive.ObjectID) product.RatingSummary {
+	total, count := 0, 0
+	for _, rv := range m.reviews {
+		if rv.ProductID == productID && rv.Status == domainreview.StatusApproved {
+			total += rv.Rating
+			count++
+		}
+	}
+	return product.NewRatingSummary(total, count)
+}
+
+// singleProduct serves one product and rates it from reviews
+type singleProduct struct {
+	product.Repository
+	product *product.Product
+	reviews *memoryReviews
+}
+
+func (s *singleProduct) GetByID(ctx context.Context, id string) (*product.Product, error) {
+	if id != s.product.ID.Hex() {
+		return nil, product.ErrProductNotFound
+	}
+	return s.product, nil
+}
+
+func (s *singleProduct) RefreshRating(ctx context.Context, productID primitive.ObjectID) error {
+	s.product.Rating = s.reviews.summarize(productID)
+	return nil
+}
+
+func setupReviewTest(t *testing.T) (*gin.Engine, *appreview.Service, *product.Product) {
+	gin.SetMode(gin.TestMode)
+
+	p, err := product.NewProduct("Coffee", "Dark roast", 12)
+	assert.NoError(t, err)
+	p.ID = primitive.NewObjectID()
+	reviews := &memoryReviews{}
+	products := &singleProduct{product: p, reviews: reviews}
+
+	service := appreview.NewService(reviews, products, products)
+	handler := NewReviewHandler(service)
+
+	router := gin.New()
+	router.GET("/api/products/:id/reviews", handler.ListProductReviews)
+	router.POST("/api/reviews/:id/approve", handler.ApproveReview)
+	router.POST("/api/reviews/:id/reject", handler.RejectReview)
+	return router, service, p
+}
+
+func TestListProductReviewsShowsApprovedOnly(t *testing.T) {
+	router, service, p := setupReviewTest(t)
+	ctx := context.Background()
+
+	approved, err := service.SubmitReview(ctx, p.ID.Hex(), "Sam", 5, "Great")
+	assert.NoError(t, err)
+	_, err = service.ApproveReview(ctx, approved.ID.Hex())
+	assert.NoError(t, err)
+	rejected, err := service.SubmitReview(ctx, p.ID.Hex(), "Alex", 1, "Spam")
+	assert.NoError(t, err)
+	_, err = service.RejectReview(ctx, rejected.ID.Hex(), "spam")
+	assert.NoError(t, err)
+	pending, err := service.SubmitReview(ctx, p.ID.Hex(), "Kim", 3, "")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []primitive.ObjectID
+	}{
+		{name: "public listing", query: "", wantStatus: http.StatusOK, wantIDs: []primitive.ObjectID{approved.ID}},
+		{name: "pending", query: "?status=pending", wantStatus: http.StatusOK, wantIDs: []primitive.ObjectID{pending.ID}},
+		{name: "all", query: "?status=all", wantStatus: http.StatusOK, wantIDs: []primitive.ObjectID{pending.ID, rejected.ID, approved.ID}},
+		{name: "unknown status", query: "?status=hidden", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/products/"+p.ID.Hex()+"/reviews"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data []domainreview.Review `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			ids := make([]primitive.ObjectID, 0, len(body.Data))
+			for _, rv := range body.Data {
+				ids = append(ids, rv.ID)
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
+
+func TestModerateReview(t *testing.T) {
+	testCases := []struct {
+		name       string
+		paths      []string
+		wantStatus int
+	}{
+		{name: "approve", paths: []string{"/approve"}, wantStatus: http.StatusOK},
+		{name: "reject", paths: []string{"/reject"}, wantStatus: http.StatusOK},
+		{name: "approve twice", paths: []string{"/approve", "/approve"}, wantStatus: http.StatusConflict},
+		{name: "reject after approval", paths: []string{"/approve", "/reject"}, wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, service, p := setupReviewTest(t)
+			rv, err := service.SubmitReview(context.Background(), p.ID.Hex(), "Sam", 4, "")
+			assert.NoError(t, err)
+
+			var w *httptest.ResponseRecorder
+			for _, path := range tc.paths {
+				w = httptest.NewRecorder()
+				req, _ := http.NewRequest("POST", "/api/reviews/"+rv.ID.Hex()+path, nil)
+				router.ServeHTTP(w, req)
+			}
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+
+	t.Run("unknown review", func(t *testing.T) {
+		router, _, _ := setupReviewTest(t)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/reviews/"+primitive.NewObjectID().Hex()+"/approve", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestReviewErrorStatusConflicts(t *testing.T) {
+	assert.Equal(t, http.StatusConflict, reviewErrorStatus(domainreview.ErrConcurrentUpdate))
+	assert.Equal(t, http.StatusConflict, reviewErrorStatus(domainreview.ErrInvalidStatusTransition))
+}
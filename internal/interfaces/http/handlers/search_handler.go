@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stasshander/ddd/internal/application/product"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+)
+
+type SearchHandler struct {
+	service *product.SearchService
+	catalog *product.FacetedSearchService
+}
+
+// NewSearchHandler creates a search handler. catalog may be nil when the
+// in-process search index is disabled, in which case Search must not be routed.
+func NewSearchHandler(service *product.SearchService, catalog *product.FacetedSearchService) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+		catalog: catalog,
+	}
+}
+
+type searchResponse struct {
+	*response.PaginatedResponse[*domainproduct.SearchHit]
+	Facets *domainproduct.SearchFacets `json:"facets"`
+}
+
+func (h *SearchHandler) SearchProducts(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+
+	hits, total, err := h.service.SearchProducts(c.Request.Context(), c.Query("q"), pagination.Page, pagination.PageSize)
+	if err != nil {
+		if err == domainproduct.ErrEmptySearchQuery {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(hits, pagination, total))
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+
+	opts := domainproduct.SearchOptions{
+		Query:     c.Query("q"),
+		Category:  c.Query("category"),
+		PriceBand: c.Query("price_band"),
+		Fuzzy:     c.DefaultQuery("fuzzy", "true") != "false",
+		Page:      pagination.Page,
+		Limit:     pagination.PageSize,
+	}
+
+	hits, total, facets, err := h.catalog.Search(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, searchResponse{
+		PaginatedResponse: response.NewPaginatedResponse(hits, pagination, total),
+		Facets:            facets,
+	})
+}
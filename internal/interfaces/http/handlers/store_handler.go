@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	appstore "github.com/stasshander/ddd/internal/application/store"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	domainregion "github.com/stasshander/ddd/internal/domain/region"
 	domainstore "github.com/stasshander/ddd/internal/domain/store"
 	"github.com/stasshander/ddd/internal/interfaces/http/response"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -20,9 +27,90 @@ func NewStoreHandler(service *appstore.Service) *StoreHandler {
 	}
 }
 
+// AddressInput accepts either a legacy free-text address string or a
+// structured postal address object.
+type AddressInput struct {
+	Text   string
+	Postal *PostalAddressRequest
+}
+
+type PostalAddressRequest struct {
+	Lines      []string `json:"lines"`
+	City       string   `json:"city"`
+	Region     string   `json:"region"`
+	PostalCode string   `json:"postal_code"`
+	Country    string   `json:"country"`
+}
+
+func (a *AddressInput) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &a.Text)
+	}
+	a.Postal = &PostalAddressRequest{}
+	return json.Unmarshal(data, a.Postal)
+}
+
+// toDomain returns the structured address, or nil for a legacy string
+func (a *AddressInput) toDomain() (*domainstore.Address, error) {
+	if a.Postal == nil {
+		return nil, nil
+	}
+	return domainstore.NewAddress(a.Postal.Lines, a.Postal.City, a.Postal.Region, a.Postal.PostalCode, a.Postal.Country)
+}
+
+// storeErrorStatus maps store domain errors to HTTP status codes
+func storeErrorStatus(err error) int {
+	for _, notFound := range []error{
+		domainstore.ErrStoreNotFound,
+		domainregion.ErrRegionNotFound,
+		domainstore.ErrOpeningHoursNotSet,
+		domainstore.ErrExceptionNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	for _, conflict := range []error{
+		domainstore.ErrProductAlreadyExists,
+		domainstore.ErrInvalidStatusTransition,
+		domainstore.ErrStoreClosed,
+		domainstore.ErrStatusChanged,
+	} {
+		if errors.Is(err, conflict) {
+			return http.StatusConflict
+		}
+	}
+
+	for _, invalid := range []error{
+		domainstore.ErrInvalidStoreName,
+		domainstore.ErrInvalidStatus,
+		domainstore.ErrInvalidStoreAddress,
+		domainstore.ErrInvalidAddressLines,
+		domainstore.ErrInvalidCity,
+		domainstore.ErrInvalidCountry,
+		domainstore.ErrInvalidPostalCode,
+		domainstore.ErrInvalidLocation,
+		domainstore.ErrInvalidTimeZone,
+		domainstore.ErrInvalidWeekday,
+		domainstore.ErrInvalidTimeOfDay,
+		domainstore.ErrInvalidTimeRange,
+		domainstore.ErrOverlappingRanges,
+		domainstore.ErrInvalidExceptionDate,
+		domainstore.ErrInvalidException,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
 type CreateStoreRequest struct {
-	Name    string `json:"name" binding:"required"`
-	Address string `json:"address" binding:"required"`
+	Name    string        `json:"name" binding:"required"`
+	Address *AddressInput `json:"address" binding:"required"`
 }
 
 func (h *StoreHandler) CreateStore(c *gin.Context) {
@@ -32,17 +120,55 @@ func (h *StoreHandler) CreateStore(c *gin.Context) {
 		return
 	}
 
-	store, err := h.service.CreateStore(c.Request.Context(), req.Name, req.Address)
+	address, err := req.Address.toDomain()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	var store *domainstore.Store
+	if address != nil {
+		store, err = h.service.CreateStoreWithAddress(c.Request.Context(), req.Name, address)
+	} else {
+		store, err = h.service.CreateStore(c.Request.Context(), req.Name, req.Address.Text)
+	}
+	if err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusCreated, response.NewSimpleResponse(store))
 }
 
+// ExpandedStoreResponse is a store with a page of its products embedded
+type ExpandedStoreResponse struct {
+	*domainstore.Store
+	ProductDetails *ExpandedProducts `json:"product_details"`
+}
+
+// ExpandedProducts is a page of embedded products. Items hold only the
+// selected fields when a field selection was given.
+type ExpandedProducts struct {
+	Items    []interface{}      `json:"items"`
+	PageInfo *response.PageInfo `json:"page_info"`
+}
+
+// GetStore returns a store. With expand=products it also embeds a page of the
+// store's products, ordered by name, paginated with page and limit and
+// narrowed to the comma separated fields if given.
 func (h *StoreHandler) GetStore(c *gin.Context) {
 	id := c.Param("id")
+	switch c.Query("expand") {
+	case "":
+	case "products":
+		h.getStoreWithProducts(c, id)
+		return
+	default:
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "expand must be products"))
+		return
+	}
+
 	store, err := h.service.GetStore(c.Request.Context(), id)
 	if err != nil {
 		if err == domainstore.ErrStoreNotFound {
@@ -56,6 +182,77 @@ func (h *StoreHandler) GetStore(c *gin.Context) {
 	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
 }
 
+func (h *StoreHandler) getStoreWithProducts(c *gin.Context, id string) {
+	fields, err := domainproduct.ParseFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	pagination := paginationFromQuery(c)
+	expansion := domainstore.ProductExpansion{
+		Fields: fields,
+		Page:   pagination.Page,
+		Limit:  pagination.PageSize,
+	}
+
+	store, page, err := h.service.GetStoreWithProducts(c.Request.Context(), id, expansion)
+	if err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	localized, _ := localize(c, page.Products)
+	items := make([]interface{}, 0, len(localized))
+	for _, p := range localized {
+		item, err := selectFields(p, fields)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		items = append(items, item)
+	}
+
+	c.Header("Vary", "Accept-Language")
+	c.JSON(http.StatusOK, response.NewSimpleResponse(&ExpandedStoreResponse{
+		Store: store,
+		ProductDetails: &ExpandedProducts{
+			Items: items,
+			PageInfo: &response.PageInfo{
+				Page:       pagination.Page,
+				PageSize:   pagination.PageSize,
+				TotalCount: page.Total,
+			},
+		},
+	}))
+}
+
+// selectFields narrows a product to the selected JSON fields, or returns it
+// unchanged if no fields were selected
+func selectFields(p *domainproduct.Product, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return p, nil
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
+}
+
 type UpdateStoreNameRequest struct {
 	Name string `json:"name" binding:"required"`
 }
@@ -86,8 +283,10 @@ func (h *StoreHandler) UpdateStoreName(c *gin.Context) {
 	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
 }
 
+// UpdateStoreAddressRequest accepts the address either as a structured
+// object or, for older clients, as a free-text string.
 type UpdateStoreAddressRequest struct {
-	Address string `json:"address" binding:"required"`
+	Address *AddressInput `json:"address" binding:"required"`
 }
 
 func (h *StoreHandler) UpdateStoreAddress(c *gin.Context) {
@@ -98,12 +297,196 @@ func (h *StoreHandler) UpdateStoreAddress(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UpdateStoreAddress(c.Request.Context(), id, req.Address); err != nil {
-		if err == domainstore.ErrStoreNotFound {
-			c.JSON(http.StatusNotFound, response.NewErrorResponse(http.StatusNotFound, err.Error()))
+	address, err := req.Address.toDomain()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if address != nil {
+		err = h.service.UpdateStorePostalAddress(c.Request.Context(), id, address)
+	} else {
+		err = h.service.UpdateStoreAddress(c.Request.Context(), id, req.Address.Text)
+	}
+	if err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	store, err := h.service.GetStore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
+}
+
+type UpdateStoreLocationRequest struct {
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+}
+
+func (h *StoreHandler) UpdateStoreLocation(c *gin.Context) {
+	id := c.Param("id")
+	var req UpdateStoreLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	if err := h.service.UpdateStoreLocation(c.Request.Context(), id, *req.Latitude, *req.Longitude); err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	store, err := h.service.GetStore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
+}
+
+type UpdateOpeningHoursRequest struct {
+	TimeZone string                             `json:"time_zone" binding:"required"`
+	Weekly   map[string][]domainstore.TimeRange `json:"weekly" binding:"required"`
+}
+
+func (h *StoreHandler) UpdateOpeningHours(c *gin.Context) {
+	id := c.Param("id")
+	var req UpdateOpeningHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	hours, err := domainstore.NewOpeningHours(req.TimeZone, req.Weekly)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.UpdateOpeningHours(c.Request.Context(), id, hours); err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	store, err := h.service.GetStore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
+}
+
+type SetOpeningExceptionRequest struct {
+	Closed bool                    `json:"closed"`
+	Ranges []domainstore.TimeRange `json:"ranges"`
+	Note   string                  `json:"note"`
+}
+
+func (h *StoreHandler) SetOpeningException(c *gin.Context) {
+	id := c.Param("id")
+	var req SetOpeningExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	day, err := domainstore.NewExceptionDay(c.Param("date"), req.Closed, req.Ranges, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.service.SetOpeningException(c.Request.Context(), id, day); err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	store, err := h.service.GetStore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
+}
+
+func (h *StoreHandler) RemoveOpeningException(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.service.RemoveOpeningException(c.Request.Context(), id, c.Param("date")); err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	store, err := h.service.GetStore(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
+}
+
+type StoreOpenResponse struct {
+	StoreID string    `json:"store_id"`
+	At      time.Time `json:"at"`
+	Open    bool      `json:"open"`
+}
+
+func (h *StoreHandler) IsStoreOpen(c *gin.Context) {
+	id := c.Param("id")
+
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "at must be an RFC 3339 timestamp"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		at = parsed
+	}
+
+	open, err := h.service.IsStoreOpen(c.Request.Context(), id, at)
+	if err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(StoreOpenResponse{
+		StoreID: id,
+		At:      at,
+		Open:    open,
+	}))
+}
+
+func (h *StoreHandler) OpenStore(c *gin.Context) {
+	h.changeStatus(c, h.service.OpenStore)
+}
+
+func (h *StoreHandler) SuspendStore(c *gin.Context) {
+	h.changeStatus(c, h.service.SuspendStore)
+}
+
+func (h *StoreHandler) CloseStore(c *gin.Context) {
+	h.changeStatus(c, h.service.CloseStore)
+}
+
+func (h *StoreHandler) changeStatus(c *gin.Context, transition func(ctx context.Context, id string) error) {
+	id := c.Param("id")
+	if err := transition(c.Request.Context(), id); err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
 		return
 	}
 
@@ -130,19 +513,40 @@ func (h *StoreHandler) DeleteStore(c *gin.Context) {
 	c.JSON(http.StatusOK, response.NewSimpleResponse[any](nil))
 }
 
-func (h *StoreHandler) ListStores(c *gin.Context) {
-	page := 1
-	limit := 10
+// storeFilterFromQuery reads the country, city, status and open_now store
+// listing filters
+func storeFilterFromQuery(c *gin.Context) (domainstore.ListFilter, error) {
+	filter := domainstore.ListFilter{
+		Country: c.Query("country"),
+		City:    c.Query("city"),
+	}
+	if raw := c.Query("status"); raw != "" {
+		status, err := domainstore.ParseStatus(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.Status = status
+	}
+	if c.Query("open_now") == "true" {
+		now := time.Now()
+		filter.OpenAt = &now
+	}
+	return filter, nil
+}
 
-	stores, total, err := h.service.ListStores(c.Request.Context(), page, limit)
+func (h *StoreHandler) ListStores(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+	filter, err := storeFilterFromQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
 		return
 	}
 
-	pagination := &response.Pagination{
-		Page:     page,
-		PageSize: limit,
+	stores, total, err := h.service.ListStores(c.Request.Context(), filter, c.Query("region_id"), pagination.Page, pagination.PageSize)
+	if err != nil {
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
 	}
 
 	c.JSON(http.StatusOK, response.NewPaginatedResponse(stores, pagination, total))
@@ -167,11 +571,8 @@ func (h *StoreHandler) AddProductToStore(c *gin.Context) {
 	}
 
 	if err := h.service.AddProductToStore(c.Request.Context(), storeID, productID); err != nil {
-		if err == domainstore.ErrStoreNotFound {
-			c.JSON(http.StatusNotFound, response.NewErrorResponse(http.StatusNotFound, err.Error()))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		status := storeErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
 		return
 	}
 
@@ -211,3 +612,39 @@ func (h *StoreHandler) RemoveProductFromStore(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.NewSimpleResponse(store))
 }
+
+func (h *StoreHandler) NearbyStores(c *gin.Context) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+	radius, errRadius := strconv.ParseFloat(c.Query("radius_km"), 64)
+	if errLat != nil || errLng != nil || errRadius != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "lat, lng and radius_km are required numbers"))
+		return
+	}
+
+	var productID *primitive.ObjectID
+	if raw := c.Query("product_id"); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid product ID"))
+			return
+		}
+		productID = &id
+	}
+
+	query, err := domainstore.NewNearbyQuery(lat, lng, radius, productID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	pagination := paginationFromQuery(c)
+
+	stores, total, err := h.service.FindNearbyStores(c.Request.Context(), query, pagination.Page, pagination.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(stores, pagination, total))
+}
@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	appregion "github.com/stasshander/ddd/internal/application/region"
+	appstore "github.com/stasshander/ddd/internal/application/store"
+	"github.com/stasshander/ddd/internal/domain/product"
+	domainregion "github.com/stasshander/ddd/internal/domain/region"
+	domainstore "github.com/stasshander/ddd/internal/domain/store"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// expandableStore serves one store and resolves its products from a map,
+// ordered by name like the MongoDB repository
+type expandableStore struct {
+	domainstore.Repository
+	store    *domainstore.Store
+	products map[primitive.ObjectID]*product.Product
+}
+
+func (e *expandableStore) GetByID(ctx context.Context, id string) (*domainstore.Store, error) {
+	if id != e.store.ID.Hex() {
+		return nil, domainstore.ErrStoreNotFound
+	}
+	return e.store, nil
+}
+
+func (e *expandableStore) GetWithProducts(ctx context.Context, id string, expansion domainstore.ProductExpansion) (*domainstore.Store, *domainstore.ProductPage, error) {
+	st, err := e.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var carried []*product.Product
+	for _, productID := range st.Products {
+		if p, ok := e.products[productID]; ok {
+			carried = append(carried, p)
+		}
+	}
+	sort.Slice(carried, func(i, j int) bool { return carried[i].Name < carried[j].Name })
+
+	start := min((expansion.Page-1)*expansion.Limit, len(carried))
+	end := min(start+expansion.Limit, len(carried))
+	return st, &domainstore.ProductPage{Products: carried[start:end], Total: len(carried)}, nil
+}
+
+func setupStoreTest(t *testing.T) (*gin.Engine, *domainstore.Store) {
+	gin.SetMode(gin.TestMode)
+
+	st, err := domainstore.NewStore("Main Street", "1 Main Street")
+	assert.NoError(t, err)
+	st.ID = primitive.NewObjectID()
+
+	repo := &expandableStore{store: st, products: make(map[primitive.ObjectID]*product.Product)}
+	for _, name := range []string{"Tea", "Coffee", "Milk"} {
+		p, err := product.NewProduct(name, name+" description", 3)
+		assert.NoError(t, err)
+		p.ID = primitive.NewObjectID()
+		repo.products[p.ID] = p
+		st.Products = append(st.Products, p.ID)
+	}
+	// A product that was deleted after the store started carrying it
+	st.Products = append(st.Products, primitive.NewObjectID())
+
+	handler := NewStoreHandler(appstore.NewService(repo, nil))
+	router := gin.New()
+	router.GET("/api/stores/:id", handler.GetStore)
+	return router, st
+}
+
+func TestGetStoreExpandProducts(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantNames  []string
+		wantTotal  int
+		wantFields []string
+	}{
+		{name: "first page", query: "?expand=products&limit=2", wantStatus: http.StatusOK, wantNames: []string{"Coffee", "Milk"}, wantTotal: 3},
+		{name: "second page", query: "?expand=products&limit=2&page=2", wantStatus: http.StatusOK, wantNames: []string{"Tea"}, wantTotal: 3},
+		{name: "past the end", query: "?expand=products&limit=2&page=3", wantStatus: http.StatusOK, wantNames: []string{}, wantTotal: 3},
+		{name: "selected fields", query: "?expand=products&fields=name", wantStatus: http.StatusOK, wantNames: []string{"Coffee", "Milk", "Tea"}, wantTotal: 3, wantFields: []string{"name"}},
+		{name: "unknown field", query: "?expand=products&fields=cost", wantStatus: http.StatusBadRequest},
+		{name: "unknown expand", query: "?expand=suppliers", wantStatus: http.StatusBadRequest},
+		{name: "no expand", query: "", wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, st := setupStoreTest(t)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/stores/"+st.ID.Hex()+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data struct {
+					ID             primitive.ObjectID `json:"id"`
+					ProductDetails *struct {
+						Items    []map[string]interface{} `json:"items"`
+						PageInfo struct {
+							TotalCount int `json:"total_count"`
+						} `json:"page_info"`
+					} `json:"product_details"`
+				} `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, st.ID, body.Data.ID)
+
+			if tc.wantNames == nil {
+				assert.Nil(t, body.Data.ProductDetails)
+				return
+			}
+			if !assert.NotNil(t, body.Data.ProductDetails) {
+				return
+			}
+			names := make([]string, 0, len(body.Data.ProductDetails.Items))
+			for _, item := range body.Data.ProductDetails.Items {
+				names = append(names, item["name"].(string))
+				if tc.wantFields != nil {
+					keys := make([]string, 0, len(item))
+					for key := range item {
+						keys = append(keys, key)
+					}
+					assert.ElementsMatch(t, tc.wantFields, keys)
+				}
+			}
+			assert.Equal(t, tc.wantNames, names)
+			assert.Equal(t, tc.wantTotal, body.Data.ProductDetails.PageInfo.TotalCount)
+		})
+	}
+}
+
+func TestGetStoreExpandProductsNotFound(t *testing.T) {
+	router, _ := setupStoreTest(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/stores/"+primitive.NewObjectID().Hex()+"?expand=products", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// listedStores keeps stores oldest first and filters them by region and, for
+// open_now listings, to open stores with hours, like the MongoDB repository
+type listedStores struct {
+	domainstore.Repository
+	stores []*domainstore.Store
+}
+
+func (l *listedStores) matching(filter domainstore.ListFilter) []*domainstore.Store {
+	var matched []*domainstore.Store
+	for _, st := range l.stores {
+		if filter.RegionIDs != nil && (st.RegionID == nil || !slices.Contains(filter.RegionIDs, *st.RegionID)) {
+			continue
+		}
+		if filter.OpenAt != nil && (st.CurrentStatus() != domainstore.StatusOpen || st.OpeningHours == nil) {
+			continue
+		}
+		matched = append(matched, st)
+	}
+	return matched
+}
+
+func (l *listedStores) List(ctx context.Context, filter domainstore.ListFilter, page, limit int) ([]*domainstore.Store, int, error) {
+	matched := l.matching(filter)
+	slices.Reverse(matched)
+	start := min((page-1)*limit, len(matched))
+	end := min(start+limit, len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+func (l *listedStores) Each(ctx context.Context, filter domainstore.ListFilter, fn func(*domainstore.Store) error) error {
+	for _, st := range l.matching(filter) {
+		if err := fn(st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// treeRegions serves a fixed region hierarchy
+type treeRegions struct {
+	domainregion.Repository
+	regions []*domainregion.Region
+}
+
+func (t *treeRegions) GetByID(ctx context.Context, id string) (*domainregion.Region, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+	for _, r := range t.regions {
+		if r.ID.Hex() == id {
+			return r, nil
+		}
+	}
+	return nil, domainregion.ErrRegionNotFound
+}
+
+func (t *treeRegions) ListDescendants(ctx context.Context, id string) ([]*domainregion.Region, error) {
+	var descendants []*domainregion.Region
+	for _, r := range t.regions {
+		if slices.ContainsFunc(r.Ancestors, func(a primitive.ObjectID) bool { return a.Hex() == id }) {
+			descendants = append(descendants, r)
+		}
+	}
+	return descendants, nil
+}
+
+func setupStoreListTest(t *testing.T) (*gin.Engine, *domainregion.Region, *domainregion.Region) {
+	gin.SetMode(gin.TestMode)
+
+	north, err := domainregion.NewRegion("North", nil)
+	assert.NoError(t, err)
+	district, err := domainregion.NewRegion("North District", north)
+	assert.NoError(t, err)
+	south, err := domainregion.NewRegion("South", nil)
+	assert.NoError(t, err)
+
+	allWeek := make(map[string][]domainstore.TimeRange)
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		allWeek[day] = []domainstore.TimeRange{{Open: "00:00", Close: "24:00"}}
+	}
+	alwaysOpen, err := domainstore.NewOpeningHours("UTC", allWeek)
+	assert.NoError(t, err)
+	neverOpen, err := domainstore.NewOpeningHours("UTC", map[string][]domainstore.TimeRange{})
+	assert.NoError(t, err)
+
+	// Stores 1 to 5, oldest first: even stores never open, store 5 is
+	// suspended, and stores 1 and 2 are in the north district
+	repo := &listedStores{}
+	for i := 1; i <= 5; i++ {
+		st, err := domainstore.NewStore(fmt.Sprintf("Store %d", i), "1 Main Street")
+		assert.NoError(t, err)
+		assert.NoError(t, st.Open())
+		assert.NoError(t, st.UpdateOpeningHours(alwaysOpen))
+		if i%2 == 0 {
+			assert.NoError(t, st.UpdateOpeningHours(neverOpen))
+		}
+		if i == 5 {
+			assert.NoError(t, st.Suspend())
+		}
+		switch {
+		case i <= 2:
+			st.AssignRegion(&district.ID)
+		case i == 3:
+			st.AssignRegion(&south.ID)
+		}
+		repo.stores = append(repo.stores, st)
+	}
+
+	regions := &treeRegions{regions: []*domainregion.Region{north, district, south}}
+	handler := NewStoreHandler(appstore.NewService(repo, appregion.NewService(regions, repo)))
+	router := gin.New()
+	router.GET("/api/stores", handler.ListStores)
+	return router, north, south
+}
+
+func TestListStores(t *testing.T) {
+	router, north, south := setupStoreListTest(t)
+
+	testCases := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantNames  []string
+		wantTotal  int
+	}{
+		{name: "all", query: "?limit=2", wantStatus: http.StatusOK, wantNames: []string{"Store 5", "Store 4"}, wantTotal: 5},
+		{name: "open now", query: "?open_now=true", wantStatus: http.StatusOK, wantNames: []string{"Store 3", "Store 1"}, wantTotal: 2},
+		{name: "open now second page", query: "?open_now=true&limit=1&page=2", wantStatus: http.StatusOK, wantNames: []string{"Store 1"}, wantTotal: 2},
+		{name: "open now past the end", query: "?open_now=true&limit=1&page=3", wantStatus: http.StatusOK, wantNames: []string{}, wantTotal: 2},
+		{name: "region subtree", query: "?region_id=" + north.ID.Hex(), wantStatus: http.StatusOK, wantNames: []string{"Store 2", "Store 1"}, wantTotal: 2},
+		{name: "region open now", query: "?open_now=true&region_id=" + south.ID.Hex(), wantStatus: http.StatusOK, wantNames: []string{"Store 3"}, wantTotal: 1},
+		{name: "open now with another status", query: "?open_now=true&status=closed", wantStatus: http.StatusOK, wantNames: []string{}, wantTotal: 0},
+		{name: "unknown region", query: "?region_id=" + primitive.NewObjectID().Hex(), wantStatus: http.StatusNotFound},
+		{name: "malformed region", query: "?region_id=north", wantStatus: http.StatusBadRequest},
+		{name: "unknown status", query: "?status=busy", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/stores"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data     []domainstore.Store `json:"data"`
+				PageInfo struct {
+					TotalCount int `json:"total_count"`
+				} `json:"page_info"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			names := make([]string, 0, len(body.Data))
+			for _, st := range body.Data {
+				names = append(names, st.Name)
+			}
+			assert.Equal(t, tc.wantNames, names)
+			assert.Equal(t, tc.wantTotal, body.PageInfo.TotalCount)
+		})
+	}
+}
+
+// savedStores keeps stored copies of stores and applies the store commands
+// the way the MongoDB repository does, each to its own fields
+type savedStores struct {
+	domainstore.Repository
+	stores map[string]domainstore.Store
+	// beforeStatus runs before each status update
+	beforeStatus func()
+}
+
+func (s *savedStores) GetByID(ctx context.Context, id string) (*domainstore.Store, error) {
+	st, ok := s.stores[id]
+	if !ok {
+		return nil, domainstore.ErrStoreNotFound
+	}
+	return &st, nil
+}
+
+func (s *savedStores) Update(ctx context.Context, st *domainstore.Store) error {
+	stored, ok := s.stores[st.ID.Hex()]
+	if !ok {
+		return domainstore.ErrStoreNotFound
+	}
+	stored.Name = st.Name
+	stored.Address = st.Address
+	stored.PostalAddress = st.PostalAddress
+	stored.Location = st.Location
+	stored.OpeningHours = st.OpeningHours
+	s.stores[st.ID.Hex()] = stored
+	return nil
+}
+
+func (s *savedStores) UpdateStatus(ctx context.Context, id string, from, to domainstore.Status) error {
+	if s.beforeStatus != nil {
+		s.beforeStatus()
+	}
+	stored, ok := s.stores[id]
+	if !ok {
+		return domainstore.ErrStoreNotFound
+	}
+	if stored.CurrentStatus() != from {
+		return domainstore.ErrStatusChanged
+	}
+	stored.Status = to
+	s.stores[id] = stored
+	return nil
+}
+
+func TestStoreStatusCommands(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// A store persisted before the status lifecycle has no status
+	legacy, err := domainstore.NewStore("Main Street", "1 Main Street")
+	assert.NoError(t, err)
+	legacy.Status = ""
+	id := legacy.ID.Hex()
+
+	testCases := []struct {
+		name         string
+		requests     []string
+		beforeStatus func(repo *savedStores)
+		wantStatuses []int
+		wantStatus   domainstore.Status
+		wantName     string
+	}{
+		{
+			name:         "rename keeps a legacy store open",
+			requests:     []string{"PUT /name"},
+			wantStatuses: []int{http.StatusOK},
+			wantStatus:   "",
+			wantName:     "High Street",
+		},
+		{
+			name:         "suspend a legacy store",
+			requests:     []string{"POST /suspend"},
+			wantStatuses: []int{http.StatusOK},
+			wantStatus:   domainstore.StatusTemporarilyClosed,
+			wantName:     "Main Street",
+		},
+		{
+			name:         "suspend then rename",
+			requests:     []string{"POST /suspend", "PUT /name"},
+			wantStatuses: []int{http.StatusOK, http.StatusOK},
+			wantStatus:   domainstore.StatusTemporarilyClosed,
+			wantName:     "High Street",
+		},
+		{
+			name:     "closed while suspending",
+			requests: []string{"POST /suspend"},
+			beforeStatus: func(repo *savedStores) {
+				stored := repo.stores[id]
+				stored.Status = domainstore.StatusClosed
+				repo.stores[id] = stored
+			},
+			wantStatuses: []int{http.StatusConflict},
+			wantStatus:   domainstore.StatusClosed,
+			wantName:     "Main Street",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &savedStores{stores: map[string]domainstore.Store{id: *legacy}}
+			if tc.beforeStatus != nil {
+				repo.beforeStatus = func() { tc.beforeStatus(repo) }
+			}
+			handler := NewStoreHandler(appstore.NewService(repo, nil))
+			router := gin.New()
+			router.PUT("/api/stores/:id/name", handler.UpdateStoreName)
+			router.POST("/api/stores/:id/suspend", handler.SuspendStore)
+
+			for i, request := range tc.requests {
+				method, path, _ := strings.Cut(request, " ")
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest(method, "/api/stores/"+id+path, strings.NewReader(`{"name":"High Street"}`))
+				req.Header.Set("Content-Type", "application/json")
+				router.ServeHTTP(w, req)
+				assert.Equal(t, tc.wantStatuses[i], w.Code, request)
+			}
+
+			stored := repo.stores[id]
+			assert.Equal(t, tc.wantStatus, stored.Status)
+			assert.Equal(t, tc.wantName, stored.Name)
+		})
+	}
+}
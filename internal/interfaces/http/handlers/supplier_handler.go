@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appsupplier "github.com/stasshander/ddd/internal/application/supplier"
+	"github.com/stasshander/ddd/internal/domain/product"
+	domainsupplier "github.com/stasshander/ddd/internal/domain/supplier"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SupplierHandler struct {
+	service *appsupplier.Service
+}
+
+func NewSupplierHandler(service *appsupplier.Service) *SupplierHandler {
+	return &SupplierHandler{
+		service: service,
+	}
+}
+
+// supplierErrorStatus maps supplier domain errors to HTTP status codes
+func supplierErrorStatus(err error) int {
+	for _, notFound := range []error{
+		domainsupplier.ErrSupplierNotFound,
+		domainsupplier.ErrProductNotSupplied,
+		product.ErrProductNotFound,
+	} {
+		if errors.Is(err, notFound) {
+			return http.StatusNotFound
+		}
+	}
+
+	for _, invalid := range []error{
+		domainsupplier.ErrInvalidName,
+		domainsupplier.ErrInvalidEmail,
+		domainsupplier.ErrInvalidLeadTime,
+		domainsupplier.ErrInvalidCostPrice,
+		primitive.ErrInvalidHex,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+type SupplierRequest struct {
+	Name         string                 `json:"name" binding:"required"`
+	Contact      domainsupplier.Contact `json:"contact"`
+	LeadTimeDays int                    `json:"lead_time_days"`
+}
+
+func (r *SupplierRequest) toDetails() appsupplier.Details {
+	return appsupplier.Details{
+		Name:         r.Name,
+		Contact:      r.Contact,
+		LeadTimeDays: r.LeadTimeDays,
+	}
+}
+
+type SupplierProductRequest struct {
+	CostPrice float64 `json:"cost_price" binding:"required"`
+}
+
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var req SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	supplier, err := h.service.CreateSupplier(c.Request.Context(), req.toDetails())
+	if err != nil {
+		status := supplierErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(supplier))
+}
+
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	supplier, err := h.service.GetSupplier(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := supplierErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(supplier))
+}
+
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	var req SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	supplier, err := h.service.UpdateSupplier(c.Request.Context(), c.Param("id"), req.toDetails())
+	if err != nil {
+		status := supplierErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(supplier))
+}
+
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	if err := h.service.DeleteSupplier(c.Request.Context(), c.Param("id")); err != nil {
+		status := supplierErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse[any](nil))
+}
+
+func (h *SupplierHandler) ListSuppliers(c *gin.Context) {
+	pagination := paginationFromQuery(c)
+
+	suppliers, total, err := h.service.ListSuppliers(c.Request.Context(), pagination.Page, pagination.PageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewPaginatedResponse(suppliers, pagination, total))
+}
+
+func (h *SupplierHandler) SetProduct(c *gin.Context) {
+	var req SupplierProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	supplier, err := h.service.SetProduct(c.Request.Context(), c.Param("id"), c.Param("productId"), req.CostPrice)
+	if err != nil {
+		status := supplierErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(supplier))
+}
+
+func (h *SupplierHandler) RemoveProduct(c *gin.Context) {
+	supplier, err := h.service.RemoveProduct(c.Request.Context(), c.Param("id"), c.Param("productId"))
+	if err != nil {
+		status := supplierErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(supplier))
+}
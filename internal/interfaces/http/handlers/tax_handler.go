@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apptax "github.com/stasshander/ddd/internal/application/tax"
+	domainproduct "github.com/stasshander/ddd/internal/domain/product"
+	domaintax "github.com/stasshander/ddd/internal/domain/tax"
+	"github.com/stasshander/ddd/internal/interfaces/http/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TaxHandler struct {
+	service *apptax.Service
+}
+
+func NewTaxHandler(service *apptax.Service) *TaxHandler {
+	return &TaxHandler{
+		service: service,
+	}
+}
+
+// taxErrorStatus maps tax domain errors to HTTP status codes
+func taxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domaintax.ErrRateTableNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domaintax.ErrDuplicateJurisdiction):
+		return http.StatusConflict
+	case errors.Is(err, domaintax.ErrInvalidCountry), errors.Is(err, domaintax.ErrInvalidRate),
+		errors.Is(err, domainproduct.ErrInvalidTaxClass), errors.Is(err, primitive.ErrInvalidHex):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateRateTableRequest sets up the tax rates of a country, or of a region
+// within it. rates maps tax classes to percentages.
+type CreateRateTableRequest struct {
+	Country          string             `json:"country" binding:"required"`
+	Region           string             `json:"region"`
+	Rates            map[string]float64 `json:"rates" binding:"required"`
+	PricesIncludeTax bool               `json:"prices_include_tax"`
+}
+
+type UpdateRateTableRequest struct {
+	Rates            map[string]float64 `json:"rates" binding:"required"`
+	PricesIncludeTax bool               `json:"prices_include_tax"`
+}
+
+func (h *TaxHandler) CreateRateTable(c *gin.Context) {
+	var req CreateRateTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	table, err := h.service.CreateRateTable(c.Request.Context(), req.Country, req.Region, req.Rates, req.PricesIncludeTax)
+	if err != nil {
+		status := taxErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewSimpleResponse(table))
+}
+
+func (h *TaxHandler) GetRateTable(c *gin.Context) {
+	table, err := h.service.GetRateTable(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		status := taxErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(table))
+}
+
+func (h *TaxHandler) UpdateRateTable(c *gin.Context) {
+	var req UpdateRateTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(http.StatusBadRequest, "Invalid request body"))
+		return
+	}
+
+	table, err := h.service.UpdateRateTable(c.Request.Context(), c.Param("id"), req.Rates, req.PricesIncludeTax)
+	if err != nil {
+		status := taxErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(table))
+}
+
+func (h *TaxHandler) DeleteRateTable(c *gin.Context) {
+	if err := h.service.DeleteRateTable(c.Request.Context(), c.Param("id")); err != nil {
+		status := taxErrorStatus(err)
+		c.JSON(status, response.NewErrorResponse(status, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse[any](nil))
+}
+
+func (h *TaxHandler) ListRateTables(c *gin.Context) {
+	tables, err := h.service.ListRateTables(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewSimpleResponse(tables))
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	appidempotency "github.com/stasshander/ddd/internal/application/idempotency"
+	"github.com/stasshander/ddd/internal/domain/idempotency"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotentRequestSize  = 1 << 20
+	maxIdempotentResponseSize = 1 << 20
+)
+
+// IdempotencyMiddleware makes POST requests carrying an Idempotency-Key
+// header safe to retry. The first response for a key is stored and replayed
+// for repeats of the same request, while reusing the key for a different
+// request is rejected. Server errors are not stored, so such requests can be
+// retried with the same key. Request bodies are read into memory to
+// fingerprint them, so the middleware is meant for individual routes and
+// rejects bodies over maxIdempotentRequestSize.
+func IdempotencyMiddleware(service *appidempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+
+		if err := idempotency.ValidateKey(key); err != nil {
+			abortIdempotency(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentRequestSize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			abortIdempotency(c, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		if err != nil {
+			abortIdempotency(c, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, replay, err := service.Begin(c.Request.Context(), key, fingerprint(c.Request, body))
+		switch {
+		case errors.Is(err, idempotency.ErrKeyMismatch):
+			abortIdempotency(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		case errors.Is(err, idempotency.ErrKeyInProgress):
+			abortIdempotency(c, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			abortIdempotency(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if replay != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(replay.Status, replay.ContentType, replay.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// The response is already sent, so the outcome is stored even if
+		// the client went away
+		ctx := context.WithoutCancel(c.Request.Context())
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || writer.overflow {
+			err = service.Release(ctx, record)
+		} else {
+			err = service.Complete(ctx, record, status, writer.Header().Get("Content-Type"), writer.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("Failed to save idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+// fingerprint identifies a request by its method, path and body
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.RequestURI()))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func abortIdempotency(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"code":    status,
+		"message": message,
+		"data":    nil,
+	})
+}
+
+// recordingWriter keeps a copy of the response body for replay. Bodies
+// larger than maxIdempotentResponseSize are not kept.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) record(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > maxIdempotentResponseSize {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
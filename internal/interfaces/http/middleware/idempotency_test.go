@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appidempotency "github.com/stasshander/ddd/internal/application/idempotency"
+	"github.com/stasshander/ddd/internal/domain/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryRecords keeps records in a map and applies the same conditions as
+// the MongoDB repository
+type memoryRecords struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newMemoryRecords() *memoryRecords {
+	return &memoryRecords{records: make(map[string]idempotency.Record)}
+}
+
+func (m *memoryRecords) Create(ctx context.Context, record *idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.records[record.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return idempotency.ErrKeyExists
+	}
+	m.records[record.Key] = *record
+	return nil
+}
+
+func (m *memoryRecords) GetByKey(ctx context.Context, key string) (*idempotency.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.records[key]
+	if !ok || !existing.ExpiresAt.After(time.Now()) {
+		return nil, idempotency.ErrRecordNotFound
+	}
+	return &existing, nil
+}
+
+func (m *memoryRecords) TakeOver(ctx context.Context, record *idempotency.Record, lockedUntil time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.records[record.Key]
+	if !ok || existing.Completed || !existing.LockedUntil.Equal(lockedUntil) {
+		return idempotency.ErrConcurrentUpdate
+	}
+	m.records[record.Key] = *record
+	return nil
+}
+
+func (m *memoryRecords) Complete(ctx context.Context, record *idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.owns(record) {
+		return idempotency.ErrConcurrentUpdate
+	}
+	m.records[record.Key] = *record
+	return nil
+}
+
+func (m *memoryRecords) Release(ctx context.Context, record *idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.owns(record) {
+		return idempotency.ErrConcurrentUpdate
+	}
+	delete(m.records, record.Key)
+	return nil
+}
+
+func (m *memoryRecords) owns(record *idempotency.Record) bool {
+	existing, ok := m.records[record.Key]
+	return ok && !existing.Completed && existing.LockedUntil.Equal(record.LockedUntil)
+}
+
+// countingHandler answers with status and the number of times it ran, after
+// reading the request body
+type countingHandler struct {
+	mu     sync.Mutex
+	calls  int
+	status int
+	// started and release, when set, hold the request until released
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *countingHandler) handle(c *gin.Context) {
+	_, _ = io.ReadAll(c.Request.Body)
+	if h.started != nil {
+		h.started <- struct{}{}
+		<-h.release
+	}
+
+	h.mu.Lock()
+	h.calls++
+	calls := h.calls
+	h.mu.Unlock()
+	c.JSON(h.status, gin.H{"call": calls})
+}
+
+func setupIdempotencyTest(status int) (*gin.Engine, *countingHandler) {
+	gin.SetMode(gin.TestMode)
+
+	handler := &countingHandler{status: status}
+	service := appidempotency.NewService(newMemoryRecords(), time.Hour, time.Minute)
+
+	router := gin.New()
+	router.POST("/api/products", IdempotencyMiddleware(service), handler.handle)
+	return router, handler
+}
+
+func idempotentPost(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/products", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	testCases := []struct {
+		name         string
+		status       int
+		keys         []string
+		bodies       []string
+		wantStatuses []int
+		wantCalls    int
+		wantReplayed []bool
+		wantLastCall int
+	}{
+		{
+			name:         "replays the first response",
+			status:       http.StatusCreated,
+			keys:         []string{"key-1", "key-1"},
+			bodies:       []string{`{"name":"Tea"}`, `{"name":"Tea"}`},
+			wantStatuses: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:    1,
+			wantReplayed: []bool{false, true},
+			wantLastCall: 1,
+		},
+		{
+			name:         "rejects a different body",
+			status:       http.StatusCreated,
+			keys:         []string{"key-1", "key-1"},
+			bodies:       []string{`{"name":"Tea"}`, `{"name":"Coffee"}`},
+			wantStatuses: []int{http.StatusCreated, http.StatusUnprocessableEntity},
+			wantCalls:    1,
+			wantReplayed: []bool{false, false},
+			wantLastCall: 0,
+		},
+		{
+			name:         "replays a client error",
+			status:       http.StatusBadRequest,
+			keys:         []string{"key-1", "key-1"},
+			bodies:       []string{`{}`, `{}`},
+			wantStatuses: []int{http.StatusBadRequest, http.StatusBadRequest},
+			wantCalls:    1,
+			wantReplayed: []bool{false, true},
+			wantLastCall: 1,
+		},
+		{
+			name:         "retries a server error",
+			status:       http.StatusInternalServerError,
+			keys:         []string{"key-1", "key-1"},
+			bodies:       []string{`{"name":"Tea"}`, `{"name":"Tea"}`},
+			wantStatuses: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			wantCalls:    2,
+			wantReplayed: []bool{false, false},
+			wantLastCall: 2,
+		},
+		{
+			name:         "ignores requests without a key",
+			status:       http.StatusCreated,
+			keys:         []string{"", ""},
+			bodies:       []string{`{"name":"Tea"}`, `{"name":"Tea"}`},
+			wantStatuses: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:    2,
+			wantReplayed: []bool{false, false},
+			wantLastCall: 2,
+		},
+		{
+			name:         "rejects an overlong key",
+			status:       http.StatusCreated,
+			keys:         []string{strings.Repeat("k", idempotency.MaxKeyLength+1)},
+			bodies:       []string{`{"name":"Tea"}`},
+			wantStatuses: []int{http.StatusBadRequest},
+			wantCalls:    0,
+			wantReplayed: []bool{false},
+			wantLastCall: 0,
+		},
+		{
+			name:         "rejects an oversized body",
+			status:       http.StatusCreated,
+			keys:         []string{"key-1"},
+			bodies:       []string{strings.Repeat("x", maxIdempotentRequestSize+1)},
+			wantStatuses: []int{http.StatusRequestEntityTooLarge},
+			wantCalls:    0,
+			wantReplayed: []bool{false},
+			wantLastCall: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, handler := setupIdempotencyTest(tc.status)
+
+			var w *httptest.ResponseRecorder
+			for i, key := range tc.keys {
+				w = idempotentPost(router, key, tc.bodies[i])
+				assert.Equal(t, tc.wantStatuses[i], w.Code, "request %d", i+1)
+				assert.Equal(t, tc.wantReplayed[i], w.Header().Get(IdempotentReplayedHeader) == "true", "request %d", i+1)
+			}
+			assert.Equal(t, tc.wantCalls, handler.calls)
+			if tc.wantLastCall > 0 {
+				assert.Contains(t, w.Body.String(), `"call":`+strconv.Itoa(tc.wantLastCall))
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareInProgress(t *testing.T) {
+	router, handler := setupIdempotencyTest(http.StatusCreated)
+	handler.started = make(chan struct{})
+	handler.release = make(chan struct{})
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() {
+		first <- idempotentPost(router, "key-1", `{"name":"Tea"}`)
+	}()
+	<-handler.started
+
+	w := idempotentPost(router, "key-1", `{"name":"Tea"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	close(handler.release)
+	assert.Equal(t, http.StatusCreated, (<-first).Code)
+
+	handler.started = nil
+	w = idempotentPost(router, "key-1", `{"name":"Tea"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, handler.calls)
+}
@@ -11,61 +11,23 @@ import (
 	"github.com/gin-gonic/gin"
 	appProduct "github.com/stasshander/ddd/internal/application/product"
 	"github.com/stasshander/ddd/internal/domain/product"
+	"github.com/stasshander/ddd/internal/domain/product/producttest"
 	"github.com/stretchr/testify/assert"
 )
 
-type MockProductRepository struct {
-	products map[string]*product.Product
-}
-
-func NewMockProductRepository() *MockProductRepository {
-	return &MockProductRepository{
-		products: make(map[string]*product.Product),
-	}
-}
-
-func (m *MockProductRepository) Create(ctx context.Context, p *product.Product) error {
-	m.products[p.ID.Hex()] = p
-	return nil
-}
-
-func (m *MockProductRepository) GetByID(ctx context.Context, id string) (*product.Product, error) {
-	if p, ok := m.products[id]; ok {
-		return p, nil
-	}
-	return nil, product.ErrProductNotFound
-}
-
-func (m *MockProductRepository) Update(ctx context.Context, p *product.Product) error {
-	if _, ok := m.products[p.ID.Hex()]; !ok {
-		return product.ErrProductNotFound
-	}
-	m.products[p.ID.Hex()] = p
-	return nil
-}
+// directRunner runs units of work without a transaction
+type directRunner struct{}
 
-func (m *MockProductRepository) Delete(ctx context.Context, id string) error {
-	if _, ok := m.products[id]; !ok {
-		return product.ErrProductNotFound
-	}
-	delete(m.products, id)
-	return nil
-}
-
-func (m *MockProductRepository) List(ctx context.Context) ([]*product.Product, error) {
-	products := make([]*product.Product, 0, len(m.products))
-	for _, p := range m.products {
-		products = append(products, p)
-	}
-	return products, nil
+func (directRunner) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
 
 func setupTest() (*gin.Engine, *ProductHandler) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
-	repo := NewMockProductRepository()
-	service := appProduct.NewService(repo)
+	repo := producttest.NewRepository()
+	service := appProduct.NewService(repo, directRunner{})
 	handler := NewProductHandler(service)
 	handler.RegisterRoutes(router)
 